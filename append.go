@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// append 요청 검사 (이어 붙여도 유효한 포맷, 임시 파일 모드의 압축에서만 지원)
+func validateAppend(event FileCompressionForm) error {
+	if !event.Append {
+		return nil
+	}
+	format := defaultIfEmpty(event.Format, DefaultFormat)
+	if !compressionFormats[format].SupportsAppend {
+		return fmt.Errorf("append is not supported for format: %s", format)
+	}
+	switch {
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("append is not supported for %s", event.Operation)
+	case event.StreamMode:
+		return fmt.Errorf("append is not supported in stream mode")
+	case event.SkipIfLarger:
+		return fmt.Errorf("append cannot be used with skipIfLarger")
+	case event.CopyOnly:
+		return fmt.Errorf("append cannot be used with copyOnly")
+	}
+	return nil
+}
+
+// 기존 대상 객체를 작업 디렉토리에 받아 새 압축 결과를 뒤에 이어 붙이고, 업로드할 파일 경로 반환
+// 대상 객체가 없으면 새 압축 결과를 그대로 업로드 (일반 생성)
+// 기존 대상 다운로드 실패는 DOWNLOAD_FAILED, 로컬 파일 작업 실패는 COMPRESS_FAILED로 반환
+// 다운로드~업로드 사이에 다른 요청이 같은 대상에 이어 붙이면 한쪽 내용이 사라지므로 같은 대상에 대한 요청은 순차 처리해야 함
+func appendToExistingTarget(ctx context.Context, job compressionJob, workDir, outputPath string) (string, error) {
+	existingPath := filepath.Join(workDir, "existing"+job.Format.Extension)
+	existing, err := downloadFromS3(ctx, job.TargetClient, job.TargetBucket, job.TargetKey, "", nil, existingPath, "")
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			loggerFrom(ctx).Info("Append target does not exist, creating it", "stage", "append", "key", job.TargetKey)
+			return outputPath, nil
+		}
+		return "", newCompressionError(ErrCodeDownload, fmt.Errorf("failed to download existing target: %w", err))
+	}
+
+	// 이후는 로컬 파일 작업이므로 다운로드 실패가 아닌 압축 결과 생성 실패로 분류
+	out, err := os.OpenFile(existingPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return "", newCompressionError(ErrCodeCompress, fmt.Errorf("failed to open existing target copy: %w", err))
+	}
+	defer out.Close()
+	in, err := os.Open(outputPath)
+	if err != nil {
+		return "", newCompressionError(ErrCodeCompress, fmt.Errorf("failed to open compressed output: %w", err))
+	}
+	defer in.Close()
+	appended, err := copyBuffered(out, in)
+	if err != nil {
+		return "", newCompressionError(ErrCodeCompress, fmt.Errorf("failed to append compressed output: %w", err))
+	}
+	if err := out.Close(); err != nil {
+		return "", newCompressionError(ErrCodeCompress, fmt.Errorf("failed to append compressed output: %w", err))
+	}
+	loggerFrom(ctx).Info("Appended to existing target", "stage", "append", "existingBytes", existing.Size, bytesAttr(appended))
+	return existingPath, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func appendTestEvent() FileCompressionForm {
+	event := testEvent()
+	event.Append = true
+	return event
+}
+
+// 기존 대상이 있으면 gzip 멤버를 이어 붙인 multistream으로 업로드 (풀면 기존 내용 + 새 내용)
+func TestAppendExistingTarget(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	old := []byte("old log line\n")
+	var existing bytes.Buffer
+	zw := gzip.NewWriter(&existing)
+	zw.Write(old)
+	zw.Close()
+	s3c.putObject("target-bucket", "logs/app.gz", existing.Bytes(), "application/gzip")
+	useFakeClients(t, s3c, sqsc)
+
+	if _, err := Handler(context.Background(), appendTestEvent()); err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	data := s3c.object("target-bucket", "logs/app.gz").data
+	if !bytes.HasPrefix(data, existing.Bytes()) {
+		t.Error("existing gzip member is not kept at the start of the target")
+	}
+	if got := gunzip(t, data); !bytes.Equal(got, append(append([]byte{}, old...), testContent...)) {
+		t.Errorf("decompressed %d bytes, want old + new content", len(got))
+	}
+
+	// 첫 번째 멤버만 읽으면 기존 내용
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	zr.Multistream(false)
+	first, err := io.ReadAll(zr)
+	if err != nil || !bytes.Equal(first, old) {
+		t.Errorf("first gzip member = %q, %v, want %q", first, err, old)
+	}
+}
+
+// 기존 대상이 없으면 일반 생성
+func TestAppendNewTarget(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+
+	if _, err := Handler(context.Background(), appendTestEvent()); err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	obj := s3c.object("target-bucket", "logs/app.gz")
+	if obj == nil {
+		t.Fatal("target not created")
+	}
+	if got := gunzip(t, obj.data); !bytes.Equal(got, testContent) {
+		t.Errorf("decompressed %d bytes, want %d", len(got), len(testContent))
+	}
+}
+
+// 기존 대상 다운로드 실패는 DOWNLOAD_FAILED, 로컬 파일 작업 실패는 COMPRESS_FAILED
+func TestAppendErrorCodes(t *testing.T) {
+	t.Run("download", func(t *testing.T) {
+		s3c := newFakeS3()
+		s3c.putObject("target-bucket", "logs/app.gz", []byte("existing"), "application/gzip")
+		s3c.errs["GetObject"] = errors.New("connection reset")
+		job := compressionJob{Format: compressionFormats["gzip"], TargetBucket: "target-bucket", TargetKey: "logs/app.gz", TargetClient: s3c}
+		_, err := appendToExistingTarget(context.Background(), job, t.TempDir(), writeTempFile(t, testContent).Name())
+		if errorCode(err) != ErrCodeDownload {
+			t.Errorf("appendToExistingTarget = %v, want %s", err, ErrCodeDownload)
+		}
+	})
+	t.Run("local", func(t *testing.T) {
+		s3c := newFakeS3()
+		s3c.putObject("target-bucket", "logs/app.gz", []byte("existing"), "application/gzip")
+		job := compressionJob{Format: compressionFormats["gzip"], TargetBucket: "target-bucket", TargetKey: "logs/app.gz", TargetClient: s3c}
+		missing := filepath.Join(t.TempDir(), "missing.gz")
+		_, err := appendToExistingTarget(context.Background(), job, t.TempDir(), missing)
+		if errorCode(err) != ErrCodeCompress {
+			t.Errorf("appendToExistingTarget = %v, want %s", err, ErrCodeCompress)
+		}
+	})
+}
+
+func TestValidateAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"gzip", FileCompressionForm{Append: true, Format: "gzip"}, false},
+		{"zstd", FileCompressionForm{Append: true, Format: "zstd"}, false},
+		{"7z", FileCompressionForm{Append: true, Format: "7z"}, true},
+		{"stream", FileCompressionForm{Append: true, Format: "gzip", StreamMode: true}, true},
+		{"decompress", FileCompressionForm{Append: true, Format: "gzip", Operation: OperationDecompress}, true},
+	}
+	for _, tt := range tests {
+		if err := validateAppend(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAppend = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// 원본 ContentType이 이미 압축된 형식이라 압축하지 않은 경우
+const SkipReasonIncompressible = "INCOMPRESSIBLE"
+
+// 이미 압축된 데이터라 다시 압축해도 크기가 거의 줄지 않는 ContentType
+var incompressibleContentTypes = map[string]bool{
+	"application/zip":              true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/vnd.rar":          true,
+	"application/x-bzip2":          true,
+	"application/x-xz":             true,
+	"application/zstd":             true,
+	"application/java-archive":     true,
+	"application/epub+zip":         true,
+}
+
+// gzip으로 충분히 잘 압축되는 텍스트 계열 ContentType (text/*, *+json, *+xml 외)
+var textContentTypes = map[string]bool{
+	"application/json":       true,
+	"application/x-ndjson":   true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-yaml":     true,
+	"application/yaml":       true,
+	"application/sql":        true,
+	"application/csv":        true,
+}
+
+// 원본 ContentType으로 압축 포맷 결정 (compress가 false면 압축하지 않음)
+//   - 이미 압축된 형식 (jpeg/png 등 이미지, 동영상, 압축 오디오, zip/gzip/7z 등 압축 파일, docx/xlsx 등 OOXML 문서): 압축 안 함
+//   - 텍스트 (text/*, JSON, XML, JavaScript, YAML, CSV 등): gzip
+//   - 그 외 (바이너리, 알 수 없거나 비어있는 ContentType): 기본 포맷(7z)
+func formatForContentType(contentType string) (format string, compress bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return DefaultFormat, true
+	}
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+	switch {
+	case incompressibleContentTypes[mediaType],
+		strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument."),
+		mainType == "video",
+		mainType == "image" && subType != "svg+xml" && subType != "bmp" && subType != "tiff",
+		mainType == "audio" && subType != "wav" && subType != "x-wav" && subType != "aiff":
+		return "", false
+	case mainType == "text", textContentTypes[mediaType],
+		strings.HasSuffix(subType, "+json"), strings.HasSuffix(subType, "+xml"):
+		return "gzip", true
+	}
+	return DefaultFormat, true
+}
+
+// autoFormat 옵션 검사 (단일 원본 키 압축 전용, format과 함께 지정할 수 없음)
+func validateAutoFormat(event FileCompressionForm) error {
+	if !event.AutoFormat {
+		return nil
+	}
+	switch {
+	case event.Format != "":
+		return fmt.Errorf("autoFormat cannot be used with format")
+	case event.OriginKey == "":
+		return fmt.Errorf("autoFormat requires a single originKey")
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("autoFormat is not supported for %s", event.Operation)
+	case event.CopyOnly:
+		return fmt.Errorf("autoFormat cannot be used with copyOnly")
+	}
+	return nil
+}
+
+// HeadObject로 원본 ContentType을 조회하여 Format을 정한 요청 반환 (압축하지 않을 형식이면 skip=true)
+// 선택된 포맷으로 요청을 다시 검사하여 포맷 전용 옵션(password, volumeSize 등)과 맞지 않으면 검증 실패로 처리
+func resolveAutoFormat(ctx context.Context, event FileCompressionForm) (FileCompressionForm, string, bool, error) {
+	client, err := newOriginClient(defaultIfEmpty(event.OriginRegion, getLambdaRegion()), event)
+	if err != nil {
+		return event, "", false, err
+	}
+	out, err := headObject(ctx, client, event.OriginBucket, event.OriginKey, event.OriginVersionId)
+	if err != nil {
+		return event, "", false, withErrorCode(ErrCodeDownload, describeSSECustomerError(err, event, event.OriginKey))
+	}
+	if out == nil {
+		return event, "", false, newCompressionError(ErrCodeOriginNotFound, fmt.Errorf("origin object not found: %s/%s", event.OriginBucket, event.OriginKey))
+	}
+	contentType := aws.ToString(out.ContentType)
+	format, compress := formatForContentType(contentType)
+	loggerFrom(ctx).Info("Selected format from content type", "stage", "validate", "contentType", contentType, "format", format, "compress", compress)
+	if !compress {
+		return event, contentType, true, nil
+	}
+
+	event.AutoFormat = false
+	event.Format = format
+	if err := validateRequest(event); err != nil {
+		return event, contentType, false, newCompressionError(ErrCodeValidation, fmt.Errorf("autoFormat selected %s for %s: %w", format, contentType, err))
+	}
+	return event, contentType, false, nil
+}
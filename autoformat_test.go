@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFormatForContentType(t *testing.T) {
+	tests := []struct {
+		contentType  string
+		wantFormat   string
+		wantCompress bool
+	}{
+		// 텍스트
+		{"text/plain", "gzip", true},
+		{"text/csv", "gzip", true},
+		{"text/plain; charset=utf-8", "gzip", true},
+		{"Text/HTML; Charset=UTF-8", "gzip", true},
+		{"application/json", "gzip", true},
+		{"application/json; charset=utf-8", "gzip", true},
+		{"application/x-ndjson", "gzip", true},
+		{"application/vnd.api+json", "gzip", true},
+		{"application/atom+xml", "gzip", true},
+		{"image/svg+xml", "gzip", true},
+		// 이미 압축된 형식
+		{"image/jpeg", "", false},
+		{"IMAGE/JPEG", "", false},
+		{"image/png", "", false},
+		{"video/mp4", "", false},
+		{"audio/mpeg", "", false},
+		{"application/zip", "", false},
+		{"application/gzip", "", false},
+		{"application/x-7z-compressed", "", false},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "", false},
+		// 압축되지 않은 미디어는 기본 포맷
+		{"image/bmp", DefaultFormat, true},
+		{"audio/wav", DefaultFormat, true},
+		// 그 외, 비어있거나 잘못된 ContentType은 기본 포맷
+		{"application/octet-stream", DefaultFormat, true},
+		{"", DefaultFormat, true},
+		{"not a content type", DefaultFormat, true},
+	}
+	for _, tt := range tests {
+		format, compress := formatForContentType(tt.contentType)
+		if format != tt.wantFormat || compress != tt.wantCompress {
+			t.Errorf("formatForContentType(%q) = %q, %v, want %q, %v", tt.contentType, format, compress, tt.wantFormat, tt.wantCompress)
+		}
+	}
+}
+
+func TestValidateAutoFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"single key", FileCompressionForm{AutoFormat: true, OriginKey: "logs/app.log"}, false},
+		{"with format", FileCompressionForm{AutoFormat: true, OriginKey: "logs/app.log", Format: "gzip"}, true},
+		{"multiple keys", FileCompressionForm{AutoFormat: true, OriginKeys: []string{"a", "b"}}, true},
+		{"decompress", FileCompressionForm{AutoFormat: true, OriginKey: "logs/app.log", Operation: OperationDecompress}, true},
+		{"copyOnly", FileCompressionForm{AutoFormat: true, OriginKey: "logs/app.log", CopyOnly: true}, true},
+	}
+	for _, tt := range tests {
+		if err := validateAutoFormat(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAutoFormat = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// 이미 압축된 형식은 SKIPPED(INCOMPRESSIBLE)로 끝나고, 텍스트는 gzip으로 압축
+func TestHandlerAutoFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		wantResult  string
+		wantKey     string // 압축된 경우 대상 키
+	}{
+		{"image/jpeg", ResultSkipped, ""},
+		{"video/mp4", ResultSkipped, ""},
+		{"application/zip", ResultSkipped, ""},
+		{"text/plain; charset=utf-8", ResultSucceed, "logs/app.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", testContent, tt.contentType)
+			useFakeClients(t, s3c, sqsc)
+			event := testEvent()
+			event.Format = ""
+			event.AutoFormat = true
+
+			result, err := Handler(context.Background(), event)
+			if err != nil {
+				t.Fatalf("Handler: %v", err)
+			}
+			if result.Result != tt.wantResult {
+				t.Fatalf("result = %s, want %s", result.Result, tt.wantResult)
+			}
+			if tt.wantKey == "" {
+				if result.Reason != SkipReasonIncompressible || len(s3c.puts) != 0 || len(s3c.gets) != 0 {
+					t.Errorf("skipped result = %+v, puts %d, gets %d", result, len(s3c.puts), len(s3c.gets))
+				}
+				return
+			}
+			obj := s3c.object("target-bucket", tt.wantKey)
+			if result.Key != tt.wantKey || obj == nil || string(gunzip(t, obj.data)) != string(testContent) {
+				t.Errorf("result key = %s, want gzip output at %s", result.Key, tt.wantKey)
+			}
+		})
+	}
+}
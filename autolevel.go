@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	DefaultAutoLevelSmallBytes = 64 * 1024 * 1024   // 이 크기 이하의 입력은 최고 압축 레벨
+	DefaultAutoLevelLargeBytes = 1024 * 1024 * 1024 // 이 크기 이상의 입력은 가장 빠른 레벨
+	autoLevelHigh              = MaxCompressionLevel
+	autoLevelMedium            = 5
+	autoLevelLow               = 1
+)
+
+// autoLevel 크기 기준 (init에서 AUTO_LEVEL_SMALL_BYTES, AUTO_LEVEL_LARGE_BYTES로 설정)
+var (
+	autoLevelSmallBytes int64 = DefaultAutoLevelSmallBytes
+	autoLevelLargeBytes int64 = DefaultAutoLevelLargeBytes
+)
+
+// 입력 크기에 맞는 압축 레벨 (7za -mx 기준 1~9, gzip/zstd도 같은 값 사용)
+//   - size <= smallBytes: 최고 레벨 (작은 파일은 오래 걸리지 않으므로 압축률 우선)
+//   - size >= largeBytes: 가장 빠른 레벨 (큰 파일은 실행 시간 예산 안에 끝나는 것이 우선)
+//   - 그 사이: 중간 레벨
+func levelForSize(size, smallBytes, largeBytes int64) int {
+	switch {
+	case size <= smallBytes:
+		return autoLevelHigh
+	case size >= largeBytes:
+		return autoLevelLow
+	}
+	return autoLevelMedium
+}
+
+// autoLevel 옵션 검사 (compressionLevel과 함께 지정할 수 없고, 실제로 압축하는 요청에만 적용)
+func validateAutoLevel(event FileCompressionForm) error {
+	if !event.AutoLevel {
+		return nil
+	}
+	switch {
+	case event.CompressionLevel != 0:
+		return fmt.Errorf("autoLevel cannot be used with compressionLevel")
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("autoLevel is not supported for %s", event.Operation)
+	case event.CopyOnly:
+		return fmt.Errorf("autoLevel cannot be used with copyOnly")
+	}
+	return nil
+}
+
+// 입력 크기로 정한 압축 레벨을 opts에 설정
+func applyAutoLevel(ctx context.Context, opts *sevenZipOptions, size int64) {
+	opts.Level = levelForSize(size, autoLevelSmallBytes, autoLevelLargeBytes)
+	loggerFrom(ctx).Info("Selected compression level from input size", "stage", "compress", bytesAttr(size), "level", opts.Level)
+}
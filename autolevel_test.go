@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// 경계값은 작은 쪽/큰 쪽 레벨에 포함
+func TestLevelForSize(t *testing.T) {
+	tests := []struct {
+		name               string
+		size, small, large int64
+		want               int
+	}{
+		{"empty", 0, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelHigh},
+		{"at small threshold", DefaultAutoLevelSmallBytes, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelHigh},
+		{"just above small threshold", DefaultAutoLevelSmallBytes + 1, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelMedium},
+		{"just below large threshold", DefaultAutoLevelLargeBytes - 1, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelMedium},
+		{"at large threshold", DefaultAutoLevelLargeBytes, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelLow},
+		{"above large threshold", 10 * DefaultAutoLevelLargeBytes, DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes, autoLevelLow},
+		// 두 기준이 같으면 중간 레벨 없이 나뉨 (같은 크기는 작은 쪽 우선)
+		{"equal thresholds at", 100, 100, 100, autoLevelHigh},
+		{"equal thresholds above", 101, 100, 100, autoLevelLow},
+	}
+	for _, tt := range tests {
+		if got := levelForSize(tt.size, tt.small, tt.large); got != tt.want {
+			t.Errorf("%s: levelForSize(%d, %d, %d) = %d, want %d", tt.name, tt.size, tt.small, tt.large, got, tt.want)
+		}
+	}
+}
+
+func TestApplyAutoLevel(t *testing.T) {
+	saved := [2]int64{autoLevelSmallBytes, autoLevelLargeBytes}
+	autoLevelSmallBytes, autoLevelLargeBytes = 10, 20
+	t.Cleanup(func() { autoLevelSmallBytes, autoLevelLargeBytes = saved[0], saved[1] })
+
+	for size, want := range map[int64]int{10: autoLevelHigh, 15: autoLevelMedium, 20: autoLevelLow} {
+		opts := sevenZipOptions{Level: 3}
+		applyAutoLevel(context.Background(), &opts, size)
+		if opts.Level != want {
+			t.Errorf("applyAutoLevel(%d) level = %d, want %d", size, opts.Level, want)
+		}
+	}
+}
+
+func TestValidateAutoLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"disabled", FileCompressionForm{CompressionLevel: 3, CopyOnly: true}, false},
+		{"compress", FileCompressionForm{AutoLevel: true}, false},
+		{"explicit compress", FileCompressionForm{AutoLevel: true, Operation: OperationCompress}, false},
+		{"with compressionLevel", FileCompressionForm{AutoLevel: true, CompressionLevel: 3}, true},
+		{"decompress", FileCompressionForm{AutoLevel: true, Operation: OperationDecompress}, true},
+		{"copyOnly", FileCompressionForm{AutoLevel: true, CopyOnly: true}, true},
+	}
+	for _, tt := range tests {
+		if err := validateAutoLevel(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAutoLevel = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
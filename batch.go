@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SendMessageBatch 한 번에 보낼 수 있는 최대 메시지 수
+const MaxSendBatchEntries = 10
+
+// 여러 레코드를 처리하는 동안 결과 메시지를 모아 두었다가 SendMessageBatch로 한꺼번에 전송
+// (레코드마다 SendMessage를 호출하는 것보다 API 호출 수와 스로틀링이 줄어듦)
+type resultBatch struct {
+	mu      sync.Mutex
+	record  string // 현재 처리 중인 레코드 ID (전송 실패 시 어느 레코드의 결과인지 구분)
+	entries []batchEntry
+	hooks   []flushHook
+}
+
+// 배치 전송이 끝난 뒤 호출할 함수 (sent는 해당 레코드의 결과가 모두 전송되었는지 여부)
+type flushHook struct {
+	record string
+	fn     func(sent bool)
+}
+
+type batchEntry struct {
+	event    FileCompressionForm
+	queueUrl string
+	result   CompressionResultData
+	record   string
+	onSent   func()
+}
+
+type resultBatchKey struct{}
+
+func withResultBatch(ctx context.Context, batch *resultBatch) context.Context {
+	return context.WithValue(ctx, resultBatchKey{}, batch)
+}
+
+// context에 저장된 결과 배치 (없으면 nil, 바로 전송)
+func resultBatchFrom(ctx context.Context) *resultBatch {
+	batch, _ := ctx.Value(resultBatchKey{}).(*resultBatch)
+	return batch
+}
+
+func (b *resultBatch) setRecord(record string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record = record
+}
+
+func (b *resultBatch) add(event FileCompressionForm, queueUrl string, result CompressionResultData, onSent func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, batchEntry{event: event, queueUrl: queueUrl, result: result, record: b.record, onSent: onSent})
+}
+
+// 현재 레코드의 결과 전송이 끝난 뒤(flush) 호출할 함수 등록
+// 결과를 큐로 보내지 않는 레코드도 전송에 실패한 것이 없으므로 sent=true로 호출됨
+func (b *resultBatch) afterFlush(fn func(sent bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hooks = append(b.hooks, flushHook{record: b.record, fn: fn})
+}
+
+// 같은 큐(리전/자격 증명 포함)끼리 묶어 최대 10개씩 전송하고, 전송하지 못한 결과의 레코드 ID 목록 반환
+// 일부 메시지만 실패하면 실패한 메시지만 다시 전송 (전송자 오류로 실패한 메시지는 재시도하지 않음)
+func (b *resultBatch) flush(ctx context.Context) []string {
+	b.mu.Lock()
+	entries, hooks := b.entries, b.hooks
+	b.entries, b.hooks = nil, nil
+	b.mu.Unlock()
+
+	var groups [][]batchEntry
+	index := map[string]int{}
+	for _, entry := range entries {
+		key := entry.event.QueueRegion + "\x00" + credentialsFromForm(entry.event).cacheKey() + "\x00" + entry.queueUrl
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], entry)
+	}
+
+	var failedRecords []string
+	for _, group := range groups {
+		for start := 0; start < len(group); start += MaxSendBatchEntries {
+			chunk := group[start:min(start+MaxSendBatchEntries, len(group))]
+			for _, entry := range sendResultBatch(ctx, chunk) {
+				failedRecords = append(failedRecords, entry.record)
+			}
+		}
+	}
+	for _, hook := range hooks {
+		hook.fn(!slices.Contains(failedRecords, hook.record))
+	}
+	return failedRecords
+}
+
+// 같은 큐로 보낼 결과(최대 10개)를 SendMessageBatch로 전송하고 전송하지 못한 항목 반환
+func sendResultBatch(ctx context.Context, chunk []batchEntry) []batchEntry {
+	event, queueUrl := chunk[0].event, chunk[0].queueUrl
+	if !queueBreaker.allow(queueUrl, time.Now()) {
+		loggerFrom(ctx).Warn("Queue circuit breaker is open, skipping SQS batch send", "stage", "queue", "queueUrl", queueUrl, "messages", len(chunk))
+		return chunk
+	}
+
+	pending := map[string]batchEntry{}
+	var requests []types.SendMessageBatchRequestEntry
+	var failed []batchEntry
+	for i, entry := range chunk {
+		input, err := resultMessageInput(queueUrl, entry.event.MessageGroupId, entry.event.MessageFormat, entry.result)
+		if err != nil {
+			loggerFrom(ctx).Error("Failed to build SQS message", "stage", "queue", "processUuid", entry.result.ProcessUuid, errorAttr(err))
+			failed = append(failed, entry)
+			continue
+		}
+		id := strconv.Itoa(i)
+		pending[id] = entry
+		requests = append(requests, types.SendMessageBatchRequestEntry{
+			Id:                     &id,
+			MessageBody:            input.MessageBody,
+			MessageGroupId:         input.MessageGroupId,
+			MessageDeduplicationId: input.MessageDeduplicationId,
+		})
+	}
+	if len(requests) == 0 {
+		return failed
+	}
+
+	client, err := sqsClientFor(event.QueueRegion, credentialsFromForm(event))
+	if err == nil {
+		err = withRetry(ctx, "queue", defaultMaxRetries, func() error {
+			// 대량 처리 시 SQS 스로틀링을 피하도록 QUEUE_SEND_RATE(초당 메시지 수)에 맞춰 대기
+			for range requests {
+				if err := queueLimiter.wait(ctx); err != nil {
+					return fmt.Errorf("waiting for queue send rate limit: %w", err)
+				}
+			}
+			out, err := client.SendMessageBatch(context.Background(), &sqs.SendMessageBatchInput{
+				QueueUrl: &queueUrl,
+				Entries:  requests,
+			})
+			if err != nil {
+				return err
+			}
+			for _, ok := range out.Successful {
+				if entry, found := pending[*ok.Id]; found {
+					delete(pending, *ok.Id)
+					if entry.onSent != nil {
+						entry.onSent()
+					}
+				}
+			}
+
+			var retry []types.SendMessageBatchRequestEntry
+			for _, entryErr := range out.Failed {
+				if entryErr.SenderFault {
+					loggerFrom(ctx).Error("SQS batch entry rejected", "stage", "queue", "queueUrl", queueUrl, "processUuid", pending[*entryErr.Id].result.ProcessUuid, "code", aws.ToString(entryErr.Code), "message", aws.ToString(entryErr.Message))
+					failed = append(failed, pending[*entryErr.Id])
+					delete(pending, *entryErr.Id)
+					continue
+				}
+				for _, request := range requests {
+					if *request.Id == *entryErr.Id {
+						retry = append(retry, request)
+					}
+				}
+			}
+			requests = retry
+			if len(requests) > 0 {
+				return &retryableError{err: fmt.Errorf("%d of %d messages failed to send to %s", len(requests), len(out.Successful)+len(out.Failed), queueUrl)}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		loggerFrom(ctx).Error("Failed to send SQS message batch", "stage", "queue", "queueUrl", queueUrl, "messages", len(pending), errorAttr(err))
+		for _, entry := range pending {
+			failed = append(failed, entry)
+		}
+	}
+	if queueBreaker.record(queueUrl, err, time.Now()) {
+		loggerFrom(ctx).Warn("Queue circuit breaker opened after consecutive failures", "stage", "queue", "queueUrl", queueUrl, "cooldownMs", queueBreaker.cooldown.Milliseconds())
+	}
+	return failed
+}
@@ -0,0 +1,44 @@
+//go:build chaos
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+)
+
+// 장애 주입 빌드 (go build -tags chaos): 스테이징에서 재시도/실패 큐/DLQ 연결을 검증하기 위해 CHAOS_FAIL_STAGE 단계에서 항상 실패
+// 운영 빌드에는 chaos_off.go의 no-op이 포함되므로 환경 변수를 설정해도 동작하지 않음
+// 임시 파일 모드, 스트리밍 모드, copyOnly, 압축 해제 모두에 주입 (copyOnly는 압축 단계가 없으므로 compress는 해당 없음)
+var chaosStages = []string{"download", "compress", "upload", "sqs"}
+
+// 장애를 주입할 단계 (init에서 initChaos로 설정, 비어있으면 비활성화)
+var chaosFailStage string
+
+// 로거 초기화 뒤에 CHAOS_FAIL_STAGE를 읽도록 main.go init에서 호출
+func initChaos() {
+	chaosFailStage = parseChaosFailStage(os.Getenv("CHAOS_FAIL_STAGE"))
+}
+
+// CHAOS_FAIL_STAGE 값 검사 (지원하지 않는 단계면 경고 후 장애 주입 비활성화)
+func parseChaosFailStage(value string) string {
+	if value == "" {
+		return ""
+	}
+	if !slices.Contains(chaosStages, value) {
+		slog.Warn("Unknown CHAOS_FAIL_STAGE, failure injection disabled", "stage", "init", "value", value, "supported", chaosStages)
+		return ""
+	}
+	slog.Warn("Failure injection enabled", "stage", "init", "failStage", value)
+	return value
+}
+
+// stage가 CHAOS_FAIL_STAGE면 재시도하지 않는 합성 에러 반환
+func injectFault(stage string) error {
+	if stage != chaosFailStage {
+		return nil
+	}
+	return fmt.Errorf("chaos: injected failure at %s stage", stage)
+}
@@ -0,0 +1,8 @@
+//go:build !chaos
+
+package main
+
+// 운영 빌드: 장애 주입 없음 (CHAOS_FAIL_STAGE는 chaos 빌드 태그로 빌드한 경우에만 동작, chaos.go 참고)
+func injectFault(string) error { return nil }
+
+func initChaos() {}
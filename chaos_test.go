@@ -0,0 +1,159 @@
+//go:build chaos
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// 실행: go test -tags chaos ./...
+func TestParseChaosFailStage(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"download", "download"},
+		{"compress", "compress"},
+		{"upload", "upload"},
+		{"sqs", "sqs"},
+		{"delete", ""},
+		{"Upload", ""},
+	}
+	for _, tt := range tests {
+		if got := parseChaosFailStage(tt.value); got != tt.want {
+			t.Errorf("parseChaosFailStage(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func useChaosFailStage(t *testing.T, stage string) {
+	t.Helper()
+	saved := chaosFailStage
+	chaosFailStage = stage
+	t.Cleanup(func() { chaosFailStage = saved })
+}
+
+func TestInjectFault(t *testing.T) {
+	useChaosFailStage(t, "upload")
+	if err := injectFault("upload"); err == nil {
+		t.Error("injectFault(upload) = nil, want injected error")
+	}
+	if err := injectFault("download"); err != nil {
+		t.Errorf("injectFault(download) = %v, want nil", err)
+	}
+	useChaosFailStage(t, "")
+	if err := injectFault("upload"); err != nil {
+		t.Errorf("injectFault with no stage = %v, want nil", err)
+	}
+}
+
+// 단계별로 주입된 장애가 해당 단계의 실패 코드로 보고되는지 확인
+func TestChaosFailStage(t *testing.T) {
+	tests := []struct {
+		stage string
+		code  string
+	}{
+		{"download", ErrCodeDownload},
+		{"compress", ErrCodeCompress},
+		{"upload", ErrCodeUpload},
+		{"sqs", ErrCodeQueue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stage, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+			useFakeClients(t, s3c, sqsc)
+			useChaosFailStage(t, tt.stage)
+
+			result, err := Handler(context.Background(), testEvent())
+			if err == nil {
+				t.Fatal("Handler succeeded, want injected failure")
+			}
+			if got := errorCode(err); got != tt.code {
+				t.Errorf("errorCode = %s, want %s (%v)", got, tt.code, err)
+			}
+			if result.Result != ResultFailed || result.ErrorCode != tt.code {
+				t.Errorf("result = %+v", result)
+			}
+			// 업로드 이전 단계에서 실패하면 대상 객체가 없어야 함
+			if tt.stage != "sqs" && s3c.object("target-bucket", "logs/app.gz") != nil {
+				t.Error("target object uploaded despite injected failure")
+			}
+			// 실패 결과 메시지는 sqs 단계가 아니면 전송됨
+			if results := sqsc.results(t); tt.stage == "sqs" && len(results) != 0 || tt.stage != "sqs" && (len(results) != 1 || results[0].ErrorCode != tt.code) {
+				t.Errorf("sent results = %+v", results)
+			}
+		})
+	}
+}
+
+// 스트리밍 모드, copyOnly, 압축 해제에서도 주입된 장애가 해당 단계의 실패 코드로 보고되는지 확인
+func TestChaosFailStageModes(t *testing.T) {
+	streamEvent := func() FileCompressionForm {
+		e := testEvent()
+		e.StreamMode = true
+		return e
+	}
+	copyEvent := func() FileCompressionForm {
+		e := testEvent()
+		e.CopyOnly = true
+		e.TargetKey = "copies/app.log"
+		return e
+	}
+	decompressEvent := func() FileCompressionForm {
+		e := testEvent()
+		e.Operation = OperationDecompress
+		e.OriginKey = "logs/app.7z"
+		e.Format = "7z"
+		return e
+	}
+	tests := []struct {
+		name  string
+		event func() FileCompressionForm
+		stage string
+		code  string
+	}{
+		{"stream download", streamEvent, "download", ErrCodeDownload},
+		{"stream compress", streamEvent, "compress", ErrCodeCompress},
+		{"stream upload", streamEvent, "upload", ErrCodeUpload},
+		{"copyOnly download", copyEvent, "download", ErrCodeDownload},
+		{"copyOnly upload", copyEvent, "upload", ErrCodeUpload},
+		{"decompress download", decompressEvent, "download", ErrCodeDownload},
+		{"decompress compress", decompressEvent, "compress", ErrCodeCompress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+			s3c.putObject("origin-bucket", "logs/app.7z", testContent, "application/x-7z-compressed")
+			useFakeClients(t, s3c, sqsc)
+			useChaosFailStage(t, tt.stage)
+
+			_, err := Handler(context.Background(), tt.event())
+			if got := errorCode(err); got != tt.code || !strings.Contains(err.Error(), "chaos: injected failure") {
+				t.Errorf("errorCode = %s, want %s (%v)", got, tt.code, err)
+			}
+			if len(s3c.puts) != 0 || len(s3c.creates) != 0 || len(s3c.copies) != 0 {
+				t.Error("target written despite injected failure")
+			}
+		})
+	}
+}
+
+// CHAOS_FAIL_STAGE는 init에서 로거 초기화 뒤에 읽음
+func TestInitChaos(t *testing.T) {
+	useChaosFailStage(t, "")
+	t.Setenv("CHAOS_FAIL_STAGE", "compress")
+	initChaos()
+	if chaosFailStage != "compress" {
+		t.Errorf("chaosFailStage = %q, want compress", chaosFailStage)
+	}
+	t.Setenv("CHAOS_FAIL_STAGE", "unknown")
+	initChaos()
+	if chaosFailStage != "" {
+		t.Errorf("chaosFailStage = %q, want disabled", chaosFailStage)
+	}
+}
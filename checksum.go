@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+)
+
+// 멀티파트 업로드 파트 크기 (manager.Uploader와 같은 규칙: 기본 5MiB, 파트 수가 최대치를 넘으면 늘림)
+// 체크섬 계산과 업로드가 같은 파트 경계를 사용해야 S3에 저장되는 값과 비교할 수 있음
+func multipartPartSize(size int64) int64 {
+	partSize := int64(manager.DefaultUploadPartSize)
+	if size/partSize >= int64(manager.MaxUploadParts) {
+		partSize = size/int64(manager.MaxUploadParts) + 1
+	}
+	return partSize
+}
+
+// S3 멀티파트 객체의 복합(composite) SHA256 체크섬 계산: base64(SHA256(파트1 SHA256 || 파트2 SHA256 || ...)) + "-파트 수"
+// 파트별 해시는 서로 독립적이므로 CPU 수만큼 병렬로 계산 (ReadAt을 사용하므로 파일 위치는 바뀌지 않음)
+// 단일 스레드로 파일 전체를 해시하는 것보다 대략 코어 수에 비례하여 빨라짐 (디스크 읽기 속도가 상한, 1코어에서는 차이 없음)
+// 비교: go test -run x -bench SHA256
+func multipartSHA256(f *os.File, size, partSize int64) (string, error) {
+	parts := int((size + partSize - 1) / partSize)
+	if parts == 0 {
+		parts = 1
+	}
+	sums := make([][]byte, parts)
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range min(runtime.NumCPU(), parts) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				offset := int64(i) * partSize
+				h := sha256.New()
+				if _, err := io.Copy(h, io.NewSectionReader(f, offset, min(partSize, size-offset))); err != nil {
+					errOnce.Do(func() { firstErr = fmt.Errorf("failed to compute checksum of part %d: %w", i+1, err) })
+					continue
+				}
+				sums[i] = h.Sum(nil)
+			}
+		}()
+	}
+	for i := range parts {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	h := sha256.New()
+	for _, sum := range sums {
+		h.Write(sum)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(parts), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+)
+
+// S3 복합 체크섬 형식: base64(SHA256 32바이트) + "-파트 수"
+var compositeChecksumPattern = regexp.MustCompile(`^[A-Za-z0-9+/]{43}=-([0-9]+)$`)
+
+// 파트별 SHA256을 이어 붙여 다시 해시한 값 (S3 문서의 계산 방식 그대로)
+func expectedCompositeSHA256(data []byte, partSize int) string {
+	h := sha256.New()
+	parts := 0
+	for offset := 0; offset < len(data) || parts == 0; offset += partSize {
+		sum := sha256.Sum256(data[offset:min(offset+partSize, len(data))])
+		h.Write(sum[:])
+		parts++
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" + strconv.Itoa(parts)
+}
+
+func TestMultipartSHA256(t *testing.T) {
+	const partSize = 1024
+	tests := []struct {
+		name      string
+		size      int
+		wantParts int
+	}{
+		{"empty", 0, 1},
+		{"single part", 100, 1},
+		{"exact parts", 3 * partSize, 3},
+		{"partial last part", 3*partSize + 1, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for i := range data {
+				data[i] = byte(i * 7)
+			}
+			f := writeTempFile(t, data)
+			got, err := multipartSHA256(f, int64(tt.size), partSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			match := compositeChecksumPattern.FindStringSubmatch(got)
+			if match == nil {
+				t.Fatalf("checksum %q is not in <base64>-<parts> format", got)
+			}
+			if match[1] != strconv.Itoa(tt.wantParts) {
+				t.Errorf("parts = %s, want %d", match[1], tt.wantParts)
+			}
+			if want := expectedCompositeSHA256(data, partSize); got != want {
+				t.Errorf("multipartSHA256 = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// 파트 수가 S3 최대 파트 수를 넘지 않도록 파트 크기를 키움
+func TestMultipartPartSize(t *testing.T) {
+	if got := multipartPartSize(100 * 1024 * 1024); got != manager.DefaultUploadPartSize {
+		t.Errorf("partSize for 100MiB = %d, want default %d", got, manager.DefaultUploadPartSize)
+	}
+	size := int64(manager.DefaultUploadPartSize) * int64(manager.MaxUploadParts) * 3
+	partSize := multipartPartSize(size)
+	if parts := (size + partSize - 1) / partSize; parts > int64(manager.MaxUploadParts) {
+		t.Errorf("%d parts exceed the maximum of %d", parts, manager.MaxUploadParts)
+	}
+}
+
+// 멀티파트 업로드에서 미리 계산한 복합 체크섬이 S3에 저장된 값과 일치하여 검증을 통과
+func TestUploadVerifyChecksumMultipart(t *testing.T) {
+	setMultipartThreshold(t, 1024*1024)
+	s3c := newFakeS3()
+	data := bytes.Repeat([]byte("checksum"), 2*1024*1024) // 16MiB, 기본 파트 크기 5MiB → 4파트
+	if _, err := uploadToS3(context.Background(), s3c, "b", "k", writeTempFile(t, data), uploadOptions{VerifyChecksum: true}); err != nil {
+		t.Fatalf("uploadToS3 with verifyChecksum: %v", err)
+	}
+	stored := s3c.object("b", "k").checksum
+	if match := compositeChecksumPattern.FindStringSubmatch(stored); match == nil || match[1] != "4" {
+		t.Errorf("stored checksum %q, want 4-part composite", stored)
+	}
+}
+
+// 병렬 파트 해시(multipartSHA256)와 단일 스레드 전체 해시(fileSHA256) 비교용 256MiB 파일
+func benchmarkHashFile(b *testing.B) (*os.File, int64) {
+	const size = 256 * 1024 * 1024
+	path := filepath.Join(b.TempDir(), "bench")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xa5}, size), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { f.Close() })
+	b.SetBytes(size)
+	return f, size
+}
+
+func BenchmarkMultipartSHA256Parallel(b *testing.B) {
+	f, size := benchmarkHashFile(b)
+	for b.Loop() {
+		if _, err := multipartSHA256(f, size, manager.DefaultUploadPartSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFileSHA256Single(b *testing.B) {
+	f, _ := benchmarkHashFile(b)
+	for b.Loop() {
+		if _, err := fileSHA256(f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
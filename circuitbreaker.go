@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// 결과 큐 전송 회로 차단기 기본값
+const (
+	DefaultQueueBreakerThreshold = 5  // 연속 실패 횟수가 이 값에 도달하면 차단 (0이면 비활성화)
+	DefaultQueueBreakerCooldown  = 60 // 차단 유지 시간 (초)
+)
+
+var errCircuitOpen = errors.New("circuit breaker is open")
+
+// 키(큐 URL)별 연속 실패 횟수를 세어, 임계값에 도달하면 cooldown 동안 호출을 건너뛰게 하는 회로 차단기
+// 웜 컨테이너에서 재사용되는 동안만 유지되며, cooldown이 지나면 시험 호출 1건만 허용(half-open)하여
+// 성공하면 초기화하고 실패하면 다시 차단
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	states    map[string]*circuitState
+}
+
+type circuitState struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		states:    map[string]*circuitState{},
+	}
+}
+
+// 호출 가능 여부 (차단 중이면 false)
+// cooldown이 지나면 시험 호출 1건만 허용하고, 그 결과가 기록될 때까지 나머지 호출은 계속 차단
+// 시험 호출이 결과를 기록하지 못하고 끝나도(context 취소 등) cooldown이 다시 지나면 다음 시험 호출 허용
+func (b *circuitBreaker) allow(key string, now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[key]
+	if !ok || state.failures < b.threshold {
+		return true
+	}
+	if now.Before(state.openUntil) {
+		return false
+	}
+	// half-open: 시험 호출 결과를 기다리는 동안 다른 호출이 들어오지 않도록 차단 시간을 다시 설정
+	state.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// 호출 결과 기록, 이번 실패로 차단이 시작되었으면 true 반환
+func (b *circuitBreaker) record(key string, err error, now time.Time) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.states, key)
+		return false
+	}
+	state, ok := b.states[key]
+	if !ok {
+		state = &circuitState{}
+		b.states[key] = state
+	}
+	state.failures++
+	if state.failures < b.threshold {
+		return false
+	}
+	state.openUntil = now.Add(b.cooldown)
+	return true
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errSend = errors.New("send failed")
+
+// 연속 실패가 임계값에 도달해야 차단되고, 그 전에는 계속 호출 가능
+func TestCircuitBreakerThreshold(t *testing.T) {
+	b := newCircuitBreaker(3, time.Minute)
+	now := time.Now()
+	for i := 1; i <= 2; i++ {
+		if opened := b.record("q", errSend, now); opened {
+			t.Fatalf("opened after %d failures, want 3", i)
+		}
+		if !b.allow("q", now) {
+			t.Fatalf("blocked after %d failures, want 3", i)
+		}
+	}
+	if opened := b.record("q", errSend, now); !opened {
+		t.Fatal("not opened after reaching the threshold")
+	}
+	if b.allow("q", now.Add(59*time.Second)) {
+		t.Error("allowed during cooldown")
+	}
+	if !b.allow("other", now) {
+		t.Error("failures of one key blocked another key")
+	}
+}
+
+// 임계값에 도달하기 전 성공하면 연속 실패 횟수가 초기화됨
+func TestCircuitBreakerResetOnSuccess(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+	now := time.Now()
+	b.record("q", errSend, now)
+	b.record("q", nil, now)
+	if opened := b.record("q", errSend, now); opened {
+		t.Error("failure count was not reset by a success")
+	}
+	if !b.allow("q", now) {
+		t.Error("blocked after a success reset the failure count")
+	}
+}
+
+// cooldown이 지나면 시험 호출 1건만 허용하고, 성공하면 닫히고 실패하면 다시 cooldown 동안 차단
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	const cooldown = time.Minute
+	open := func(now time.Time) *circuitBreaker {
+		b := newCircuitBreaker(2, cooldown)
+		b.record("q", errSend, now)
+		b.record("q", errSend, now)
+		return b
+	}
+	start := time.Now()
+	afterCooldown := start.Add(cooldown)
+
+	t.Run("single probe", func(t *testing.T) {
+		b := open(start)
+		if !b.allow("q", afterCooldown) {
+			t.Fatal("probe not allowed after cooldown")
+		}
+		if b.allow("q", afterCooldown) || b.allow("q", afterCooldown.Add(time.Second)) {
+			t.Error("more than one call allowed while the probe is in flight")
+		}
+	})
+
+	t.Run("probe success closes", func(t *testing.T) {
+		b := open(start)
+		b.allow("q", afterCooldown)
+		b.record("q", nil, afterCooldown)
+		for i := 0; i < 3; i++ {
+			if !b.allow("q", afterCooldown) {
+				t.Fatal("blocked after a successful probe")
+			}
+		}
+		if opened := b.record("q", errSend, afterCooldown); opened {
+			t.Error("one failure after recovery reopened the breaker")
+		}
+	})
+
+	t.Run("probe failure reopens", func(t *testing.T) {
+		b := open(start)
+		b.allow("q", afterCooldown)
+		if opened := b.record("q", errSend, afterCooldown); !opened {
+			t.Error("failed probe did not reopen the breaker")
+		}
+		if b.allow("q", afterCooldown.Add(cooldown-time.Second)) {
+			t.Error("allowed before the new cooldown expired")
+		}
+		if !b.allow("q", afterCooldown.Add(cooldown)) {
+			t.Error("next probe not allowed after the new cooldown")
+		}
+	})
+
+	t.Run("unrecorded probe", func(t *testing.T) {
+		// 시험 호출이 결과를 기록하지 못해도 cooldown이 다시 지나면 다음 시험 호출 허용
+		b := open(start)
+		b.allow("q", afterCooldown)
+		if !b.allow("q", afterCooldown.Add(cooldown)) {
+			t.Error("breaker stuck after a probe that never recorded a result")
+		}
+	})
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Minute)
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		if b.record("q", errSend, now) {
+			t.Fatal("disabled breaker opened")
+		}
+	}
+	if !b.allow("q", now) {
+		t.Error("disabled breaker blocked a call")
+	}
+}
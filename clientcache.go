@@ -0,0 +1,78 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// 최근 사용 순서(LRU)로 최대 capacity개까지만 보관하는 클라이언트 캐시
+// SDK 클라이언트는 닫아야 할 연결을 갖지 않으므로 제거 시 별도 정리 없이 버림
+// 동시 접근 보호는 호출 측(clientsMu)에서 담당
+type clientCache[V any] struct {
+	capacity int
+	order    *list.List // 앞쪽일수록 최근 사용
+	entries  map[string]*list.Element
+}
+
+type clientCacheEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newClientCache[V any](capacity int) *clientCache[V] {
+	return &clientCache[V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// 캐시된 값을 반환하고 최근 사용으로 표시
+func (c *clientCache[V]) get(key string) (V, bool) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*clientCacheEntry[V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// 값을 저장하고, 용량을 넘으면 가장 오래 사용하지 않은 항목 제거
+func (c *clientCache[V]) put(key string, value V) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*clientCacheEntry[V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&clientCacheEntry[V]{key: key, value: value})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*clientCacheEntry[V]).key)
+	}
+}
+
+// 캐시에 없으면 잠금 밖에서 create로 생성한 뒤 다시 확인하고 저장
+// 생성(LoadDefaultConfig/AssumeRole)이 느려도 다른 키의 조회를 막지 않으며,
+// 같은 키를 동시에 생성한 경우 먼저 저장된 클라이언트를 사용하고 나머지는 버림
+func cachedClient[V any](mu *sync.Mutex, cache *clientCache[V], key string, create func() (V, error)) (V, error) {
+	mu.Lock()
+	client, ok := cache.get(key)
+	mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return client, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := cache.get(key); ok {
+		return existing, nil
+	}
+	cache.put(key, client)
+	return client, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// 테스트 동안 S3 클라이언트 캐시를 새 캐시로 교체
+func withFreshS3Clients(t *testing.T, capacity int) {
+	t.Helper()
+	clientsMu.Lock()
+	saved := s3Clients
+	s3Clients = newClientCache[*s3.Client](capacity)
+	clientsMu.Unlock()
+	t.Cleanup(func() {
+		clientsMu.Lock()
+		s3Clients = saved
+		clientsMu.Unlock()
+	})
+}
+
+// 여러 고루틴이 새 리전을 동시에 요청해도 리전별로 하나의 클라이언트만 공유되는지 확인 (go test -race로 실행)
+func TestGetS3ClientConcurrentRegions(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	const regions = 8
+	const goroutines = 64
+	withFreshS3Clients(t, regions)
+
+	results := make([]*s3.Client, goroutines)
+	errs := make([]error, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = getS3Client(fmt.Sprintf("test-region-%d", i%regions))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < goroutines; i++ {
+		if errs[i] != nil {
+			t.Fatalf("getS3Client(%d): %v", i, errs[i])
+		}
+		cached, err := getS3Client(fmt.Sprintf("test-region-%d", i%regions))
+		if err != nil {
+			t.Fatalf("getS3Client(%d) again: %v", i, err)
+		}
+		if results[i] != cached {
+			t.Errorf("goroutine %d got a client that is not the cached one for its region", i)
+		}
+	}
+}
+
+// 한 키의 클라이언트 생성이 오래 걸려도 다른 키 조회가 막히지 않는지 확인
+func TestCachedClientDoesNotHoldLockWhileCreating(t *testing.T) {
+	var mu sync.Mutex
+	cache := newClientCache[int](4)
+	cache.put("cached", 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cachedClient(&mu, cache, "slow", func() (int, error) {
+			close(started)
+			<-release
+			return 2, nil
+		})
+	}()
+	<-started
+
+	got := make(chan int, 1)
+	go func() {
+		v, _ := cachedClient(&mu, cache, "cached", func() (int, error) { return 0, fmt.Errorf("unexpected create") })
+		got <- v
+	}()
+	select {
+	case v := <-got:
+		if v != 1 {
+			t.Errorf("cached value = %d, want 1", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lookup of a cached key blocked while another key was being created")
+	}
+	close(release)
+	<-done
+}
+
+// 같은 키를 동시에 생성하면 먼저 저장된 값을 모두가 사용
+func TestCachedClientKeepsFirstInserted(t *testing.T) {
+	var mu sync.Mutex
+	cache := newClientCache[int](4)
+
+	first, err := cachedClient(&mu, cache, "k", func() (int, error) {
+		// 생성 도중 다른 호출이 먼저 저장한 상황
+		mu.Lock()
+		cache.put("k", 7)
+		mu.Unlock()
+		return 8, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 7 {
+		t.Errorf("cachedClient returned %d, want the already cached 7", first)
+	}
+	if v, _ := cache.get("k"); v != 7 {
+		t.Errorf("cache holds %d, want 7", v)
+	}
+}
+
+func TestCachedClientErrorNotCached(t *testing.T) {
+	var mu sync.Mutex
+	cache := newClientCache[int](4)
+	if _, err := cachedClient(&mu, cache, "k", func() (int, error) { return 0, fmt.Errorf("boom") }); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := cache.get("k"); ok {
+		t.Error("failed creation was cached")
+	}
+}
+
+// 용량을 넘게 넣으면 가장 오래 사용하지 않은 키부터 제거되고, 조회한 키는 최근 사용으로 유지
+func TestClientCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newClientCache[int](3)
+	for i, key := range []string{"a", "b", "c"} {
+		cache.put(key, i)
+	}
+	// a를 조회하면 가장 오래된 항목은 b가 됨
+	if v, ok := cache.get("a"); !ok || v != 0 {
+		t.Fatalf("get(a) = %d, %v, want 0, true", v, ok)
+	}
+	cache.put("d", 3)
+	cache.put("e", 4)
+
+	for _, key := range []string{"b", "c"} {
+		if _, ok := cache.get(key); ok {
+			t.Errorf("%s should have been evicted", key)
+		}
+	}
+	for key, want := range map[string]int{"a": 0, "d": 3, "e": 4} {
+		if v, ok := cache.get(key); !ok || v != want {
+			t.Errorf("get(%s) = %d, %v, want %d, true", key, v, ok, want)
+		}
+	}
+	if n := cache.order.Len(); n != 3 || len(cache.entries) != 3 {
+		t.Errorf("cache holds %d/%d entries, want 3", n, len(cache.entries))
+	}
+}
+
+// 이미 있는 키를 다시 넣으면 값만 바꾸고 최근 사용으로 표시 (항목 수는 늘지 않음)
+func TestClientCachePutExistingKey(t *testing.T) {
+	cache := newClientCache[int](2)
+	cache.put("a", 1)
+	cache.put("b", 2)
+	cache.put("a", 10)
+	cache.put("c", 3)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, _ := cache.get("a"); v != 10 {
+		t.Errorf("get(a) = %d, want 10", v)
+	}
+}
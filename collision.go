@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// 대상 객체가 이미 있을 때의 처리 방식 (collisionStrategy, 비어있으면 overwrite 필드에 따름)
+const (
+	CollisionFail      = "fail"      // TARGET_EXISTS로 실패 (기본 동작)
+	CollisionOverwrite = "overwrite" // 덮어쓰기 (overwrite: true와 같음)
+	CollisionRename    = "rename"    // 확장자 앞에 -1, -2, ... 를 붙인 비어있는 키에 업로드
+	MaxRenameAttempts  = 100         // rename에서 시도할 최대 번호
+)
+
+func validateCollisionStrategy(event FileCompressionForm) error {
+	switch event.CollisionStrategy {
+	case "", CollisionOverwrite:
+		return nil
+	case CollisionFail, CollisionRename:
+	default:
+		return fmt.Errorf("unsupported collisionStrategy: %s (expected fail, overwrite or rename)", event.CollisionStrategy)
+	}
+	switch {
+	case event.Overwrite:
+		return fmt.Errorf("collisionStrategy %s cannot be used with overwrite", event.CollisionStrategy)
+	case event.CollisionStrategy == CollisionRename && event.Append:
+		return fmt.Errorf("collisionStrategy rename cannot be used with append")
+	case event.CollisionStrategy == CollisionRename && event.Operation == OperationDecompress:
+		return fmt.Errorf("collisionStrategy rename is not supported for decompress")
+	}
+	return nil
+}
+
+// key의 확장자 앞에 -n을 붙인 키 (압축 포맷 확장자로 끝나면 그 앞, 아니면 마지막 확장자 앞, 예: logs/app.log.gz → logs/app.log-1.gz)
+func renameCandidate(key, extension string, n int) string {
+	ext := path.Ext(key)
+	if extension != "" && strings.HasSuffix(key, extension) {
+		ext = extension
+	}
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(key, ext), n, ext)
+}
+
+// 대상 키가 비어있으면 그대로, 이미 있으면 -1부터 MaxRenameAttempts까지 붙여 보며 처음으로 비어있는 키 반환
+// 분할 압축이면 첫 번째 볼륨 키로 확인
+func resolveCollisionKey(ctx context.Context, job compressionJob) (string, error) {
+	suffix := ""
+	if job.SevenZip.VolumeSize != "" {
+		suffix = firstVolumeSuffix
+	}
+	candidate := job.TargetKey
+	for n := 0; n <= MaxRenameAttempts; n++ {
+		if n > 0 {
+			candidate = renameCandidate(job.TargetKey, job.Format.Extension, n)
+		}
+		exists, err := objectExists(ctx, job.TargetClient, job.TargetBucket, candidate+suffix, "")
+		if err != nil {
+			return "", newCompressionError(ErrCodeUpload, err)
+		}
+		if !exists {
+			if n > 0 {
+				loggerFrom(ctx).Info("Target exists, using renamed key", "stage", "validate", "targetKey", job.TargetKey, "renamedKey", candidate)
+			}
+			return candidate, nil
+		}
+	}
+	return "", newCompressionError(ErrCodeTargetExists, fmt.Errorf("target object already exists and no free key found after %d attempts: %s/%s", MaxRenameAttempts, job.TargetBucket, job.TargetKey))
+}
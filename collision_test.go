@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRenameCandidate(t *testing.T) {
+	tests := []struct {
+		key, extension string
+		n              int
+		want           string
+	}{
+		{"logs/app.gz", ".gz", 1, "logs/app-1.gz"},
+		{"logs/app.log.gz", ".gz", 2, "logs/app.log-2.gz"},
+		// 여러 단계 확장자는 포맷 확장자 전체 앞에 번호를 붙임
+		{"logs/app.tar.gz", ".tar.gz", 1, "logs/app-1.tar.gz"},
+		{"logs/app.tar.zst", ".tar.zst", 3, "logs/app-3.tar.zst"},
+		// 포맷 확장자로 끝나지 않으면 마지막 확장자 앞
+		{"logs/app.archive", ".7z", 1, "logs/app-1.archive"},
+		{"logs/app", ".gz", 1, "logs/app-1"},
+	}
+	for _, tt := range tests {
+		if got := renameCandidate(tt.key, tt.extension, tt.n); got != tt.want {
+			t.Errorf("renameCandidate(%q, %q, %d) = %q, want %q", tt.key, tt.extension, tt.n, got, tt.want)
+		}
+	}
+}
+
+func collisionTestJob(s3c *fakeS3, key, extension string) compressionJob {
+	return compressionJob{
+		Format:       compressionFormat{Extension: extension},
+		TargetBucket: "target-bucket",
+		TargetKey:    key,
+		TargetClient: s3c,
+	}
+}
+
+func TestResolveCollisionKey(t *testing.T) {
+	t.Run("free key", func(t *testing.T) {
+		s3c := newFakeS3()
+		key, err := resolveCollisionKey(context.Background(), collisionTestJob(s3c, "logs/app.gz", ".gz"))
+		if err != nil || key != "logs/app.gz" {
+			t.Errorf("resolveCollisionKey = %q, %v", key, err)
+		}
+	})
+	t.Run("first free number", func(t *testing.T) {
+		s3c := newFakeS3()
+		for _, k := range []string{"logs/app.tar.gz", "logs/app-1.tar.gz", "logs/app-3.tar.gz"} {
+			s3c.putObject("target-bucket", k, testContent, "application/gzip")
+		}
+		key, err := resolveCollisionKey(context.Background(), collisionTestJob(s3c, "logs/app.tar.gz", ".tar.gz"))
+		if err != nil || key != "logs/app-2.tar.gz" {
+			t.Errorf("resolveCollisionKey = %q, %v, want logs/app-2.tar.gz", key, err)
+		}
+	})
+	t.Run("volumes", func(t *testing.T) {
+		s3c := newFakeS3()
+		s3c.putObject("target-bucket", "logs/app.7z.001", testContent, "application/x-7z-compressed")
+		job := collisionTestJob(s3c, "logs/app.7z", ".7z")
+		job.SevenZip.VolumeSize = "10m"
+		key, err := resolveCollisionKey(context.Background(), job)
+		if err != nil || key != "logs/app-1.7z" {
+			t.Errorf("resolveCollisionKey = %q, %v, want logs/app-1.7z", key, err)
+		}
+	})
+	t.Run("gives up", func(t *testing.T) {
+		s3c := newFakeS3()
+		s3c.putObject("target-bucket", "logs/app.gz", testContent, "application/gzip")
+		for n := 1; n <= MaxRenameAttempts; n++ {
+			s3c.putObject("target-bucket", fmt.Sprintf("logs/app-%d.gz", n), testContent, "application/gzip")
+		}
+		key, err := resolveCollisionKey(context.Background(), collisionTestJob(s3c, "logs/app.gz", ".gz"))
+		if errorCode(err) != ErrCodeTargetExists {
+			t.Errorf("resolveCollisionKey = %q, %v, want %s", key, err, ErrCodeTargetExists)
+		}
+	})
+}
+
+// rename이면 이미 있는 대상을 덮어쓰지 않고 번호를 붙인 키에 업로드하고 결과에도 그 키를 반환
+func TestHandlerCollisionRename(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	s3c.putObject("target-bucket", "logs/app.gz", []byte("existing"), "application/gzip")
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.CollisionStrategy = CollisionRename
+
+	result, err := Handler(context.Background(), event)
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	if result.Key != "logs/app-1.gz" {
+		t.Errorf("result key = %s, want logs/app-1.gz", result.Key)
+	}
+	if string(s3c.object("target-bucket", "logs/app.gz").data) != "existing" {
+		t.Error("existing target overwritten")
+	}
+	if obj := s3c.object("target-bucket", "logs/app-1.gz"); obj == nil || string(gunzip(t, obj.data)) != string(testContent) {
+		t.Error("renamed target not uploaded")
+	}
+}
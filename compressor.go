@@ -0,0 +1,152 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 임시 파일 모드의 압축 구현 (7za 바이너리, Go 라이브러리 등)
+// Handler와 무관하게 새 포맷의 구현을 추가하거나 테스트에서 가짜 구현으로 대체할 수 있도록 분리
+type Compressor interface {
+	Compress(ctx context.Context, inputPaths []string, outputPath string, opts sevenZipOptions) error
+}
+
+// 포맷과 실행 환경에 맞는 압축 구현 선택
+// 7za가 지원하지 않는 포맷(TypeFlag 없음)은 항상 Go 구현을 사용하고,
+// 7za가 없으면 Go 구현이 있는 포맷에 한해 대체 (로컬 테스트 시에도 사용)
+func selectCompressor(ctx context.Context, format compressionFormat) Compressor {
+	if format.TypeFlag == "" {
+		return format.Native
+	}
+	if format.Native != nil {
+		if _, err := os.Stat(sevenZipCmd); os.IsNotExist(err) {
+			loggerFrom(ctx).Warn("7za binary not found, using Go implementation", "stage", "compress", "path", sevenZipCmd)
+			return format.Native
+		}
+	}
+	return SevenZipCompressor{Format: format}
+}
+
+// 7za 바이너리 프로그램으로 압축
+type SevenZipCompressor struct {
+	Format compressionFormat
+}
+
+func (c SevenZipCompressor) Compress(ctx context.Context, inputPaths []string, outputPath string, opts sevenZipOptions) error {
+	if _, err := os.Stat(sevenZipCmd); os.IsNotExist(err) {
+		return fmt.Errorf("7za binary not found: %s", sevenZipCmd)
+	}
+	args := []string{"a", c.Format.TypeFlag}
+	args = append(args, opts.flags(c.Format)...)
+	if opts.Verbose {
+		args = append(args, SevenZipProgressOut)
+	}
+	args = append(args, outputPath)
+	args = append(args, inputPaths...)
+	loggerFrom(ctx).Info("Running 7za", "stage", "compress", "args", redactArgs(args))
+	// 일시적인 오류(/tmp 공간 부족 등)로 실패하면 남은 출력을 지우고 다시 실행 (7za a는 기존 아카이브에 추가하므로 매번 정리)
+	err := withRetry(ctx, "compress", sevenZipRetries, func() error {
+		removePartialOutput(outputPath)
+		return runSevenZipCompress(ctx, args, opts)
+	})
+	if err != nil {
+		return err
+	}
+	loggerFrom(ctx).Info("7za compression successful", "stage", "compress")
+	return nil
+}
+
+// 임시 파일 없이 Writer로 바로 압축할 수 있는 구현 (스트리밍 모드에서 S3 → 압축 → S3로 연결)
+// 반환된 Writer의 Close가 끝나야 압축 결과가 모두 w에 기록됨
+type StreamCompressor interface {
+	NewWriter(w io.Writer, name string, opts sevenZipOptions) (io.WriteCloser, error)
+}
+
+// Go compress/gzip으로 단일 파일 압축 (level 0이면 기본 레벨)
+type GzipCompressor struct{}
+
+func (c GzipCompressor) Compress(ctx context.Context, inputPaths []string, outputPath string, opts sevenZipOptions) error {
+	if len(inputPaths) != 1 {
+		return fmt.Errorf("gzip supports a single input file, got %d", len(inputPaths))
+	}
+	loggerFrom(ctx).Info("Running gzip", "stage", "compress", "file", filepath.Base(inputPaths[0]), "level", opts.Level)
+	return compressFileWith(ctx, c, "gzip", inputPaths[0], outputPath, opts)
+}
+
+func (GzipCompressor) NewWriter(w io.Writer, name string, opts sevenZipOptions) (io.WriteCloser, error) {
+	level := opts.Level
+	if level <= 0 {
+		level = gzip.DefaultCompression
+	}
+	zw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	zw.Name = name
+	return zw, nil
+}
+
+// klauspost/compress/zstd로 단일 파일 압축 (zstd 바이너리 불필요)
+// level 0이면 기본 레벨, 그 외에는 zstd 레벨(1~22)에 가장 가까운 인코더 레벨 사용
+type ZstdCompressor struct{}
+
+func (c ZstdCompressor) Compress(ctx context.Context, inputPaths []string, outputPath string, opts sevenZipOptions) error {
+	if len(inputPaths) != 1 {
+		return fmt.Errorf("zstd supports a single input file, got %d", len(inputPaths))
+	}
+	loggerFrom(ctx).Info("Running zstd", "stage", "compress", "file", filepath.Base(inputPaths[0]), "level", opts.Level)
+	return compressFileWith(ctx, c, "zstd", inputPaths[0], outputPath, opts)
+}
+
+func (ZstdCompressor) NewWriter(w io.Writer, name string, opts sevenZipOptions) (io.WriteCloser, error) {
+	encoderOpts := []zstd.EOption{}
+	if opts.Level > 0 {
+		encoderOpts = append(encoderOpts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(opts.Level)))
+	}
+	if opts.Threads > 0 {
+		encoderOpts = append(encoderOpts, zstd.WithEncoderConcurrency(opts.Threads))
+	}
+	zw, err := zstd.NewWriter(w, encoderOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	return zw, nil
+}
+
+// 입력 파일을 StreamCompressor의 Writer로 압축하여 출력 파일에 기록
+func compressFileWith(ctx context.Context, c StreamCompressor, name, inputPath, outputPath string, opts sevenZipOptions) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer in.Close()
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer out.Close()
+
+	zw, err := c.NewWriter(out, filepath.Base(inputPath), opts)
+	if err != nil {
+		return err
+	}
+	if gw, ok := zw.(*gzip.Writer); ok {
+		if info, err := in.Stat(); err == nil {
+			gw.ModTime = info.ModTime()
+		}
+	}
+	if _, err := copyBuffered(zw, &contextReader{ctx: ctx, r: in}); err != nil {
+		zw.Close()
+		return fmt.Errorf("%s error: %w", name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("%s error: %w", name, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// script를 본문으로 하는 실행 가능한 셸 스크립트를 만들고 경로 반환
+func writeStub(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "7za")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// 테스트 동안 7za 대신 path를 실행
+func useSevenZip(t *testing.T, path string) {
+	t.Helper()
+	saved := sevenZipCmd
+	sevenZipCmd = path
+	t.Cleanup(func() { sevenZipCmd = saved })
+}
+
+func TestLoadPathConfig(t *testing.T) {
+	savedCmd, savedTemp := sevenZipCmd, tempDir
+	t.Cleanup(func() { sevenZipCmd, tempDir = savedCmd, savedTemp })
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("SEVENZIP_PATH", "")
+		t.Setenv("TEMP_DIR", "")
+		loadPathConfig()
+		if sevenZipCmd != SevenZipCmd || tempDir != TempDir {
+			t.Errorf("paths = %s, %s, want %s, %s", sevenZipCmd, tempDir, SevenZipCmd, TempDir)
+		}
+	})
+
+	t.Run("env overrides invoke stub", func(t *testing.T) {
+		dir := t.TempDir()
+		marker := filepath.Join(dir, "invoked")
+		stub := writeStub(t, `echo "$@" > `+marker+"\n")
+		work := t.TempDir()
+		t.Setenv("SEVENZIP_PATH", stub)
+		t.Setenv("TEMP_DIR", work)
+		loadPathConfig()
+		if sevenZipCmd != stub || tempDir != work {
+			t.Fatalf("paths = %s, %s, want %s, %s", sevenZipCmd, tempDir, stub, work)
+		}
+
+		workDir, err := createWorkDir("uuid")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(workDir) != work {
+			t.Errorf("work dir %s is not under TEMP_DIR %s", workDir, work)
+		}
+		input := filepath.Join(workDir, "in.txt")
+		if err := os.WriteFile(input, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		output := filepath.Join(workDir, "out.7z")
+		if err := (SevenZipCompressor{Format: compressionFormats["7z"]}).Compress(context.Background(), []string{input}, output, sevenZipOptions{}); err != nil {
+			t.Fatalf("Compress: %v", err)
+		}
+		args, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("stub was not invoked: %v", err)
+		}
+		if want := "a " + SevenZipFormatFlag; !strings.HasPrefix(string(args), want) || !strings.Contains(string(args), output) {
+			t.Errorf("stub args = %q, want prefix %q and output %s", args, want, output)
+		}
+	})
+}
+
+// 실행 중에 context를 취소하면 7za 프로세스가 종료되고 context 에러가 반환되는지 확인
+func TestCompressCancelKillsSevenZip(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	// exec로 셸을 sleep으로 바꾸어 기록한 PID가 곧 kill 대상 프로세스가 되도록 함
+	useSevenZip(t, writeStub(t, "echo $$ > "+pidFile+".tmp && mv "+pidFile+".tmp "+pidFile+"\nexec sleep 30\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- SevenZipCompressor{Format: compressionFormats["7z"]}.Compress(ctx, []string{filepath.Join(dir, "in")}, filepath.Join(dir, "out.7z"), sevenZipOptions{})
+	}()
+
+	var pid int
+	for deadline := time.Now().Add(5 * time.Second); pid == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("stub 7za did not start")
+		}
+		if data, err := os.ReadFile(pidFile); err == nil {
+			pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Compress error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compress did not return after cancellation")
+	}
+	if err := syscall.Kill(pid, 0); !errors.Is(err, syscall.ESRCH) {
+		t.Errorf("7za process %d is still running after cancellation (kill -0: %v)", pid, err)
+	}
+}
+
+// 7za 명령행과 출력(실패, 경고)을 로그로 남길 때 암호가 드러나지 않는지 확인
+func TestSevenZipPasswordNotLogged(t *testing.T) {
+	const password = "s3cr3t-Passw0rd"
+	savedRetries := sevenZipRetries
+	sevenZipRetries = 0
+	t.Cleanup(func() { sevenZipRetries = savedRetries })
+
+	for _, exitCode := range []int{SevenZipExitWarning, SevenZipExitFatal} {
+		t.Run(strconv.Itoa(exitCode), func(t *testing.T) {
+			// 받은 인자(암호 포함)를 그대로 출력하고 실패하는 7za
+			useSevenZip(t, writeStub(t, "echo \"Command line: $@\"\necho \"Wrong password? $@\" >&2\nexit "+strconv.Itoa(exitCode)+"\n"))
+			var logs bytes.Buffer
+			ctx := withLogger(context.Background(), slog.New(slog.NewJSONHandler(&logs, nil)))
+			dir := t.TempDir()
+			opts := sevenZipOptions{Password: password, TolerateWarnings: exitCode == SevenZipExitWarning}
+			err := SevenZipCompressor{Format: compressionFormats["7z"]}.Compress(ctx, []string{filepath.Join(dir, "in")}, filepath.Join(dir, "out.7z"), opts)
+			if exitCode == SevenZipExitFatal && err == nil {
+				t.Fatal("Compress succeeded, want 7za failure")
+			}
+			if !strings.Contains(logs.String(), "Wrong password?") {
+				t.Fatalf("7za output was not logged: %s", logs.String())
+			}
+			if strings.Contains(logs.String(), password) {
+				t.Errorf("password appears in logs: %s", logs.String())
+			}
+			if err != nil && strings.Contains(err.Error(), password) {
+				t.Errorf("password appears in error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	got := redactArgs([]string{"a", "-t7z", SevenZipPassFlag + "hunter2", SevenZipHeaderFlag, "out.7z"})
+	if want := "a -t7z -p**** -mhe=on out.7z"; got != want {
+		t.Errorf("redactArgs = %q, want %q", got, want)
+	}
+}
+
+// 7za 종료 코드별 에러 코드, 경고 허용 여부, 재시도 여부 확인
+func TestSevenZipExitCodes(t *testing.T) {
+	savedRetries := sevenZipRetries
+	sevenZipRetries = 1
+	t.Cleanup(func() { sevenZipRetries = savedRetries })
+
+	tests := []struct {
+		exitCode         int
+		tolerateWarnings bool
+		wantCode         string // 비어있으면 성공
+		wantRuns         int
+	}{
+		{0, false, "", 1},
+		{SevenZipExitWarning, false, ErrCodeCompressWarn, 1},
+		{SevenZipExitWarning, true, "", 1},
+		{SevenZipExitFatal, false, ErrCodeCompress, 2},
+		{SevenZipExitCommandLine, false, ErrCodeCompressArgs, 1},
+		{SevenZipExitMemory, false, ErrCodeOutOfMemory, 2},
+		{SevenZipExitUserStopped, false, ErrCodeCompress, 1},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.exitCode)+"/"+strconv.FormatBool(tt.tolerateWarnings), func(t *testing.T) {
+			dir := t.TempDir()
+			runs := filepath.Join(dir, "runs")
+			useSevenZip(t, writeStub(t, "echo run >> "+runs+"\nexit "+strconv.Itoa(tt.exitCode)+"\n"))
+			err := SevenZipCompressor{Format: compressionFormats["7z"]}.Compress(context.Background(), []string{filepath.Join(dir, "in")}, filepath.Join(dir, "out.7z"), sevenZipOptions{TolerateWarnings: tt.tolerateWarnings})
+			if got := errorCode(err); tt.wantCode == "" && err != nil || tt.wantCode != "" && got != tt.wantCode {
+				t.Errorf("error = %v (code %s), want code %q", err, got, tt.wantCode)
+			}
+			if err != nil && !strings.Contains(err.Error(), "exit code "+strconv.Itoa(tt.exitCode)) {
+				t.Errorf("error %q does not mention the exit code", err)
+			}
+			data, _ := os.ReadFile(runs)
+			if n := strings.Count(string(data), "run"); n != tt.wantRuns {
+				t.Errorf("7za ran %d times, want %d", n, tt.wantRuns)
+			}
+		})
+	}
+}
+
+// 7za l -slt 출력 예시 (7z 아카이브: 디렉토리 항목은 Folder = +)
+const sevenZipListing = `
+7-Zip (a) [64] 16.02 : Copyright (c) 1999-2016 Igor Pavlov : 2016-05-21
+
+Scanning the drive for archives:
+1 file, 1234 bytes (2 KiB)
+
+Listing archive: /tmp/job/archive.7z
+
+--
+Path = /tmp/job/archive.7z
+Type = 7z
+Physical Size = 1234
+
+----------
+Path = logs
+Size = 0
+Folder = +
+Attributes = D_ drwxr-xr-x
+
+Path = logs/app.log
+Size = 1000
+Folder = -
+Attributes = A_ -rw-r--r--
+
+Path = logs/Path = tricky.log
+Size = 10
+Folder = -
+Attributes = A_ -rw-r--r--
+
+Path = readme.txt
+Size = 20
+Folder = -
+Attributes = A_ -rw-r--r--
+`
+
+// gzip 등 Folder 줄이 없는 포맷 (출력 끝에 빈 줄 없음)
+const gzipListing = `Listing archive: app.log.gz
+
+--
+Path = app.log.gz
+Type = gzip
+
+----------
+Path = app.log
+Size = 1000
+Attributes = A_ -rw-r--r--`
+
+func TestParseArchiveListing(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"7z with folders", sevenZipListing, []string{"logs/app.log", "logs/Path = tricky.log", "readme.txt"}},
+		{"gzip without folder lines", gzipListing, []string{"app.log"}},
+		{"windows line endings", strings.ReplaceAll(gzipListing, "\n", "\r\n"), []string{"app.log"}},
+		{"directory by attributes only", "----------\nPath = dir\nAttributes = D_ drwxr-xr-x\n\nPath = dir/a\n", []string{"dir/a"}},
+		{"archive header only", "--\nPath = empty.7z\nType = 7z\n", nil},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseArchiveListing(tt.output); !slices.Equal(got, tt.want) {
+				t.Errorf("parseArchiveListing = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// 목록 조회는 7za l -slt <비밀번호 플래그> <아카이브>를 실행하고 출력에서 비밀번호를 가림
+func TestListArchiveFiles(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	listing := strings.Replace(gzipListing, "Path = app.log\n", "Path = app-s3cret.log\n", 1)
+	useSevenZip(t, writeStub(t, `echo "$@" > `+argsFile+`
+cat <<'LISTING'
+`+listing+`
+LISTING
+`))
+	opts := sevenZipOptions{Password: "s3cret"}
+
+	files, err := listArchiveFiles(context.Background(), "/tmp/job/app.log.gz", SevenZipPassFlag+opts.Password, opts)
+	if err != nil {
+		t.Fatalf("listArchiveFiles: %v", err)
+	}
+	if want := []string{"app-****.log"}; !slices.Equal(files, want) {
+		t.Errorf("listArchiveFiles = %q, want %q", files, want)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(args)), "l -slt "+SevenZipPassFlag+"s3cret /tmp/job/app.log.gz"; got != want {
+		t.Errorf("7za args = %q, want %q", got, want)
+	}
+
+	useSevenZip(t, writeStub(t, "echo 'Can not open the file as archive' >&2\nexit 2\n"))
+	if _, err := listArchiveFiles(context.Background(), "/tmp/job/broken.gz", SevenZipPassFlag, sevenZipOptions{}); err == nil {
+		t.Error("listArchiveFiles succeeded when 7za failed")
+	}
+}
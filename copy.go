@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// 단일 CopyObject 요청으로 복사할 수 있는 최대 크기 (5GiB)
+const MaxCopyObjectBytes = 5 * 1024 * 1024 * 1024
+
+// copyOnly 요청 검사: 단일 원본을 변환 없이 복사하므로 압축/변환 관련 옵션과 함께 사용할 수 없음
+func validateCopyOnly(event FileCompressionForm) error {
+	if !event.CopyOnly {
+		return nil
+	}
+	switch {
+	case event.OriginKey == "" && event.Mode != ModeIndividual:
+		return fmt.Errorf("copyOnly requires originKey, or originKeys in %s mode", ModeIndividual)
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("copyOnly is not supported for %s", event.Operation)
+	case event.StreamMode:
+		return fmt.Errorf("copyOnly cannot be used with streamMode")
+	case event.VolumeSize != "" || event.Password != "":
+		return fmt.Errorf("copyOnly cannot be used with volumeSize or password")
+	case event.RangeStart != nil || event.RangeEnd != nil:
+		return fmt.Errorf("copyOnly cannot be used with a byte range")
+	case event.VerifyChecksum:
+		return fmt.Errorf("copyOnly cannot be used with verifyChecksum")
+	case event.TargetKey == "" && event.TargetKeyTemplate == "" && defaultIfEmpty(event.TargetBucket, event.OriginBucket) == event.OriginBucket:
+		return fmt.Errorf("copyOnly requires a different target bucket, targetKey or targetKeyTemplate")
+	}
+	return nil
+}
+
+// 다운로드/압축/업로드 없이 S3 서버 측 복사(CopyObject)로 원본을 대상에 그대로 복사
+// 대상 리전 클라이언트로 요청하므로 리전이 달라도 동작 (TargetRoleArn을 쓰면 그 역할에 원본 읽기 권한 필요)
+func runServerSideCopy(ctx context.Context, job compressionJob) (compressionStats, error) {
+	var stats compressionStats
+	loggerFrom(ctx).Info("Using server-side copy, skipping download/compress/upload", "stage", "copy", "originRegion", job.OriginRegion, "targetRegion", job.TargetRegion)
+
+	if !job.Event.Overwrite {
+		exists, err := objectExists(ctx, job.TargetClient, job.TargetBucket, job.TargetKey, "")
+		if err != nil {
+			loggerFrom(ctx).Error("Target existence check failed", "stage", "copy", errorAttr(err))
+			return stats, newCompressionError(ErrCodeUpload, err)
+		}
+		if exists {
+			return stats, newCompressionError(ErrCodeTargetExists, fmt.Errorf("target object already exists: %s/%s", job.TargetBucket, job.TargetKey))
+		}
+	}
+
+	if err := injectFault("download"); err != nil {
+		return stats, newCompressionError(ErrCodeDownload, err)
+	}
+	origin, err := headObject(ctx, job.OriginClient, job.Event.OriginBucket, job.Event.OriginKey, job.Event.OriginVersionId)
+	if err == nil && origin == nil {
+		err = newCompressionError(ErrCodeOriginNotFound, fmt.Errorf("origin object not found: %s/%s", job.Event.OriginBucket, job.Event.OriginKey))
+	}
+	if err != nil {
+		loggerFrom(ctx).Error("Origin check failed", "stage", "copy", errorAttr(err))
+		return stats, withErrorCode(ErrCodeDownload, err)
+	}
+	size := aws.ToInt64(origin.ContentLength)
+	if err := checkEmptyOrigin(ctx, job, job.Event.OriginKey, size); err != nil {
+		return stats, err
+	}
+	if size > MaxCopyObjectBytes {
+		return stats, newCompressionError(ErrCodeValidation, fmt.Errorf("copyOnly supports objects up to %d bytes, got %d", int64(MaxCopyObjectBytes), size))
+	}
+
+	// 바이트가 그대로이므로 원본 ContentType을 사용하고, 메타데이터는 압축 결과와 같은 규칙으로 설정 (REPLACE)
+	opts := job.Upload
+	opts.ContentType = aws.ToString(origin.ContentType)
+	if job.Event.PreserveMetadata {
+		opts = opts.withOriginMetadata(objectInfo{ContentType: opts.ContentType, Metadata: origin.Metadata}, job.Event.OriginKey)
+	}
+	if job.Event.PreserveTimestamp {
+		opts = opts.withOriginTimestamp(aws.ToTime(origin.LastModified))
+	}
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(job.TargetBucket),
+		Key:               aws.String(job.TargetKey),
+		CopySource:        aws.String(copySource(job.Event.OriginBucket, job.Event.OriginKey, job.Event.OriginVersionId)),
+		MetadataDirective: s3types.MetadataDirectiveReplace,
+		TaggingDirective:  s3types.TaggingDirectiveReplace,
+		Metadata:          opts.Metadata,
+	}
+	if contentType := defaultIfEmpty(opts.ContentTypeOverride, opts.ContentType); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if opts.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if opts.SSEAlgorithm != "" {
+		input.ServerSideEncryption = opts.SSEAlgorithm
+	}
+	if opts.SSEKmsKeyId != "" {
+		input.SSEKMSKeyId = aws.String(opts.SSEKmsKeyId)
+	}
+	if opts.StorageClass != "" {
+		input.StorageClass = opts.StorageClass
+	}
+	if opts.Tagging != "" {
+		input.Tagging = aws.String(opts.Tagging)
+	}
+	if opts.ObjectLockMode != "" {
+		input.ObjectLockMode = opts.ObjectLockMode
+		input.ObjectLockRetainUntilDate = aws.Time(opts.ObjectLockRetainUntil)
+	}
+
+	if err := injectFault("upload"); err != nil {
+		return stats, newCompressionError(ErrCodeUpload, err)
+	}
+	start := time.Now()
+	err = withRetry(ctx, "copy", job.MaxRetries, func() error {
+		_, err := job.TargetClient.CopyObject(ctx, input)
+		return err
+	})
+	if err != nil {
+		loggerFrom(ctx).Error("Server-side copy failed", "stage", "copy", durationAttr(time.Since(start)), errorAttr(err))
+		return stats, newCompressionError(ErrCodeUpload, fmt.Errorf("failed to copy S3 object: %w", err))
+	}
+	stats.OriginalSize = size
+	stats.CompressedSize = size
+	stats.UploadDuration = time.Since(start)
+	loggerFrom(ctx).Info("Server-side copy success", "stage", "copy", bytesAttr(size), durationAttr(stats.UploadDuration))
+	return stats, nil
+}
+
+// CopyObject의 CopySource 값 (URL 인코딩된 bucket/key, 버전 지정 시 ?versionId=)
+func copySource(bucket, key, versionId string) string {
+	source := url.PathEscape(bucket + "/" + key)
+	if versionId != "" {
+		source += "?versionId=" + url.QueryEscape(versionId)
+	}
+	return source
+}
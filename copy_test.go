@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestValidateCopyOnly(t *testing.T) {
+	base := FileCompressionForm{CopyOnly: true, OriginBucket: "origin-bucket", OriginKey: "logs/app.log", TargetBucket: "target-bucket"}
+	tests := []struct {
+		name    string
+		modify  func(*FileCompressionForm)
+		wantErr bool
+	}{
+		{"valid", func(e *FileCompressionForm) {}, false},
+		{"same bucket with targetKey", func(e *FileCompressionForm) { e.TargetBucket, e.TargetKey = "", "copies/app.log" }, false},
+		{"individual", func(e *FileCompressionForm) {
+			e.OriginKey, e.OriginKeys, e.Mode = "", []string{"a", "b"}, ModeIndividual
+		}, false},
+		{"no originKey", func(e *FileCompressionForm) { e.OriginKey, e.OriginKeys = "", []string{"a", "b"} }, true},
+		{"decompress", func(e *FileCompressionForm) { e.Operation = OperationDecompress }, true},
+		{"stream", func(e *FileCompressionForm) { e.StreamMode = true }, true},
+		{"volumeSize", func(e *FileCompressionForm) { e.VolumeSize = "10m" }, true},
+		{"password", func(e *FileCompressionForm) { e.Password = "secret" }, true},
+		{"range", func(e *FileCompressionForm) { e.RangeStart = aws.Int64(0) }, true},
+		{"verifyChecksum", func(e *FileCompressionForm) { e.VerifyChecksum = true }, true},
+		{"same bucket and key", func(e *FileCompressionForm) { e.TargetBucket = "" }, true},
+	}
+	for _, tt := range tests {
+		event := base
+		tt.modify(&event)
+		if err := validateCopyOnly(event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateCopyOnly = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	tests := []struct {
+		bucket, key, versionId string
+		want                   string
+	}{
+		{"origin-bucket", "logs/app.log", "", "origin-bucket%2Flogs%2Fapp.log"},
+		{"origin-bucket", "logs/a b+c.log", "", "origin-bucket%2Flogs%2Fa%20b+c.log"},
+		{"origin-bucket", "logs/app.log", "v1+/=", "origin-bucket%2Flogs%2Fapp.log?versionId=v1%2B%2F%3D"},
+	}
+	for _, tt := range tests {
+		got := copySource(tt.bucket, tt.key, tt.versionId)
+		if got != tt.want {
+			t.Errorf("copySource(%q, %q, %q) = %q, want %q", tt.bucket, tt.key, tt.versionId, got, tt.want)
+		}
+		// S3가 디코딩하면 원래 bucket/key가 나와야 함
+		path, _, _ := strings.Cut(got, "?")
+		if unescaped, err := url.PathUnescape(path); err != nil || unescaped != tt.bucket+"/"+tt.key {
+			t.Errorf("decoded copy source = %q, %v", unescaped, err)
+		}
+	}
+}
+
+// copyOnly는 다운로드/업로드 없이 CopyObject 한 번으로 복사하고, 압축하지 않았으므로 compressed=false 태그를 붙임
+func TestServerSideCopy(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.CopyOnly = true
+	event.TargetKey = "copies/app.log"
+	event.OriginVersionId = "v1"
+	event.Tags = TagSet{"team": "data"}
+
+	if _, err := Handler(context.Background(), event); err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	if len(s3c.copies) != 1 || len(s3c.gets) != 0 || len(s3c.puts) != 0 || len(s3c.creates) != 0 {
+		t.Fatalf("copies %d, gets %d, puts %d, multipart %d, want a single copy", len(s3c.copies), len(s3c.gets), len(s3c.puts), len(s3c.creates))
+	}
+	in := s3c.copies[0]
+	if got := aws.ToString(in.CopySource); got != copySource("origin-bucket", "logs/app.log", "v1") {
+		t.Errorf("CopySource = %s", got)
+	}
+	if aws.ToString(in.Bucket) != "target-bucket" || aws.ToString(in.Key) != "copies/app.log" {
+		t.Errorf("copy target = %s/%s", aws.ToString(in.Bucket), aws.ToString(in.Key))
+	}
+	if in.MetadataDirective != s3types.MetadataDirectiveReplace || in.TaggingDirective != s3types.TaggingDirectiveReplace {
+		t.Errorf("directives = %s, %s, want REPLACE", in.MetadataDirective, in.TaggingDirective)
+	}
+	if aws.ToString(in.ContentType) != "text/plain" {
+		t.Errorf("ContentType = %s, want origin content type", aws.ToString(in.ContentType))
+	}
+	tags, err := url.ParseQuery(aws.ToString(in.Tagging))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Get(TagKeyCompressed) != "false" || tags.Get(TagKeyProcessUuid) != "test-uuid" || tags.Get("team") != "data" {
+		t.Errorf("Tagging = %s", aws.ToString(in.Tagging))
+	}
+	if obj := s3c.object("target-bucket", "copies/app.log"); obj == nil || string(obj.data) != string(testContent) {
+		t.Error("copied object content differs from origin")
+	}
+}
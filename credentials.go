@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// 요청에 직접 지정한 임시 자격 증명 (격리된 통합 테스트 전용)
+// 비어있으면 기본 자격 증명 체인(Lambda 실행 역할) 사용
+type staticCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+func credentialsFromForm(event FileCompressionForm) staticCredentials {
+	return staticCredentials{
+		AccessKeyId:     event.AccessKeyId,
+		SecretAccessKey: event.SecretAccessKey,
+		SessionToken:    event.SessionToken,
+	}
+}
+
+func (c staticCredentials) isSet() bool {
+	return c.AccessKeyId != ""
+}
+
+// 클라이언트 캐시 키에 사용할 값 (비밀 값은 해시로만 포함하여 같은 키 ID의 다른 세션과 구분)
+func (c staticCredentials) cacheKey() string {
+	sum := sha256.Sum256([]byte(c.AccessKeyId + "\x00" + c.SecretAccessKey + "\x00" + c.SessionToken))
+	return c.AccessKeyId + ":" + hex.EncodeToString(sum[:8])
+}
+
+// 설정된 경우 기본 자격 증명 대신 정적 자격 증명 사용
+func (c staticCredentials) apply(cfg *aws.Config) {
+	if c.isSet() {
+		cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(c.AccessKeyId, c.SecretAccessKey, c.SessionToken))
+	}
+}
+
+// 자격 증명 필드 조합 검사 (키 ID와 비밀 키는 함께 지정)
+func validateCredentials(event FileCompressionForm) error {
+	if (event.AccessKeyId == "") != (event.SecretAccessKey == "") {
+		return fmt.Errorf("accessKeyId and secretAccessKey must be set together")
+	}
+	if event.SessionToken != "" && event.AccessKeyId == "" {
+		return fmt.Errorf("sessionToken requires accessKeyId and secretAccessKey")
+	}
+	return nil
+}
+
+// 요청이 로그에 기록되더라도 자격 증명과 암호가 남지 않도록 가림
+func (f FileCompressionForm) LogValue() slog.Value {
+	type plainForm FileCompressionForm // LogValue 재귀 호출 방지
+	redacted := plainForm(f)
+	for _, field := range []*string{&redacted.AccessKeyId, &redacted.SecretAccessKey, &redacted.SessionToken, &redacted.Password, &redacted.SSECustomerKey} {
+		if *field != "" {
+			*field = "***"
+		}
+	}
+	return slog.AnyValue(redacted)
+}
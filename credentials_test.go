@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var testStaticCredentials = staticCredentials{AccessKeyId: "AKIATESTKEY", SecretAccessKey: "test-secret", SessionToken: "test-token"}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"none", FileCompressionForm{}, false},
+		{"key and secret", FileCompressionForm{AccessKeyId: "AKIATESTKEY", SecretAccessKey: "test-secret"}, false},
+		{"with session token", FileCompressionForm{AccessKeyId: "AKIATESTKEY", SecretAccessKey: "test-secret", SessionToken: "test-token"}, false},
+		{"key only", FileCompressionForm{AccessKeyId: "AKIATESTKEY"}, true},
+		{"secret only", FileCompressionForm{SecretAccessKey: "test-secret"}, true},
+		{"session token only", FileCompressionForm{SessionToken: "test-token"}, true},
+	}
+	for _, tt := range tests {
+		if err := validateCredentials(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateCredentials = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// 같은 키 ID라도 비밀 키/세션 토큰이 다르면 다른 캐시 키, 비밀 값은 캐시 키에 그대로 나타나지 않음
+func TestStaticCredentialsCacheKey(t *testing.T) {
+	key := testStaticCredentials.cacheKey()
+	if key != testStaticCredentials.cacheKey() {
+		t.Error("cacheKey is not stable")
+	}
+	otherSecret, otherToken := testStaticCredentials, testStaticCredentials
+	otherSecret.SecretAccessKey = "other-secret"
+	otherToken.SessionToken = "other-token"
+	if key == otherSecret.cacheKey() || key == otherToken.cacheKey() {
+		t.Error("cacheKey does not distinguish secrets")
+	}
+	if strings.Contains(key, "test-secret") || strings.Contains(key, "test-token") {
+		t.Errorf("cacheKey %q contains a secret", key)
+	}
+}
+
+// 정적 자격 증명이 있으면 그 자격 증명으로 만든 별도 클라이언트를 사용하고, 없으면 실행 역할 클라이언트 사용
+func TestS3ClientStaticCredentials(t *testing.T) {
+	const region = "ap-northeast-2"
+	roleClient, err := getS3ClientWithRole(region, "", staticCredentials{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staticClient, err := getS3ClientWithRole(region, "", testStaticCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staticClient == roleClient {
+		t.Fatal("static credentials share the role client")
+	}
+	if again, _ := getS3ClientWithRole(region, "", testStaticCredentials); again != staticClient {
+		t.Error("static credential client is not cached")
+	}
+	other := testStaticCredentials
+	other.SessionToken = "other-token"
+	if otherClient, _ := getS3ClientWithRole(region, "", other); otherClient == staticClient {
+		t.Error("different session token shares a cached client")
+	}
+
+	creds, err := staticClient.Options().Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if creds.AccessKeyID != testStaticCredentials.AccessKeyId || creds.SecretAccessKey != testStaticCredentials.SecretAccessKey || creds.SessionToken != testStaticCredentials.SessionToken {
+		t.Errorf("client credentials = %s, want the request credentials", creds.AccessKeyID)
+	}
+
+	sqsClient, err := getSQSClient(region, testStaticCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds, err := sqsClient.Options().Credentials.Retrieve(context.Background()); err != nil || creds.AccessKeyID != testStaticCredentials.AccessKeyId {
+		t.Errorf("SQS client credentials = %s, %v", creds.AccessKeyID, err)
+	}
+}
+
+// 요청의 자격 증명이 원본/대상/결과 큐 클라이언트 생성에 전달되는지 확인
+func TestHandlerPassesStaticCredentials(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+	var mu sync.Mutex
+	var s3Creds, sqsCreds []staticCredentials
+	s3ClientFor = func(_, _ string, creds staticCredentials) (S3API, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		s3Creds = append(s3Creds, creds)
+		return s3c, nil
+	}
+	sqsClientFor = func(_ string, creds staticCredentials) (SQSAPI, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sqsCreds = append(sqsCreds, creds)
+		return sqsc, nil
+	}
+	event := testEvent()
+	event.AccessKeyId = testStaticCredentials.AccessKeyId
+	event.SecretAccessKey = testStaticCredentials.SecretAccessKey
+	event.SessionToken = testStaticCredentials.SessionToken
+
+	if _, err := Handler(context.Background(), event); err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	if len(s3Creds) == 0 || len(sqsCreds) == 0 {
+		t.Fatalf("clients created: s3 %d, sqs %d", len(s3Creds), len(sqsCreds))
+	}
+	for _, creds := range append(s3Creds, sqsCreds...) {
+		if creds != testStaticCredentials {
+			t.Errorf("client created with %+v, want request credentials", creds.AccessKeyId)
+		}
+	}
+}
+
+func TestFormLogValueRedactsSecrets(t *testing.T) {
+	event := testEvent()
+	event.AccessKeyId = testStaticCredentials.AccessKeyId
+	event.SecretAccessKey = testStaticCredentials.SecretAccessKey
+	event.SessionToken = testStaticCredentials.SessionToken
+	event.Password = "archive-password"
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("Request", "event", event)
+	out := buf.String()
+	for _, secret := range []string{"AKIATESTKEY", "test-secret", "test-token", "archive-password"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `"accessKeyId":"***"`) || !strings.Contains(out, "origin-bucket") {
+		t.Errorf("log = %s", out)
+	}
+	// 원래 요청 값은 바뀌지 않아야 함
+	if event.SecretAccessKey != testStaticCredentials.SecretAccessKey {
+		t.Error("LogValue modified the request")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Lambda 이벤트 형태 감지용 (SQS/S3 트리거 이벤트는 Records[].eventSource 로 구분)
+type eventEnvelope struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+}
+
+// Lambda 엔트리 포인트: 이벤트 형태를 감지하여 알맞은 핸들러로 전달
+// - SQS 트리거 이벤트: 각 레코드 본문을 FileCompressionForm으로 처리하고 부분 배치 실패 응답 반환
+// - S3 트리거 이벤트: 업로드된 객체마다 기본 옵션으로 압축하고 결과 목록 반환
+// - 그 외: FileCompressionForm 단건 요청으로 처리
+func EventHandler(ctx context.Context, payload json.RawMessage) (any, error) {
+	var envelope eventEnvelope
+	if err := json.Unmarshal(payload, &envelope); err == nil && len(envelope.Records) > 0 {
+		switch envelope.Records[0].EventSource {
+		case "aws:sqs":
+			var event events.SQSEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return nil, fmt.Errorf("failed to decode SQS event: %w", err)
+			}
+			return SQSHandler(ctx, event)
+		case "aws:s3":
+			var event events.S3Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return nil, fmt.Errorf("failed to decode S3 event: %w", err)
+			}
+			return S3Handler(ctx, event)
+		}
+	}
+
+	form, err := decodeForm(payload)
+	if err != nil {
+		err = newCompressionError(ErrCodeValidation, err)
+		return buildErrorResult(form, err), err
+	}
+	return Handler(ctx, form)
+}
+
+// 요청 JSON을 FileCompressionForm으로 디코딩
+// 알 수 없는 필드(오타 등)가 있으면 무시하지 않고 해당 필드명을 담은 에러 반환
+func decodeForm(data []byte) (FileCompressionForm, error) {
+	var form FileCompressionForm
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&form); err != nil {
+		return form, fmt.Errorf("failed to decode request: %w", err)
+	}
+	return form, nil
+}
+
+// SQS 트리거 핸들러: 레코드별로 처리하고 실패한 레코드만 BatchItemFailures로 반환
+// (이벤트 소스 매핑에 ReportBatchItemFailures 설정 필요)
+// 레코드가 여러 개이면 결과 메시지를 모아 SendMessageBatch로 전송하고, 결과를 보내지 못한 레코드도 실패로 반환
+func SQSHandler(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var response events.SQSEventResponse
+	var batch *resultBatch
+	if len(event.Records) > 1 {
+		batch = &resultBatch{}
+		ctx = withResultBatch(ctx, batch)
+	}
+	for _, record := range event.Records {
+		if batch != nil {
+			batch.setRecord(record.MessageId)
+		}
+		form, err := decodeForm([]byte(record.Body))
+		if err != nil {
+			loggerFrom(ctx).Error("Failed to decode SQS record", "stage", "sqs-batch", "messageId", record.MessageId, errorAttr(err))
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+			continue
+		}
+
+		result, err := Handler(ctx, form)
+		if err != nil {
+			loggerFrom(ctx).Error("SQS record failed", "stage", "sqs-batch", "messageId", record.MessageId, "processUuid", result.ProcessUuid, "errorCode", result.ErrorCode, errorAttr(err))
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+			continue
+		}
+		loggerFrom(ctx).Info("SQS record processed", "stage", "sqs-batch", "messageId", record.MessageId, "processUuid", result.ProcessUuid, "result", result.Result, "bucket", result.Bucket, "key", result.Key)
+	}
+	if batch != nil {
+		for _, messageId := range batch.flush(ctx) {
+			if !slices.ContainsFunc(response.BatchItemFailures, func(f events.SQSBatchItemFailure) bool { return f.ItemIdentifier == messageId }) {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: messageId})
+			}
+		}
+	}
+	loggerFrom(ctx).Info("SQS batch processed", "stage", "sqs-batch", "records", len(event.Records), "failures", len(response.BatchItemFailures))
+	return response, nil
+}
+
+// S3 트리거(ObjectCreated) 핸들러: 레코드의 버킷/키/리전으로 요청을 만들어 처리하고 레코드별 결과 반환
+// 압축 결과를 같은 버킷에 올리면 다시 이벤트가 발생하므로 이미 압축된 키는 건너뜀
+// 실패한 레코드는 결과(FAILED)와 실패 큐로 전달하고, 성공한 레코드가 재처리되지 않도록 에러는 반환하지 않음
+// 레코드가 여러 개이면 결과 메시지를 모아 SendMessageBatch로 전송
+func S3Handler(ctx context.Context, event events.S3Event) ([]CompressionResultData, error) {
+	results := make([]CompressionResultData, 0, len(event.Records))
+	var batch *resultBatch
+	if len(event.Records) > 1 {
+		batch = &resultBatch{}
+		ctx = withResultBatch(ctx, batch)
+	}
+	for _, record := range event.Records {
+		key := record.S3.Object.URLDecodedKey
+		if batch != nil {
+			batch.setRecord(key)
+		}
+		if !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			loggerFrom(ctx).Info("Skipping non-create S3 event", "stage", "s3-event", "eventName", record.EventName, "key", key)
+			results = append(results, skippedS3Record(record, SkipReasonUnsupportedEvent, "unsupported event: "+record.EventName))
+			continue
+		}
+		if strings.HasSuffix(key, ResumeMarkerSuffix) {
+			loggerFrom(ctx).Info("Skipping resume marker object", "stage", "s3-event", "bucket", record.S3.Bucket.Name, "key", key)
+			results = append(results, skippedS3Record(record, SkipReasonUnsupportedEvent, "resume marker object: "+key))
+			continue
+		}
+		if isAlreadyCompressed(key, DefaultFormat) {
+			loggerFrom(ctx).Info("Skipping already compressed object", "stage", "s3-event", "bucket", record.S3.Bucket.Name, "key", key)
+			results = append(results, skippedS3Record(record, SkipReasonAlreadyCompressed, "file is already compressed: "+key))
+			continue
+		}
+
+		result, err := Handler(ctx, formFromS3Record(record))
+		if err != nil {
+			loggerFrom(ctx).Error("S3 record failed", "stage", "s3-event", "processUuid", result.ProcessUuid, "errorCode", result.ErrorCode, errorAttr(err))
+		}
+		results = append(results, result)
+	}
+	if batch != nil {
+		if failed := batch.flush(ctx); len(failed) > 0 {
+			loggerFrom(ctx).Error("Failed to send results for S3 records", "stage", "s3-event", "keys", failed)
+		}
+	}
+	return results, nil
+}
+
+// 처리하지 않은 S3 이벤트 레코드의 결과 (결과 큐로는 보내지 않음)
+func skippedS3Record(record events.S3EventRecord, reason, message string) CompressionResultData {
+	return CompressionResultData{
+		Result:  ResultSkipped,
+		Reason:  reason,
+		Message: message,
+		Region:  record.AWSRegion,
+		Bucket:  record.S3.Bucket.Name,
+		Key:     record.S3.Object.URLDecodedKey,
+	}
+}
+
+// S3 이벤트 레코드로부터 요청 생성 (대상 버킷/키는 기본값, 결과 큐는 Lambda 리전의 S3_EVENT_QUEUE_URL 환경 변수)
+// 같은 업로드에 대한 중복 전달이 같은 요청으로 처리되도록 S3 요청 ID를 ProcessUuid로 사용
+func formFromS3Record(record events.S3EventRecord) FileCompressionForm {
+	return FileCompressionForm{
+		ProcessUuid:     defaultIfEmpty(record.ResponseElements["x-amz-request-id"], record.S3.Object.Sequencer),
+		OriginRegion:    record.AWSRegion,
+		OriginBucket:    record.S3.Bucket.Name,
+		OriginKey:       record.S3.Object.URLDecodedKey,
+		OriginVersionId: record.S3.Object.VersionID,
+		QueueRegion:     getLambdaRegion(),
+		QueueUrl:        os.Getenv("S3_EVENT_QUEUE_URL"),
+	}
+}
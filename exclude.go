@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 접두사/다중 키 모드에서 제외할 키의 glob 패턴 (대소문자 구분)
+// - '/'가 없는 패턴(예: *.tmp)은 파일명에만 적용
+// - '/'가 있는 패턴(예: logs/**/*.gz)은 키 전체에 적용 (접두사 모드에서는 접두사 이후 경로)
+// - *: '/'를 제외한 임의 문자열, ?: '/'를 제외한 한 글자, **: '/'를 포함한 임의 문자열 ("**/"는 0개 이상의 디렉토리)
+type excludePattern struct {
+	matchBase bool
+	re        *regexp.Regexp
+}
+
+func compileExcludePatterns(patterns []string) ([]excludePattern, error) {
+	compiled := make([]excludePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("exclude pattern must not be empty")
+		}
+		re, err := regexp.Compile(globToRegexp(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, excludePattern{matchBase: !strings.Contains(pattern, "/"), re: re})
+	}
+	return compiled, nil
+}
+
+// glob 패턴을 전체 일치 정규식으로 변환
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// 키(접두사 모드에서는 접두사를 뗀 경로)가 패턴 중 하나와 일치하는지 확인
+func isExcluded(key, prefix string, patterns []excludePattern) bool {
+	rel := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+	for _, p := range patterns {
+		target := rel
+		if p.matchBase {
+			target = path.Base(rel)
+		}
+		if p.re.MatchString(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// 패턴과 일치하지 않는 키만 남기고 제외된 개수 반환
+func filterExcludedKeys(keys []string, prefix string, patterns []excludePattern) ([]string, int) {
+	if len(patterns) == 0 {
+		return keys, 0
+	}
+	kept := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !isExcluded(key, prefix, patterns) {
+			kept = append(kept, key)
+		}
+	}
+	return kept, len(keys) - len(kept)
+}
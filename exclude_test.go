@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsExcluded(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		prefix  string
+		want    bool
+	}{
+		// '/'가 없는 패턴은 파일명에만 적용
+		{"*.tmp", "data/2024/file.tmp", "", true},
+		{"*.tmp", "data/file.tmp.gz", "", false},
+		{"file?.log", "a/file1.log", "", true},
+		{"file?.log", "a/file10.log", "", false},
+		// 대소문자 구분
+		{"*.tmp", "data/FILE.TMP", "", false},
+		{"*.TMP", "data/FILE.TMP", "", true},
+		// '/'가 있는 패턴은 전체 경로(접두사 이후)에 적용, *는 '/'를 넘지 않음
+		{"logs/*.gz", "logs/a.gz", "", true},
+		{"logs/*.gz", "logs/2024/a.gz", "", false},
+		{"logs/**/*.gz", "logs/a.gz", "", true},
+		{"logs/**/*.gz", "logs/2024/05/a.gz", "", true},
+		{"logs/**/*.gz", "other/logs/a.gz", "", false},
+		{"**/*.7z", "a.7z", "", true},
+		{"**/*.7z", "x/y/z.7z", "", true},
+		{"cache/**", "cache/a/b", "", true},
+		{"cache/**", "cache", "", false},
+		// 접두사 모드에서는 접두사를 뗀 경로 기준
+		{"tmp/*", "exports/tmp/a.csv", "exports/", true},
+		{"tmp/*", "exports/tmp/a.csv", "", false},
+		// 정규식 메타 문자는 그대로 일치
+		{"a+b.(1).txt", "dir/a+b.(1).txt", "", true},
+		{"a+b.(1).txt", "dir/aab.(1).txt", "", false},
+	}
+	for _, tt := range tests {
+		patterns, err := compileExcludePatterns([]string{tt.pattern})
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		if got := isExcluded(tt.key, tt.prefix, patterns); got != tt.want {
+			t.Errorf("isExcluded(%q, prefix %q, %q) = %v, want %v", tt.key, tt.prefix, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFilterExcludedKeys(t *testing.T) {
+	patterns, err := compileExcludePatterns([]string{"*.tmp", "**/_SUCCESS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, excluded := filterExcludedKeys([]string{"p/a.csv", "p/b.tmp", "p/x/_SUCCESS", "p/c.csv"}, "p/", patterns)
+	if !slices.Equal(kept, []string{"p/a.csv", "p/c.csv"}) || excluded != 2 {
+		t.Errorf("filterExcludedKeys = %v, %d, want [p/a.csv p/c.csv], 2", kept, excluded)
+	}
+}
+
+func TestCompileExcludePatternsRejectsEmpty(t *testing.T) {
+	if _, err := compileExcludePatterns([]string{"*.tmp", ""}); err == nil {
+		t.Error("empty pattern accepted")
+	}
+}
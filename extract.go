@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// 압축 해제 전 검사: 절대 경로나 ".." 경로 요소가 있는 항목은 추출 디렉토리 밖을 가리킬 수 있으므로 거부
+// (7za e는 경로 없이 파일명만 사용하지만, 신뢰할 수 없는 아카이브이므로 항목 목록 단계에서 먼저 걸러냄)
+func checkArchiveEntryPaths(entries []string) error {
+	for _, entry := range entries {
+		path := filepath.ToSlash(entry)
+		if strings.HasPrefix(path, "/") || filepath.VolumeName(entry) != "" {
+			return fmt.Errorf("archive entry has an absolute path: %s", entry)
+		}
+		for _, elem := range strings.Split(path, "/") {
+			if elem == ".." {
+				return fmt.Errorf("archive entry escapes the extraction directory: %s", entry)
+			}
+		}
+	}
+	return nil
+}
+
+// 압축 해제 후 검사: 추출 디렉토리 아래에는 일반 파일과 디렉토리만 있어야 함
+// 심볼릭 링크 항목은 업로드 시 디렉토리 밖의 파일(/etc/passwd 등)을 읽게 되므로 거부
+func checkExtractedFiles(destDir string) error {
+	return filepath.WalkDir(destDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(destDir, path)
+		if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("extracted file escapes the extraction directory: %s", path)
+		}
+		switch mode := d.Type(); {
+		case mode&fs.ModeSymlink != 0:
+			return fmt.Errorf("archive entry is a symbolic link: %s", rel)
+		case !mode.IsRegular() && !mode.IsDir():
+			return fmt.Errorf("archive entry is not a regular file: %s", rel)
+		}
+		return nil
+	})
+}
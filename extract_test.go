@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestCheckArchiveEntryPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		wantErr bool
+	}{
+		{"plain files", []string{"app.log", "logs/2024/app.log"}, false},
+		{"dots in names", []string{"app..log", "..hidden", "logs/.../app.log"}, false},
+		{"parent directory", []string{"../x"}, true},
+		{"nested parent directory", []string{"logs/../../x"}, true},
+		{"parent after valid entry", []string{"app.log", "logs/.."}, true},
+		{"absolute path", []string{"/etc/passwd"}, true},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkArchiveEntryPaths(tt.entries); (err != nil) != tt.wantErr {
+				t.Errorf("checkArchiveEntryPaths(%q) = %v, wantErr %v", tt.entries, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckExtractedFiles(t *testing.T) {
+	newDir := func(t *testing.T) string {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "logs"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "logs", "app.log"), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	t.Run("regular files", func(t *testing.T) {
+		if err := checkExtractedFiles(newDir(t)); err != nil {
+			t.Errorf("checkExtractedFiles = %v", err)
+		}
+	})
+	t.Run("symlink outside", func(t *testing.T) {
+		dir := newDir(t)
+		if err := os.Symlink("../../x", filepath.Join(dir, "logs", "x")); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkExtractedFiles(dir); err == nil {
+			t.Error("checkExtractedFiles accepted a symbolic link")
+		}
+	})
+	t.Run("fifo", func(t *testing.T) {
+		dir := newDir(t)
+		if err := syscall.Mkfifo(filepath.Join(dir, "pipe"), 0o644); err != nil {
+			t.Skipf("mkfifo: %v", err)
+		}
+		if err := checkExtractedFiles(dir); err == nil {
+			t.Error("checkExtractedFiles accepted a named pipe")
+		}
+	})
+}
+
+// ../x 항목이 있는 아카이브는 압축을 풀기 전에 VALIDATION_FAILED로 거부하고 7za e를 실행하지 않음
+func TestDecompressRejectsParentEntry(t *testing.T) {
+	ranFile := filepath.Join(t.TempDir(), "extracted")
+	useSevenZip(t, writeStub(t, `if [ "$1" = "l" ]; then
+cat <<'LISTING'
+----------
+Path = ../x
+Size = 4
+Attributes = A_ -rw-r--r--
+LISTING
+exit 0
+fi
+touch `+ranFile+`
+`))
+	_, err := extractFile(context.Background(), filepath.Join(t.TempDir(), "evil.7z"), t.TempDir(), sevenZipOptions{})
+	if errorCode(err) != ErrCodeValidation {
+		t.Fatalf("decompress error = %v, want %s", err, ErrCodeValidation)
+	}
+	if _, err := os.Stat(ranFile); err == nil {
+		t.Error("7za e ran for an archive with an unsafe entry")
+	}
+}
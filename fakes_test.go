@@ -0,0 +1,543 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// 메모리에 객체를 보관하는 가짜 S3 (S3API 구현)
+// errs에 작업 이름(PutObject 등)별 에러를 넣으면 해당 작업이 그 에러로 실패
+type fakeS3 struct {
+	mu         sync.Mutex
+	objects    map[string]*fakeObject // "bucket/key"
+	uploads    map[string]*fakeUpload // uploadId
+	nextUpload int
+	objectLock map[string]bool // 객체 잠금이 활성화된 버킷
+	errs       map[string]error
+
+	puts    []*s3.PutObjectInput // Body는 제외하고 기록
+	gets    []*s3.GetObjectInput
+	deletes []*s3.DeleteObjectInput
+	copies  []*s3.CopyObjectInput
+	creates []*s3.CreateMultipartUploadInput
+	parts   []*s3.UploadPartInput // Body는 제외하고 기록
+	aborts  []*s3.AbortMultipartUploadInput
+}
+
+type fakeObject struct {
+	data         []byte
+	contentType  string
+	metadata     map[string]string
+	tagging      string
+	lastModified time.Time
+	checksum     string // SHA256 체크섬 (base64, 멀티파트면 복합 체크섬)
+}
+
+type fakeUpload struct {
+	bucket    string
+	key       string
+	input     *s3.CreateMultipartUploadInput
+	initiated time.Time
+	parts     map[int32][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects:    map[string]*fakeObject{},
+		uploads:    map[string]*fakeUpload{},
+		objectLock: map[string]bool{},
+		errs:       map[string]error{},
+	}
+}
+
+// 테스트 준비용: 객체 저장
+func (f *fakeS3) putObject(bucket, key string, data []byte, contentType string) *fakeObject {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj := &fakeObject{data: data, contentType: contentType, lastModified: time.Now()}
+	f.objects[bucket+"/"+key] = obj
+	return obj
+}
+
+// 테스트 확인용: 저장된 객체 (없으면 nil)
+func (f *fakeS3) object(bucket, key string) *fakeObject {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[bucket+"/"+key]
+}
+
+func (f *fakeS3) fail(op string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errs[op]
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if err := f.fail("PutObject"); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	recorded := *in
+	recorded.Body = nil
+	f.puts = append(f.puts, &recorded)
+	obj := &fakeObject{
+		data:         data,
+		contentType:  aws.ToString(in.ContentType),
+		metadata:     in.Metadata,
+		tagging:      aws.ToString(in.Tagging),
+		lastModified: time.Now(),
+	}
+	if in.ChecksumAlgorithm == s3types.ChecksumAlgorithmSha256 {
+		sum := sha256.Sum256(data)
+		obj.checksum = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)] = obj
+	return &s3.PutObjectOutput{ETag: aws.String(etag(data))}, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if err := f.fail("GetObject"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets = append(f.gets, in)
+	obj, ok := f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	data := obj.data
+	if r := aws.ToString(in.Range); r != "" {
+		var start, end int64
+		if _, err := fmt.Sscanf(r, "bytes=%d-%d", &start, &end); err != nil {
+			return nil, fmt.Errorf("fake: unsupported range %q", r)
+		}
+		data = data[start:min(end+1, int64(len(data)))]
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(obj.contentType),
+		Metadata:      obj.metadata,
+		LastModified:  aws.Time(obj.lastModified),
+		ETag:          aws.String(etag(obj.data)),
+	}, nil
+}
+
+func (f *fakeS3) HeadObject(ctx context.Context, in *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if err := f.fail("HeadObject"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)]
+	if !ok {
+		return nil, &s3types.NotFound{}
+	}
+	out := &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(obj.data))),
+		ContentType:   aws.String(obj.contentType),
+		Metadata:      obj.metadata,
+		LastModified:  aws.Time(obj.lastModified),
+		ETag:          aws.String(etag(obj.data)),
+	}
+	if obj.checksum != "" {
+		out.ChecksumSHA256 = aws.String(obj.checksum)
+	}
+	return out, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if err := f.fail("DeleteObject"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletes = append(f.deletes, in)
+	delete(f.objects, aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) CopyObject(ctx context.Context, in *s3.CopyObjectInput, _ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	if err := f.fail("CopyObject"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.copies = append(f.copies, in)
+	source, _, _ := strings.Cut(aws.ToString(in.CopySource), "?")
+	if unescaped, err := url.PathUnescape(source); err == nil {
+		source = unescaped
+	}
+	obj, ok := f.objects[source]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	copied := *obj
+	if in.MetadataDirective == s3types.MetadataDirectiveReplace {
+		copied.contentType = aws.ToString(in.ContentType)
+		copied.metadata = in.Metadata
+	}
+	copied.tagging = aws.ToString(in.Tagging)
+	copied.lastModified = time.Now()
+	f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)] = &copied
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if err := f.fail("ListObjectsV2"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bucketPrefix := aws.ToString(in.Bucket) + "/"
+	var keys []string
+	for name := range f.objects {
+		if key, ok := strings.CutPrefix(name, bucketPrefix); ok && strings.HasPrefix(key, aws.ToString(in.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(false)}
+	for _, key := range keys {
+		obj := f.objects[bucketPrefix+key]
+		out.Contents = append(out.Contents, s3types.Object{
+			Key:          aws.String(key),
+			Size:         aws.Int64(int64(len(obj.data))),
+			LastModified: aws.Time(obj.lastModified),
+		})
+	}
+	out.KeyCount = aws.Int32(int32(len(out.Contents)))
+	return out, nil
+}
+
+func (f *fakeS3) CreateMultipartUpload(ctx context.Context, in *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	if err := f.fail("CreateMultipartUpload"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.creates = append(f.creates, in)
+	f.nextUpload++
+	id := "upload-" + strconv.Itoa(f.nextUpload)
+	f.uploads[id] = &fakeUpload{
+		bucket:    aws.ToString(in.Bucket),
+		key:       aws.ToString(in.Key),
+		input:     in,
+		initiated: time.Now(),
+		parts:     map[int32][]byte{},
+	}
+	return &s3.CreateMultipartUploadOutput{Bucket: in.Bucket, Key: in.Key, UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPart(ctx context.Context, in *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if err := f.fail("UploadPart"); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	recorded := *in
+	recorded.Body = nil
+	f.parts = append(f.parts, &recorded)
+	upload, ok := f.uploads[aws.ToString(in.UploadId)]
+	if !ok {
+		return nil, &s3types.NoSuchUpload{}
+	}
+	upload.parts[aws.ToInt32(in.PartNumber)] = data
+	out := &s3.UploadPartOutput{ETag: aws.String(etag(data))}
+	if upload.input.ChecksumAlgorithm == s3types.ChecksumAlgorithmSha256 {
+		sum := sha256.Sum256(data)
+		out.ChecksumSHA256 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	return out, nil
+}
+
+func (f *fakeS3) CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if err := f.fail("CompleteMultipartUpload"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[aws.ToString(in.UploadId)]
+	if !ok {
+		return nil, &s3types.NoSuchUpload{}
+	}
+	var data []byte
+	composite := sha256.New()
+	for _, part := range in.MultipartUpload.Parts {
+		body, ok := upload.parts[aws.ToInt32(part.PartNumber)]
+		if !ok || aws.ToString(part.ETag) != etag(body) {
+			return nil, &s3types.NoSuchUpload{}
+		}
+		data = append(data, body...)
+		sum := sha256.Sum256(body)
+		composite.Write(sum[:])
+	}
+	obj := &fakeObject{
+		data:         data,
+		contentType:  aws.ToString(upload.input.ContentType),
+		metadata:     upload.input.Metadata,
+		tagging:      aws.ToString(upload.input.Tagging),
+		lastModified: time.Now(),
+	}
+	if upload.input.ChecksumAlgorithm == s3types.ChecksumAlgorithmSha256 {
+		obj.checksum = base64.StdEncoding.EncodeToString(composite.Sum(nil)) + "-" + strconv.Itoa(len(in.MultipartUpload.Parts))
+	}
+	f.objects[upload.bucket+"/"+upload.key] = obj
+	delete(f.uploads, aws.ToString(in.UploadId))
+	return &s3.CompleteMultipartUploadOutput{Bucket: in.Bucket, Key: in.Key}, nil
+}
+
+func (f *fakeS3) AbortMultipartUpload(ctx context.Context, in *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborts = append(f.aborts, in)
+	delete(f.uploads, aws.ToString(in.UploadId))
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) ListMultipartUploads(ctx context.Context, in *s3.ListMultipartUploadsInput, _ ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error) {
+	if err := f.fail("ListMultipartUploads"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := &s3.ListMultipartUploadsOutput{IsTruncated: aws.Bool(false)}
+	for id, upload := range f.uploads {
+		if upload.bucket == aws.ToString(in.Bucket) && strings.HasPrefix(upload.key, aws.ToString(in.Prefix)) {
+			out.Uploads = append(out.Uploads, s3types.MultipartUpload{
+				Key:       aws.String(upload.key),
+				UploadId:  aws.String(id),
+				Initiated: aws.Time(upload.initiated),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeS3) ListParts(ctx context.Context, in *s3.ListPartsInput, _ ...func(*s3.Options)) (*s3.ListPartsOutput, error) {
+	if err := f.fail("ListParts"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[aws.ToString(in.UploadId)]
+	if !ok {
+		return nil, &s3types.NoSuchUpload{}
+	}
+	out := &s3.ListPartsOutput{IsTruncated: aws.Bool(false)}
+	for number, body := range upload.parts {
+		out.Parts = append(out.Parts, s3types.Part{
+			PartNumber: aws.Int32(number),
+			ETag:       aws.String(etag(body)),
+			Size:       aws.Int64(int64(len(body))),
+		})
+	}
+	slices.SortFunc(out.Parts, func(a, b s3types.Part) int { return int(aws.ToInt32(a.PartNumber) - aws.ToInt32(b.PartNumber)) })
+	return out, nil
+}
+
+func (f *fakeS3) GetObjectLockConfiguration(ctx context.Context, in *s3.GetObjectLockConfigurationInput, _ ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	if err := f.fail("GetObjectLockConfiguration"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.objectLock[aws.ToString(in.Bucket)] {
+		return nil, fakeResponseError(http.StatusNotFound, "ObjectLockConfigurationNotFoundError: Object Lock configuration does not exist for this bucket")
+	}
+	return &s3.GetObjectLockConfigurationOutput{
+		ObjectLockConfiguration: &s3types.ObjectLockConfiguration{ObjectLockEnabled: s3types.ObjectLockEnabledEnabled},
+	}, nil
+}
+
+// S3 ETag (따옴표로 감싼 MD5 hex)
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// SDK가 반환하는 것과 같은 형태의 HTTP 상태 코드 에러
+func fakeResponseError(status int, message string) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status, Header: http.Header{}}},
+			Err:      errors.New(message),
+		},
+	}
+}
+
+// 보낸 메시지를 기록하는 가짜 SQS (SQSAPI 구현)
+// failBatchEntry가 true를 반환한 배치 항목은 Failed로 응답 (batchSenderFault면 재시도하지 않는 전송자 오류)
+type fakeSQS struct {
+	mu               sync.Mutex
+	err              error
+	failBatchEntry   func(entry sqstypes.SendMessageBatchRequestEntry) bool
+	batchSenderFault bool
+
+	sent    []*sqs.SendMessageInput
+	batches []*sqs.SendMessageBatchInput
+}
+
+func (f *fakeSQS) SendMessage(ctx context.Context, in *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, in)
+	return &sqs.SendMessageOutput{MessageId: aws.String(strconv.Itoa(len(f.sent)))}, nil
+}
+
+func (f *fakeSQS) SendMessageBatch(ctx context.Context, in *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.batches = append(f.batches, in)
+	out := &sqs.SendMessageBatchOutput{}
+	for _, entry := range in.Entries {
+		if f.failBatchEntry != nil && f.failBatchEntry(entry) {
+			out.Failed = append(out.Failed, sqstypes.BatchResultErrorEntry{
+				Id:          entry.Id,
+				Code:        aws.String("InternalError"),
+				Message:     aws.String("fake batch entry failure"),
+				SenderFault: f.batchSenderFault,
+			})
+			continue
+		}
+		f.sent = append(f.sent, &sqs.SendMessageInput{QueueUrl: in.QueueUrl, MessageBody: entry.MessageBody})
+		out.Successful = append(out.Successful, sqstypes.SendMessageBatchResultEntry{Id: entry.Id, MessageId: entry.Id})
+	}
+	return out, nil
+}
+
+// 전송된(배치 포함) raw 형식 결과 메시지
+func (f *fakeSQS) results(t *testing.T) []CompressionResultData {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	results := make([]CompressionResultData, 0, len(f.sent))
+	for _, in := range f.sent {
+		var result CompressionResultData
+		if err := json.Unmarshal([]byte(aws.ToString(in.MessageBody)), &result); err != nil {
+			t.Fatalf("decode SQS message: %v", err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// 테스트 동안 요청 처리가 가짜 S3/SQS 클라이언트와 테스트 전용 임시 디렉토리를 사용하도록 교체
+// 이전 테스트의 실패가 영향을 주지 않도록 큐 회로 차단기도 새로 만듦
+func useFakeClients(t *testing.T, s3Client S3API, sqsClient SQSAPI) {
+	t.Helper()
+	savedS3, savedSQS, savedTemp, savedBreaker := s3ClientFor, sqsClientFor, tempDir, queueBreaker
+	s3ClientFor = func(string, string, staticCredentials) (S3API, error) { return s3Client, nil }
+	sqsClientFor = func(string, staticCredentials) (SQSAPI, error) { return sqsClient, nil }
+	tempDir = t.TempDir()
+	queueBreaker = newCircuitBreaker(DefaultQueueBreakerThreshold, DefaultQueueBreakerCooldown*time.Second)
+	t.Cleanup(func() {
+		s3ClientFor, sqsClientFor, tempDir, queueBreaker = savedS3, savedSQS, savedTemp, savedBreaker
+	})
+}
+
+// 처리 기록을 메모리에 보관하는 가짜 DynamoDB (IdempotencyAPI 구현)
+// PutItem은 acquireIdempotencyLock의 조건(기록 없음, FAILED, 오래된 IN_PROGRESS)을 흉내 냄
+type fakeIdempotencyStore struct {
+	mu    sync.Mutex
+	items map[string]map[string]ddbtypes.AttributeValue // processUuid
+}
+
+func (f *fakeIdempotencyStore) PutItem(ctx context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := attributeString(in.Item, "processUuid")
+	if existing, ok := f.items[key]; ok {
+		status := attributeString(existing, "status")
+		startedAt, _ := existing["startedAt"].(*ddbtypes.AttributeValueMemberN)
+		staleBefore, _ := in.ExpressionAttributeValues[":staleBefore"].(*ddbtypes.AttributeValueMemberN)
+		stale := status == IdempotencyStatusInProgress && startedAt != nil && staleBefore != nil && startedAt.Value < staleBefore.Value
+		if status != IdempotencyStatusFailed && !stale {
+			return nil, &ddbtypes.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+		}
+	}
+	f.items[key] = maps.Clone(in.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeIdempotencyStore) GetItem(ctx context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: maps.Clone(f.items[attributeString(in.Key, "processUuid")])}, nil
+}
+
+func (f *fakeIdempotencyStore) UpdateItem(ctx context.Context, in *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := attributeString(in.Key, "processUuid")
+	item := f.items[key]
+	if item == nil {
+		item = maps.Clone(in.Key)
+		f.items[key] = item
+	}
+	// recordIdempotencyResult의 "SET #status = :status, #result = :result"만 지원
+	for name, attr := range in.ExpressionAttributeNames {
+		item[attr] = in.ExpressionAttributeValues[":"+strings.TrimPrefix(name, "#")]
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// 테스트 확인용: 기록된 처리 상태 (없으면 "")
+func (f *fakeIdempotencyStore) status(processUuid string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return attributeString(f.items[processUuid], "status")
+}
+
+// 테스트 동안 중복 실행 방지를 가짜 저장소로 활성화
+func useFakeIdempotency(t *testing.T) *fakeIdempotencyStore {
+	t.Helper()
+	store := &fakeIdempotencyStore{items: map[string]map[string]ddbtypes.AttributeValue{}}
+	savedTable, savedClient := idempotencyTable, idempotencyClient
+	idempotencyTable, idempotencyClient = "test-idempotency", store
+	t.Cleanup(func() { idempotencyTable, idempotencyClient = savedTable, savedClient })
+	return store
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ProcessUuid 기반 중복 실행 방지 (IDEMPOTENCY_TABLE 환경 변수가 있을 때만 동작)
+// 테이블 파티션 키: processUuid (String), TTL 속성: expiresAt
+const (
+	IdempotencyStatusInProgress = "IN_PROGRESS"
+	IdempotencyStatusCompleted  = "COMPLETED"
+	IdempotencyStatusFailed     = "FAILED"
+	IdempotencyStatusUnsent     = "UNSENT" // 처리는 끝났지만 배치 결과 메시지를 보내지 못함 (재전달 시 저장된 결과를 다시 전송)
+
+	IdempotencyLockTimeout = 15 * time.Minute   // Lambda 최대 실행 시간, 이보다 오래된 IN_PROGRESS는 중단된 실행으로 간주
+	IdempotencyRecordTTL   = 7 * 24 * time.Hour // 완료 기록 보관 기간
+)
+
+const ErrCodeInProgress = "IN_PROGRESS"
+
+// 중복 실행 방지 기록에 사용하는 DynamoDB 작업 (테스트 시 가짜 구현으로 대체 가능)
+type IdempotencyAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+var (
+	idempotencyTable  string
+	idempotencyClient IdempotencyAPI
+)
+
+func initIdempotency() {
+	idempotencyTable = os.Getenv("IDEMPOTENCY_TABLE")
+	if idempotencyTable == "" {
+		return
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(getLambdaRegion()))
+	if err != nil {
+		fatal("Failed to load DynamoDB config", "stage", "init", errorAttr(err))
+	}
+	idempotencyClient = dynamodb.NewFromConfig(cfg)
+	slog.Info("Idempotency guard enabled", "stage", "init", "table", idempotencyTable)
+}
+
+// 같은 ProcessUuid의 요청이 이미 완료되었으면 이전 결과를 반환하고, 아니면 요청을 처리한 뒤 결과를 기록
+func withIdempotency(ctx context.Context, event FileCompressionForm, process func() (CompressionResultData, error)) (CompressionResultData, error) {
+	if idempotencyTable == "" || event.ProcessUuid == "" || event.DryRun {
+		return process()
+	}
+
+	acquired, err := acquireIdempotencyLock(ctx, event.ProcessUuid)
+	if err != nil {
+		// 기록 저장소 장애로 처리를 막지는 않음
+		loggerFrom(ctx).Warn("Idempotency check failed, processing anyway", "stage", "idempotency", errorAttr(err))
+		return process()
+	}
+	if !acquired {
+		return previousResult(ctx, event)
+	}
+
+	result, err := process()
+	if err != nil {
+		// 실패한 요청은 재전달 시 다시 처리될 수 있도록 FAILED로 기록
+		recordIdempotencyStatus(ctx, event.ProcessUuid, IdempotencyStatusFailed, result)
+		return result, err
+	}
+	recordCompletedAfterSend(ctx, event, result)
+	return result, nil
+}
+
+// 성공 결과를 COMPLETED로 기록
+// 여러 레코드를 처리 중이면 결과 메시지는 아직 전송 전이므로 배치 전송이 끝난 뒤 기록하고,
+// 전송에 실패하면 UNSENT로 기록하여 재전달 시 (대상이 이미 있어 다시 처리할 수 없으므로) 저장된 결과를 다시 전송
+func recordCompletedAfterSend(ctx context.Context, event FileCompressionForm, result CompressionResultData) {
+	batch := resultBatchFrom(ctx)
+	if batch == nil {
+		recordIdempotencyStatus(ctx, event.ProcessUuid, IdempotencyStatusCompleted, result)
+		return
+	}
+	batch.afterFlush(func(sent bool) {
+		status := IdempotencyStatusCompleted
+		if !sent {
+			status = IdempotencyStatusUnsent
+		}
+		recordIdempotencyStatus(ctx, event.ProcessUuid, status, result)
+	})
+}
+
+// 기록 실패는 처리 결과에 영향을 주지 않으므로 경고만 남김
+func recordIdempotencyStatus(ctx context.Context, processUuid, status string, result CompressionResultData) {
+	if err := recordIdempotencyResult(ctx, processUuid, status, result); err != nil {
+		loggerFrom(ctx).Warn("Failed to record idempotency result", "stage", "idempotency", "status", status, errorAttr(err))
+	}
+}
+
+// 조건부 PutItem으로 처리 권한 획득
+// 기록이 없거나, 이전 실행이 실패했거나, IN_PROGRESS 상태가 제한 시간을 넘긴 경우에만 성공하므로 동시 실행 시 하나만 획득
+func acquireIdempotencyLock(ctx context.Context, processUuid string) (bool, error) {
+	now := time.Now()
+	_, err := idempotencyClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(idempotencyTable),
+		Item: map[string]ddbtypes.AttributeValue{
+			"processUuid": &ddbtypes.AttributeValueMemberS{Value: processUuid},
+			"status":      &ddbtypes.AttributeValueMemberS{Value: IdempotencyStatusInProgress},
+			"startedAt":   &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			"expiresAt":   &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(IdempotencyRecordTTL).Unix(), 10)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(processUuid) OR #status = :failed OR (#status = :inProgress AND startedAt < :staleBefore)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":failed":      &ddbtypes.AttributeValueMemberS{Value: IdempotencyStatusFailed},
+			":inProgress":  &ddbtypes.AttributeValueMemberS{Value: IdempotencyStatusInProgress},
+			":staleBefore": &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(-IdempotencyLockTimeout).Unix(), 10)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to put idempotency record: %w", err)
+	}
+	return true, nil
+}
+
+// 이미 처리된(또는 처리 중인) 요청의 결과 반환
+func previousResult(ctx context.Context, event FileCompressionForm) (CompressionResultData, error) {
+	out, err := idempotencyClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(idempotencyTable),
+		Key:            map[string]ddbtypes.AttributeValue{"processUuid": &ddbtypes.AttributeValueMemberS{Value: event.ProcessUuid}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get idempotency record: %w", err)
+		return buildErrorResult(event, err), err
+	}
+
+	status := attributeString(out.Item, "status")
+	if status == IdempotencyStatusCompleted || status == IdempotencyStatusUnsent {
+		var result CompressionResultData
+		if err := json.Unmarshal([]byte(attributeString(out.Item, "result")), &result); err != nil {
+			err = fmt.Errorf("failed to decode previous result: %w", err)
+			return buildErrorResult(event, err), err
+		}
+		if status == IdempotencyStatusUnsent {
+			return resendPreviousResult(ctx, event, result)
+		}
+		loggerFrom(ctx).Info("Duplicate request already completed, returning previous result", "stage", "idempotency")
+		return result, nil
+	}
+
+	// 다른 실행이 처리 중: 에러를 반환하여 SQS 트리거 등에서 나중에 재시도되도록 함 (실패 큐로는 보내지 않음)
+	err = newCompressionError(ErrCodeInProgress, fmt.Errorf("request %s is already in progress", event.ProcessUuid))
+	loggerFrom(ctx).Warn("Duplicate request is already in progress", "stage", "idempotency", errorAttr(err))
+	return buildErrorResult(event, err), err
+}
+
+// 처리는 끝났지만 결과 메시지를 보내지 못한 요청: 저장된 결과를 성공 큐로 다시 보내고 COMPLETED로 기록
+// (원본 삭제는 다시 시도하지 않으므로 deleteOriginal 요청의 원본은 남음)
+func resendPreviousResult(ctx context.Context, event FileCompressionForm, result CompressionResultData) (CompressionResultData, error) {
+	loggerFrom(ctx).Info("Duplicate request completed but its result was not sent, resending previous result", "stage", "idempotency")
+	if queueUrl := defaultIfEmpty(event.SuccessQueueUrl, event.QueueUrl); queueUrl != "" {
+		if err := sendResultToQueue(ctx, event, queueUrl, result, nil); err != nil {
+			err = newCompressionError(ErrCodeQueue, fmt.Errorf("failed to resend previous result: %w", err))
+			loggerFrom(ctx).Error("Failed to resend previous result", "stage", "idempotency", errorAttr(err))
+			return buildErrorResult(event, err), err
+		}
+	}
+	recordCompletedAfterSend(ctx, event, result)
+	return result, nil
+}
+
+func recordIdempotencyResult(ctx context.Context, processUuid, status string, result CompressionResultData) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = idempotencyClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(idempotencyTable),
+		Key:              map[string]ddbtypes.AttributeValue{"processUuid": &ddbtypes.AttributeValueMemberS{Value: processUuid}},
+		UpdateExpression: aws.String("SET #status = :status, #result = :result"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+			"#result": "result",
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":status": &ddbtypes.AttributeValueMemberS{Value: status},
+			":result": &ddbtypes.AttributeValueMemberS{Value: string(body)},
+		},
+	})
+	return err
+}
+
+func attributeString(item map[string]ddbtypes.AttributeValue, name string) string {
+	if v, ok := item[name].(*ddbtypes.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// 요청을 SQS 트리거 레코드로 변환
+func sqsRecord(t *testing.T, messageId string, event FileCompressionForm) events.SQSMessage {
+	t.Helper()
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events.SQSMessage{MessageId: messageId, Body: string(body)}
+}
+
+func idempotencyTestEvent(processUuid, key string) FileCompressionForm {
+	event := testEvent()
+	event.ProcessUuid = processUuid
+	event.OriginKey = key
+	return event
+}
+
+// 배치 전송에서 일부 결과 메시지만 실패하면 그 요청은 COMPLETED가 아닌 UNSENT로 기록하고,
+// 재전달 시 다시 압축하지 않고 저장된 결과를 다시 전송
+func TestBatchIdempotencyRecordedAfterSend(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{batchSenderFault: true}
+	s3c.putObject("origin-bucket", "logs/a.log", testContent, "text/plain")
+	s3c.putObject("origin-bucket", "logs/b.log", testContent, "text/plain")
+	sqsc.failBatchEntry = func(entry sqstypes.SendMessageBatchRequestEntry) bool {
+		return strings.Contains(*entry.MessageBody, `"processUuid":"uuid-b"`)
+	}
+	useFakeClients(t, s3c, sqsc)
+	store := useFakeIdempotency(t)
+	eventA, eventB := idempotencyTestEvent("uuid-a", "logs/a.log"), idempotencyTestEvent("uuid-b", "logs/b.log")
+
+	resp, err := SQSHandler(context.Background(), events.SQSEvent{Records: []events.SQSMessage{
+		sqsRecord(t, "msg-a", eventA),
+		sqsRecord(t, "msg-b", eventB),
+	}})
+	if err != nil {
+		t.Fatalf("SQSHandler: %v", err)
+	}
+	if len(resp.BatchItemFailures) != 1 || resp.BatchItemFailures[0].ItemIdentifier != "msg-b" {
+		t.Fatalf("BatchItemFailures = %+v, want only msg-b", resp.BatchItemFailures)
+	}
+	if got := store.status("uuid-a"); got != IdempotencyStatusCompleted {
+		t.Errorf("uuid-a status = %q, want %s", got, IdempotencyStatusCompleted)
+	}
+	if got := store.status("uuid-b"); got != IdempotencyStatusUnsent {
+		t.Errorf("uuid-b status = %q, want %s (result was never sent)", got, IdempotencyStatusUnsent)
+	}
+
+	// 재전달된 msg-b는 저장된 결과를 다시 전송
+	sqsc.failBatchEntry = nil
+	resp, err = SQSHandler(context.Background(), events.SQSEvent{Records: []events.SQSMessage{sqsRecord(t, "msg-b", eventB)}})
+	if err != nil || len(resp.BatchItemFailures) != 0 {
+		t.Fatalf("redelivered SQSHandler = %+v, %v", resp, err)
+	}
+	if got := store.status("uuid-b"); got != IdempotencyStatusCompleted {
+		t.Errorf("uuid-b status after redelivery = %q, want %s", got, IdempotencyStatusCompleted)
+	}
+	var sent []string
+	for _, result := range sqsc.results(t) {
+		sent = append(sent, result.ProcessUuid)
+	}
+	if strings.Join(sent, ",") != "uuid-a,uuid-b" {
+		t.Errorf("results sent for %v, want uuid-a then uuid-b", sent)
+	}
+	if len(s3c.puts) != 2 {
+		t.Errorf("PutObject called %d times, want 2 (redelivery must not compress again)", len(s3c.puts))
+	}
+}
+
+// 같은 요청이 이미 완료되었으면 처리하지 않고 이전 결과 반환
+func TestIdempotencyReplaysCompletedResult(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/a.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+	useFakeIdempotency(t)
+	event := idempotencyTestEvent("uuid-a", "logs/a.log")
+
+	first, err := Handler(context.Background(), event)
+	if err != nil {
+		t.Fatalf("first Handler: %v", err)
+	}
+	second, err := Handler(context.Background(), event)
+	if err != nil {
+		t.Fatalf("second Handler: %v", err)
+	}
+	if second.Key != first.Key || second.Result != first.Result {
+		t.Errorf("replayed result = %+v, want %+v", second, first)
+	}
+	if len(s3c.puts) != 1 || len(sqsc.sent) != 1 {
+		t.Errorf("duplicate request uploaded %d times and sent %d messages, want 1 each", len(s3c.puts), len(sqsc.sent))
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// individual 모드 요청 검사: 원본 키마다 대상 키를 따로 만들므로 TargetKey는 사용할 수 없고,
+// 생성된 대상 키가 서로 겹치지 않아야 함 (예: {dir} 없는 템플릿에서 다른 디렉토리의 같은 파일명)
+func validateIndividualKeys(event FileCompressionForm) error {
+	if event.TargetKey != "" {
+		return fmt.Errorf("targetKey is not supported in %s mode (use targetKeyTemplate)", ModeIndividual)
+	}
+	operation := defaultIfEmpty(event.Operation, OperationCompress)
+	format := compressionFormats[defaultIfEmpty(event.Format, DefaultFormat)]
+	now := time.Now()
+	targets := make(map[string]string, len(event.OriginKeys))
+	for _, key := range event.OriginKeys {
+		if key == "" {
+			return fmt.Errorf("originKeys must not contain empty keys")
+		}
+		target := resolveTargetKey(individualEvent(event, key), operation, format, now)
+		if prev, ok := targets[target]; ok {
+			return fmt.Errorf("originKeys %s and %s resolve to the same target key %s", prev, key, target)
+		}
+		targets[target] = key
+	}
+	return nil
+}
+
+// 원본 키 하나를 처리하는 단일 키 요청 생성
+// 결과 큐 전송과 원본 삭제는 전체 결과를 보낸 뒤 processIndividual에서 한 번에 수행
+func individualEvent(event FileCompressionForm, key string) FileCompressionForm {
+	sub := event
+	sub.Mode = ""
+	sub.OriginKey = key
+	sub.OriginKeys = nil
+	sub.ExcludePatterns = nil
+	sub.DeleteOriginal = false
+	sub.QueueUrl = ""
+	sub.SuccessQueueUrl = ""
+	sub.FailureQueueUrl = ""
+	sub.Passthrough = nil
+	return sub
+}
+
+// 원본 키마다 압축하여 각자의 대상 키로 업로드하고 파일별 결과를 Items로 모음
+// 한 파일의 실패가 나머지 처리를 막지 않으며, 모두 실패한 경우에만 FAILED와 에러 반환 (일부 실패는 PARTIAL)
+func processIndividual(ctx context.Context, event FileCompressionForm, startTime time.Time) (CompressionResultData, error) {
+	// validateRequest에서 이미 검사했으므로 에러는 발생하지 않음
+	exclude, _ := compileExcludePatterns(event.ExcludePatterns)
+	keys, excluded := filterExcludedKeys(event.OriginKeys, "", exclude)
+	if len(keys) == 0 {
+		err := newCompressionError(ErrCodeValidation, fmt.Errorf("all %d origin keys were excluded", excluded))
+		loggerFrom(ctx).Error("Invalid request", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	}
+	if excluded > 0 {
+		loggerFrom(ctx).Info("Excluded origin keys", "stage", "validate", "objects", len(keys), "excluded", excluded)
+	}
+
+	items := make([]CompressionResultData, 0, len(keys))
+	var firstErr error
+	failed := 0
+	for _, key := range keys {
+		item, err := processRequest(withLogger(ctx, loggerFrom(ctx).With("originKey", key)), individualEvent(event, key))
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		items = append(items, item)
+	}
+	loggerFrom(ctx).Info("Individual compression finished", "stage", "complete", "files", len(items), "failed", failed, durationAttr(time.Since(startTime)))
+
+	originRegion := defaultIfEmpty(event.OriginRegion, getLambdaRegion())
+	result := CompressionResultData{
+		Result:      ResultSucceed,
+		Message:     fmt.Sprintf("%d of %d files processed", len(items)-failed, len(items)),
+		ProcessUuid: event.ProcessUuid,
+		Region:      defaultIfEmpty(event.TargetRegion, originRegion),
+		Bucket:      defaultIfEmpty(event.TargetBucket, event.OriginBucket),
+		Passthrough: event.Passthrough,
+		TotalMs:     time.Since(startTime).Milliseconds(),
+		Items:       items,
+	}
+	queueUrl := defaultIfEmpty(event.SuccessQueueUrl, event.QueueUrl)
+	switch failed {
+	case 0:
+	case len(items):
+		result.Result = ResultFailed
+		result.ErrorCode = errorCode(firstErr)
+		queueUrl = defaultIfEmpty(event.FailureQueueUrl, event.QueueUrl)
+	default:
+		result.Result = ResultPartial
+	}
+
+	// 결과 전송에 실패하면 원본은 삭제하지 않음 (배치 전송이면 전송된 뒤 삭제)
+	deleteOriginals := func() {
+		if event.DeleteOriginal && !event.DryRun {
+			deleteSucceededOriginals(ctx, event, keys, items)
+		}
+	}
+	if queueUrl != "" && !event.DryRun {
+		if err := sendResultToQueue(ctx, event, queueUrl, result, deleteOriginals); err != nil {
+			if !errors.Is(err, errCircuitOpen) {
+				loggerFrom(ctx).Error("Failed to send SQS message", "stage", "queue", errorAttr(err))
+			}
+			if result.Result != ResultFailed {
+				return result, newCompressionError(ErrCodeQueue, err)
+			}
+		}
+	} else {
+		deleteOriginals()
+	}
+	if result.Result == ResultFailed {
+		return result, newCompressionError(result.ErrorCode, fmt.Errorf("all %d files failed: %w", len(items), firstErr))
+	}
+	return result, nil
+}
+
+// 압축에 성공한 파일의 원본만 삭제 (실패해도 경고 로그만 남김)
+func deleteSucceededOriginals(ctx context.Context, event FileCompressionForm, keys []string, items []CompressionResultData) {
+	client, err := s3ClientFor(defaultIfEmpty(event.OriginRegion, getLambdaRegion()), event.OriginRoleArn, credentialsFromForm(event))
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to create S3 client for deleting originals", "stage", "delete", errorAttr(err))
+		return
+	}
+	maxRetries := event.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	for i, key := range keys {
+		if items[i].Result != ResultSucceed {
+			continue
+		}
+		if items[i].Bucket == event.OriginBucket && items[i].Key == key {
+			loggerFrom(ctx).Warn("Origin and target are the same object, not deleting", "stage", "delete", "key", key)
+			continue
+		}
+		err := withRetry(ctx, "delete", maxRetries, func() error {
+			return deleteFromS3(ctx, client, event.OriginBucket, key, "")
+		})
+		if err != nil {
+			loggerFrom(ctx).Warn("Failed to delete original file", "stage", "delete", "key", key, errorAttr(err))
+		} else {
+			loggerFrom(ctx).Info("Original file deleted", "stage", "delete", "bucket", event.OriginBucket, "key", key)
+		}
+	}
+}
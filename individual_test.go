@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// 개별 압축 결과의 대상이 원본과 같은 객체면 삭제하지 않고, 실패한 파일의 원본도 남김
+func TestDeleteSucceededOriginals(t *testing.T) {
+	s3c := newFakeS3()
+	for _, key := range []string{"logs/a.log", "logs/b.log", "logs/c.log"} {
+		s3c.putObject("origin-bucket", key, testContent, "text/plain")
+	}
+	useFakeClients(t, s3c, &fakeSQS{})
+	event := FileCompressionForm{OriginBucket: "origin-bucket", DeleteOriginal: true}
+	keys := []string{"logs/a.log", "logs/b.log", "logs/c.log"}
+	items := []CompressionResultData{
+		{Result: ResultSucceed, Bucket: "target-bucket", Key: "logs/a.gz"},
+		{Result: ResultSucceed, Bucket: "origin-bucket", Key: "logs/b.log"},
+		{Result: ResultFailed, Bucket: "origin-bucket", Key: "logs/c.log"},
+	}
+
+	deleteSucceededOriginals(context.Background(), event, keys, items)
+	if s3c.object("origin-bucket", "logs/a.log") != nil {
+		t.Error("succeeded original was not deleted")
+	}
+	if s3c.object("origin-bucket", "logs/b.log") == nil {
+		t.Error("original deleted although it is the target object")
+	}
+	if s3c.object("origin-bucket", "logs/c.log") == nil {
+		t.Error("failed original was deleted")
+	}
+	if len(s3c.deletes) != 1 {
+		t.Errorf("deletes = %d, want 1", len(s3c.deletes))
+	}
+}
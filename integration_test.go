@@ -0,0 +1,131 @@
+//go:build integration
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/localstack"
+)
+
+// LocalStack으로 Handler 전체 흐름 확인 (Docker 필요)
+// 실행: go test -tags integration -run TestIntegrationLocalStack ./...
+const localstackImage = "localstack/localstack:3.8"
+
+// LocalStack 기본 계정 ID로 만든 큐 URL (queueUrlPattern 형식을 따르고, 실제 요청은 SQS_ENDPOINT_URL로 전송됨)
+const integrationQueueUrl = "https://sqs.us-east-1.amazonaws.com/000000000000/compress-results"
+
+// LocalStack 컨테이너를 띄우고 S3/SQS 엔드포인트를 컨테이너로 바꿈 (테스트가 끝나면 되돌리고 컨테이너 종료)
+func startLocalStack(t *testing.T) {
+	t.Helper()
+	// Docker가 없으면 실패 대신 건너뜀
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+	container, err := localstack.Run(ctx, localstackImage)
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start LocalStack: %v", err)
+	}
+	endpoint, err := container.PortEndpoint(ctx, "4566/tcp", "http")
+	if err != nil {
+		t.Fatalf("failed to get LocalStack endpoint: %v", err)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	savedS3, savedPathStyle, savedSQS, savedTemp := s3EndpointURL, s3UsePathStyle, sqsEndpointURL, tempDir
+	s3EndpointURL, s3UsePathStyle, sqsEndpointURL, tempDir = endpoint, true, endpoint, t.TempDir()
+	t.Cleanup(func() {
+		s3EndpointURL, s3UsePathStyle, sqsEndpointURL, tempDir = savedS3, savedPathStyle, savedSQS, savedTemp
+	})
+}
+
+func TestIntegrationLocalStack(t *testing.T) {
+	startLocalStack(t)
+	ctx := context.Background()
+
+	// 엔드포인트 설정이 적용된 클라이언트로 버킷과 큐 준비
+	s3c, err := createS3Client("us-east-1")
+	if err != nil {
+		t.Fatalf("createS3Client: %v", err)
+	}
+	sqsc, err := createSQSClient("us-east-1", staticCredentials{})
+	if err != nil {
+		t.Fatalf("createSQSClient: %v", err)
+	}
+	for _, bucket := range []string{"origin-bucket", "target-bucket"} {
+		if _, err := s3c.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+			t.Fatalf("CreateBucket %s: %v", bucket, err)
+		}
+	}
+	if _, err := sqsc.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String("compress-results")}); err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+	content := bytes.Repeat([]byte("integration test log line\n"), 1000)
+	if _, err := s3c.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String("origin-bucket"),
+		Key:    aws.String("logs/app.log"),
+		Body:   bytes.NewReader(content),
+	}); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+
+	result, err := Handler(ctx, FileCompressionForm{
+		ProcessUuid:  "integration-uuid",
+		OriginRegion: "us-east-1",
+		OriginBucket: "origin-bucket",
+		OriginKey:    "logs/app.log",
+		TargetRegion: "us-east-1",
+		TargetBucket: "target-bucket",
+		Format:       "gzip",
+		QueueUrl:     integrationQueueUrl,
+	})
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	if result.Result != ResultSucceed {
+		t.Fatalf("result = %s, want %s", result.Result, ResultSucceed)
+	}
+
+	// 압축 결과 객체 확인
+	out, err := s3c.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("target-bucket"), Key: aws.String("logs/app.gz")})
+	if err != nil {
+		t.Fatalf("compressed object not found: %v", err)
+	}
+	compressed, err := io.ReadAll(out.Body)
+	out.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to read compressed object: %v", err)
+	}
+	if got := gunzip(t, compressed); !bytes.Equal(got, content) {
+		t.Error("compressed object does not decompress to the original content")
+	}
+
+	// 결과 메시지 확인
+	received, err := sqsc.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(integrationQueueUrl),
+		MaxNumberOfMessages: 10,
+		WaitTimeSeconds:     5,
+	})
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if len(received.Messages) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received.Messages))
+	}
+	var message CompressionResultData
+	if err := json.Unmarshal([]byte(aws.ToString(received.Messages[0].Body)), &message); err != nil {
+		t.Fatalf("invalid result message: %v", err)
+	}
+	if message.Result != ResultSucceed || message.Bucket != "target-bucket" || message.Key != "logs/app.gz" {
+		t.Errorf("result message = %+v", message)
+	}
+}
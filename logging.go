@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// 구조화(JSON) 로깅 - CloudWatch Logs Insights에서 필드 단위로 조회할 수 있도록 JSON으로 출력
+// 공통 필드: processUuid, stage, durationMs, bytes, error
+// LOG_LEVEL 환경 변수로 레벨 설정 (DEBUG, INFO, WARN, ERROR / 기본값 INFO)
+func initLogger() {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: parseLogLevel(os.Getenv("LOG_LEVEL"))})
+	slog.SetDefault(slog.New(handler))
+}
+
+func parseLogLevel(value string) slog.Level {
+	var level slog.Level
+	if value == "" || level.UnmarshalText([]byte(value)) != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+type loggerKey struct{}
+
+// 요청 단위 로거(processUuid 포함)를 context에 저장
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// context에 저장된 요청 단위 로거 (없으면 기본 로거)
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func durationAttr(d time.Duration) slog.Attr {
+	return slog.Int64("durationMs", d.Milliseconds())
+}
+
+func bytesAttr(n int64) slog.Attr {
+	return slog.Int64("bytes", n)
+}
+
+func errorAttr(err error) slog.Attr {
+	return slog.String("error", err.Error())
+}
+
+// 초기화 단계의 복구 불가능한 오류 기록 후 종료
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -1,277 +1,2933 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // Compression Option - 7z 무압축(Copy) 모드
-// 7z은 컨테이너에 미리 설치되어 있어야 하며, /var/task/7za 경로에 위치해야함
+// 7z은 컨테이너에 미리 설치되어 있어야 하며, /var/task/7za 경로에 위치해야함 (SEVENZIP_PATH 환경 변수로 변경 가능)
 const (
-	SevenZipCmd        = "/var/task/7za" // 7z 바이너리 경로
-	SevenZipFormatFlag = "-t7z"          // 압축 포맷
-	SevenZipCopyFlag   = "-m0=Copy"      // 무압축 옵션
-	TempDir            = "/tmp"
-	CompressExtension  = ".7z"
-	BufferSize         = 4 * 1024 * 1024
+	SevenZipCmd         = "/var/task/7za" // 7z 바이너리 경로
+	SevenZipFormatFlag  = "-t7z"          // 압축 포맷
+	SevenZipCopyFlag    = "-m0=Copy"      // 무압축 옵션
+	SevenZipLevelFlag   = "-mx=%d"        // 압축 레벨 옵션 (1~9)
+	SevenZipDictFlag    = "-md="          // 사전 크기 옵션 (예: 64m)
+	SevenZipThreadsFlag = "-mmt=%d"       // 스레드 수 옵션
+	SevenZipPassFlag    = "-p"            // 암호 옵션 (-p<password>)
+	SevenZipHeaderFlag  = "-mhe=on"       // 헤더(파일 목록) 암호화 옵션
+	SevenZipVolumeFlag  = "-v"            // 분할 압축 볼륨 크기 옵션 (예: -v1g)
+	SevenZipSolidFlag   = "-ms="          // 솔리드 압축 옵션 (on/off 또는 솔리드 블록 크기, 예: 64m)
+	SevenZipProgressOut = "-bsp1"         // 진행률을 표준 출력으로 출력 (임시 파일 모드 전용)
+	MaxCompressionLevel = 9
+	TempDir             = "/tmp"
+	CompressExtension   = ".7z"
+	DefaultFormat       = "7z"
+	BufferSize          = 4 * 1024 * 1024 // 다운로드/해시/gzip 복사 버퍼 기본 크기
+
+	DefaultMultipartThreshold  = 100 * 1024 * 1024 // 이 크기를 넘는 파일은 멀티파트 업로드
+	DefaultMaxRetries          = 3                 // S3 작업 재시도 횟수
+	RetryBaseDelay             = 200 * time.Millisecond
+	RetryMaxDelay              = 5 * time.Second
+	DefaultMetricsNamespace    = "FileCompress"
+	DefaultMaxInputBytes       = 10 * 1024 * 1024 * 1024 // /tmp 최대 크기(10GB) 기준 입력 크기 제한
+	DefaultMessageFormat       = MessageFormatRaw        // 결과 메시지 기본 형식
+	DefaultMessageGroupId      = "file-compress"         // FIFO 큐에서 그룹 ID와 ProcessUuid가 모두 없을 때 사용
+	DefaultProgressIntervalMB  = 100                     // 진행률 로그 출력 간격 (MB, 0이면 비활성화)
+	DefaultMaxCachedClients    = 10                      // 종류별 최대 캐시 클라이언트 수
+	DefaultMaxPrefixObjects    = 1000                    // 접두사(OriginPrefix) 압축 시 최대 객체 수
+	DefaultDownloadConcurrency = 4                       // 여러 원본 키 동시 다운로드 수
+	DefaultBytesPerSecond      = 50 * 1024 * 1024        // 실행 시간 예산 계산용 처리 속도 추정값 (다운로드+압축+업로드)
+	DefaultSevenZipRetries     = 2                       // 7za 일시적 실패 시 재실행 횟수
+	DefaultTimeBudgetHeadroom  = 10                      // 예상 처리 시간 외에 남겨둘 여유 시간 (초)
+	DefaultStaleTempMinutes    = 30                      // 이 시간(분)보다 오래된 작업 디렉토리는 이전 실행이 남긴 것으로 보고 삭제 (Lambda 최대 실행 시간 15분)
+
+	OperationCompress   = "compress"   // 원본을 압축하여 업로드 (기본값)
+	OperationDecompress = "decompress" // .7z 아카이브를 풀어서 원본 파일을 업로드
+
+	MessageFormatRaw = "raw" // 결과 JSON을 그대로 전송
+	MessageFormatSNS = "sns" // SNS 알림 형식으로 감싸서 전송
+
+	DispositionAttachment = "attachment" // contentDisposition에 지정하면 대상 파일명으로 헤더 자동 생성
+
+	ModeArchive    = "archive"    // 여러 원본 키를 하나의 아카이브로 압축 (기본값)
+	ModeIndividual = "individual" // 원본 키마다 별도로 압축하여 각자의 대상 키로 업로드
+)
+
+// 압축 포맷별 7za 타입 옵션과 확장자
+type compressionFormat struct {
+	TypeFlag       string // 비어있으면 7za로 압축할 수 없는 포맷 (Native 구현 사용)
+	Extension      string
+	ContentType    string     // 업로드 객체의 기본 ContentType
+	SupportsCopy   bool       // -m0=Copy 지원 여부 (gzip은 Copy 메서드 미지원)
+	SupportsStream bool       // 스트리밍 압축 지원 여부: 7za 표준출력(-so) 또는 Go 구현 (7z, zip은 seek 가능한 출력 파일 필요)
+	MultiFile      bool       // 여러 파일을 하나의 아카이브로 묶을 수 있는지 여부 (gzip은 단일 파일만 지원)
+	SupportsAppend bool       // 압축 결과를 이어 붙여도 유효한 포맷인지 여부 (gzip 멀티 멤버, zstd 멀티 프레임)
+	Native         Compressor // Go로 구현된 압축 (TypeFlag가 없거나 7za 바이너리가 없을 때 사용, nil이면 없음)
+}
+
+var compressionFormats = map[string]compressionFormat{
+	"7z":   {TypeFlag: SevenZipFormatFlag, Extension: CompressExtension, ContentType: "application/x-7z-compressed", SupportsCopy: true, MultiFile: true},
+	"zip":  {TypeFlag: "-tzip", Extension: ".zip", ContentType: "application/zip", SupportsCopy: true, MultiFile: true},
+	"gzip": {TypeFlag: "-tgzip", Extension: ".gz", ContentType: "application/gzip", SupportsCopy: false, SupportsStream: true, SupportsAppend: true, Native: GzipCompressor{}},
+	"zstd": {Extension: ".zst", ContentType: "application/zstd", SupportsStream: true, SupportsAppend: true, Native: ZstdCompressor{}}, // 7za 미지원, Go 구현만 사용
+}
+
+// static client map
+// 프로비저닝된 동시성 등 오래 유지되는 컨테이너에서 리전이 늘어나도 메모리가 계속 늘지 않도록 LRU로 개수 제한
+var (
+	s3Clients  = newClientCache[*s3.Client](DefaultMaxCachedClients)  // 리전(+AssumeRole ARN)별 S3 클라이언트 캐시
+	sqsClients = newClientCache[*sqs.Client](DefaultMaxCachedClients) // 리전별 SQS 클라이언트 캐시
+	clientsMu  sync.Mutex                                             // 클라이언트 캐시 동시 접근 보호 (조회 시에도 사용 순서가 바뀌므로 단일 잠금, 생성 중에는 잡지 않음)
+)
+
+// 이 패키지에서 사용하는 S3 작업 (테스트 시 가짜 구현으로 대체 가능)
+// 멀티파트 업로드(manager.Uploader)에 필요한 작업은 manager.UploadAPIClient로 포함
+type S3API interface {
+	manager.UploadAPIClient
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error)
+	ListParts(ctx context.Context, params *s3.ListPartsInput, optFns ...func(*s3.Options)) (*s3.ListPartsOutput, error)
+	GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
+}
+
+// 이 패키지에서 사용하는 SQS 작업
+type SQSAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+}
+
+// 요청 처리에 사용할 S3/SQS 클라이언트 조회 (테스트에서 가짜 구현으로 교체)
+var (
+	s3ClientFor = func(region, roleArn string, creds staticCredentials) (S3API, error) {
+		return getS3ClientWithRole(region, roleArn, creds)
+	}
+	sqsClientFor = func(region string, creds staticCredentials) (SQSAPI, error) {
+		return getSQSClient(region, creds)
+	}
+)
+
+// 환경 변수로 변경 가능한 설정값
+var (
+	sevenZipCmd                    = SevenZipCmd
+	tempDir                        = TempDir
+	tempFileStrategy               = TempFileStrategyPath
+	multipartThreshold       int64 = DefaultMultipartThreshold
+	progressInterval         int64 = DefaultProgressIntervalMB * 1024 * 1024
+	copyBufferSize                 = BufferSize
+	defaultMaxRetries              = DefaultMaxRetries
+	sevenZipRetries                = DefaultSevenZipRetries
+	defaultMaxInputBytes           = int64(DefaultMaxInputBytes)
+	maxPrefixObjects               = DefaultMaxPrefixObjects
+	downloadConcurrency            = DefaultDownloadConcurrency
+	processingBytesPerSecond       = int64(DefaultBytesPerSecond)
+	timeBudgetHeadroom             = DefaultTimeBudgetHeadroom * time.Second
+	s3EndpointURL                  = "" // LocalStack 등 사용자 지정 S3 엔드포인트
+	s3UsePathStyle                 = false
+	s3UseAccelerate                = false
+	sqsEndpointURL                 = "" // LocalStack 등 사용자 지정 SQS 엔드포인트 (S3_ENDPOINT_URL과 함께 로컬 통합 테스트에 사용)
+	emitMetrics                    = false
+	metricsNamespace               = DefaultMetricsNamespace
+	cloudWatchClient         *cloudwatch.Client
+	queueBreaker             = newCircuitBreaker(DefaultQueueBreakerThreshold, DefaultQueueBreakerCooldown*time.Second) // 큐 URL별 결과 전송 회로 차단기
+	queueLimiter             = newRateLimiter(DefaultQueueSendRate)                                                     // 결과 큐 전송 속도 제한 (QUEUE_SEND_RATE)
+)
+
+// Lambda Request 구조체
+type FileCompressionForm struct {
+	ProcessUuid       string   `json:"processUuid"`
+	Operation         string   `json:"operation"`
+	OriginRegion      string   `json:"originRegion"`
+	OriginBucket      string   `json:"originBucket"`
+	OriginKey         string   `json:"originKey"`
+	OriginKeys        []string `json:"originKeys"`
+	Mode              string   `json:"mode"`            // originKeys 처리 방식: archive(기본값), individual
+	OriginVersionId   string   `json:"originVersionId"` // 버전 관리 버킷에서 읽고 삭제할 원본 버전 (단일 OriginKey 전용)
+	RangeStart        *int64   `json:"rangeStart"`      // 지정하면 원본의 이 바이트 범위만 다운로드하여 압축 (단일 OriginKey 전용, 양 끝 포함)
+	RangeEnd          *int64   `json:"rangeEnd"`
+	OriginPrefix      string   `json:"originPrefix"`    // 이 접두사 아래의 모든 객체를 하위 경로를 유지하여 하나의 아카이브로 압축
+	ExcludePatterns   []string `json:"excludePatterns"` // 접두사/다중 키 모드에서 제외할 키의 glob 패턴 (예: *.tmp, **/*.7z)
+	OriginUrl         string   `json:"originUrl"`       // S3 대신 이 http(s) URL에서 원본을 다운로드 (targetBucket, targetKey 필요)
+	TargetRegion      string   `json:"targetRegion"`
+	TargetBucket      string   `json:"targetBucket"`
+	TargetKey         string   `json:"targetKey"`
+	TargetKeyTemplate string   `json:"targetKeyTemplate"` // TargetKey가 없을 때 사용 (예: compressed/{yyyy}/{mm}/{dd}/{basename}{ext})
+	DeleteOriginal    bool     `json:"deleteOriginal"`
+	CompressionLevel  int      `json:"compressionLevel"`
+	AutoLevel         bool     `json:"autoLevel"` // compressionLevel 대신 입력 크기로 레벨 선택 (작으면 높게, 크면 낮게)
+	DictionarySize    string   `json:"dictionarySize"`
+	Threads           int      `json:"threads"`
+	Password          string   `json:"password"`
+	VolumeSize        string   `json:"volumeSize"`
+	Solid             string   `json:"solid"` // 7z 솔리드 압축 설정 ("on", "off" 또는 솔리드 블록 크기 예: 64m / 비어있으면 7-Zip 기본값)
+	SkipIfLarger      bool     `json:"skipIfLarger"`
+	ForceRecompress   bool     `json:"forceRecompress"`
+	CopyOnly          bool     `json:"copyOnly"` // 압축하지 않고 원본을 S3 서버 측 복사(CopyObject)로 대상에 그대로 복사
+	RejectEmpty       bool     `json:"rejectEmpty"`
+	MinAgeHours       int      `json:"minAgeHours"`      // 원본이 수정된 지 이 시간이 지나지 않았으면 압축하지 않고 SKIPPED 반환
+	TolerateWarnings  bool     `json:"tolerateWarnings"` // 7za 경고(종료 코드 1, 읽을 수 없는 입력 등)를 실패로 처리하지 않음
+	VerboseCompress   bool     `json:"verboseCompress"`  // 7za 진행률(-bsp1)과 출력을 실행 중에 한 줄씩 로그로 남김 (임시 파일 모드)
+	Format            string   `json:"format"`
+	AutoFormat        bool     `json:"autoFormat"` // format 대신 원본 ContentType으로 포맷 자동 선택 (텍스트는 gzip, 이미 압축된 형식은 SKIPPED)
+	ContentType       string   `json:"contentType"`
+	Disposition       string   `json:"contentDisposition"` // 업로드할 객체의 Content-Disposition ("attachment"면 대상 파일명으로 자동 생성, 비어있으면 설정 안 함)
+	StreamMode        bool     `json:"streamMode"`
+	MaxRetries        int      `json:"maxRetries"`
+	Overwrite         bool     `json:"overwrite"`
+	CollisionStrategy string   `json:"collisionStrategy"`    // 대상 객체가 이미 있을 때: fail(기본값), overwrite, rename(-1, -2, ... 를 붙인 키 사용)
+	Append            bool     `json:"append"`               // 대상 객체가 있으면 새 압축 결과를 뒤에 이어 붙여 다시 업로드 (gzip, zstd 전용, 없으면 새로 생성)
+	PresignExpiry     int      `json:"presignExpirySeconds"` // 0보다 크면 업로드한 객체의 GET presigned URL을 이 유효 기간(초)으로 생성하여 결과에 포함
+	SSECustomerKey    string   `json:"sseCustomerKey"`       // SSE-C로 암호화된 원본을 읽을 때 사용할 base64 인코딩된 256비트 키 (대상에는 적용 안 함)
+	SSECustomerAlg    string   `json:"sseCustomerAlgorithm"` // SSE-C 알고리즘 (AES256만 지원, 기본값 AES256)
+	SSEKmsKeyId       string   `json:"sseKmsKeyId"`
+	SSEAlgorithm      string   `json:"sseAlgorithm"`
+	Tags              TagSet   `json:"tags"`         // 업로드할 객체에 추가할 태그 (compressed=true, processUuid는 자동 추가)
+	StorageClass      string   `json:"storageClass"` // 업로드할 객체의 스토리지 클래스 (예: STANDARD_IA, GLACIER_IR / 기본값 STANDARD)
+	VerifyChecksum    bool     `json:"verifyChecksum"`
+	VerifyContents    bool     `json:"verifyContents"` // 압축 후 7za l로 아카이브 항목이 입력 파일과 일치하는지 확인 (7z, zip)
+	ResumeUpload      bool     `json:"resumeUpload"`   // 같은 ProcessUuid와 업로드 설정으로 중단된 멀티파트 업로드가 있으면 내용이 같은 파트는 재사용하여 이어서 업로드
+	Reproducible      bool     `json:"reproducible"`   // 같은 입력이면 같은 바이트의 아카이브 생성 (파일 시간을 원본 LastModified로 고정, 제한 사항은 validateReproducible 참고)
+	PreserveMetadata  bool     `json:"preserveMetadata"`
+	PreserveTimestamp bool     `json:"preserveTimestamp"` // 원본의 LastModified를 x-amz-meta-original-last-modified(RFC3339)로 저장
+	OriginHash        string   `json:"originHash"`        // sha256 또는 md5: 다운로드하면서 원본 해시를 계산하여 x-amz-meta-original-<알고리즘>(hex)으로 저장
+	DryRun            bool     `json:"dryRun"`
+	Warmup            bool     `json:"warmup"` // 예약된 워밍업 호출: 처리 없이 바로 SKIPPED(WARMUP) 반환
+	OriginRoleArn     string   `json:"originRoleArn"`
+	TargetRoleArn     string   `json:"targetRoleArn"`
+	AccessKeyId       string   `json:"accessKeyId"`     // 테스트 전용: 지정하면 기본 자격 증명 체인 대신 사용 (로그에 남기지 않음)
+	SecretAccessKey   string   `json:"secretAccessKey"` // 테스트 전용
+	SessionToken      string   `json:"sessionToken"`    // 테스트 전용
+	MaxInputBytes     int64    `json:"maxInputBytes"`
+	QueueRegion       string   `json:"queueRegion"`
+	QueueUrl          string   `json:"queueUrl"`
+	SuccessQueueUrl   string   `json:"successQueueUrl"`
+	FailureQueueUrl   string   `json:"failureQueueUrl"`
+	MessageGroupId    string   `json:"messageGroupId"`
+	MessageFormat     string   `json:"messageFormat"` // 결과 메시지 형식: raw(기본값), sns(SNS 알림 형식으로 감싸서 전송)
+	Passthrough       FieldMap `json:"passthrough"`   // 결과에 그대로 복사되는 요청자 정의 값 (작업 ID, 테넌트 ID 등, 해석하지 않음)
+
+	// 업로드할 객체의 객체 잠금(WORM) 보존 설정 (대상 버킷에 객체 잠금이 활성화되어 있어야 함)
+	ObjectLockMode        string `json:"objectLockMode"`        // GOVERNANCE 또는 COMPLIANCE
+	ObjectLockRetainUntil string `json:"objectLockRetainUntil"` // 보존 만료 시각 (RFC3339, 미래 시각)
+
+	// 요청 본문 대신 S3에 저장된 요청 JSON을 가리키는 포인터 (페이로드 크기 제한을 넘는 요청용, 다른 필드와 함께 지정할 수 없음)
+	S3PointerBucket string `json:"s3PointerBucket"`
+	S3PointerKey    string `json:"s3PointerKey"`
+}
+
+// 문자열 키 → 문자열 값
+type FieldMap map[string]string
+
+// 기본값이 적용된 실제 처리 대상 정보
+type compressionJob struct {
+	Event        FileCompressionForm
+	Operation    string
+	Format       compressionFormat
+	OriginKeys   []string // 압축 대상 원본 키 목록 (단일 키 요청이면 OriginKey 하나, 접두사 요청이면 조회된 키 목록)
+	OriginRegion string
+	TargetRegion string
+	TargetBucket string
+	TargetKey    string
+	MaxRetries   int
+	SevenZip     sevenZipOptions
+	Compressor   Compressor // 임시 파일 모드의 압축 구현 (포맷과 7za 유무에 따라 선택)
+	Upload       uploadOptions
+	OriginClient S3API
+	TargetClient S3API
+}
+
+// 7za 압축 옵션 (레벨, 사전 크기, 스레드 수)
+type sevenZipOptions struct {
+	Level          int
+	DictionarySize string // 비어있으면 -md 생략
+	Threads        int    // 0이면 -mmt 생략
+	Password       string // 비어있지 않으면 암호 + 헤더 암호화 적용 (로그에 남기지 않음)
+	VolumeSize     string // 비어있지 않으면 이 크기 단위로 분할 압축 (.7z.001, .7z.002, ...)
+	Solid          string // 비어있으면 -ms 생략 (7-Zip 기본값)
+	Reproducible   bool   // 7z에 생성/접근 시간을 저장하지 않음
+	Verbose        bool   // 7za 출력(진행률 포함)을 버퍼링하지 않고 한 줄씩 바로 로그로 남김
+
+	TolerateWarnings bool // 7za가 경고(종료 코드 1)로 끝나도 경고 로그만 남기고 성공으로 처리
+}
+
+// 포맷에 맞는 7za 압축 스위치 목록
+func (o sevenZipOptions) flags(format compressionFormat) []string {
+	var flags []string
+	if levelFlag := compressionLevelFlag(format, o.Level); levelFlag != "" {
+		flags = append(flags, levelFlag)
+	}
+	if o.DictionarySize != "" {
+		flags = append(flags, SevenZipDictFlag+o.DictionarySize)
+	}
+	if o.Threads > 0 {
+		flags = append(flags, fmt.Sprintf(SevenZipThreadsFlag, o.Threads))
+	}
+	if o.Password != "" {
+		flags = append(flags, SevenZipPassFlag+o.Password, SevenZipHeaderFlag)
+	}
+	if o.VolumeSize != "" {
+		flags = append(flags, SevenZipVolumeFlag+o.VolumeSize)
+	}
+	if o.Solid != "" {
+		flags = append(flags, SevenZipSolidFlag+o.Solid)
+	}
+	if o.Reproducible && format.TypeFlag == SevenZipFormatFlag {
+		flags = append(flags, reproducibleSevenZipFlags...)
+	}
+	return flags
+}
+
+// 로그 출력용으로 암호 인자를 가린 명령행
+func redactArgs(args []string) string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasPrefix(arg, SevenZipPassFlag) && arg != SevenZipPassFlag {
+			arg = SevenZipPassFlag + "****"
+		}
+		redacted[i] = arg
+	}
+	return strings.Join(redacted, " ")
+}
+
+// 7za 출력에 암호가 포함되어 있으면 가림
+func (o sevenZipOptions) redactOutput(out string) string {
+	if o.Password == "" {
+		return out
+	}
+	return strings.ReplaceAll(out, o.Password, "****")
+}
+
+// 요청 값으로부터 7za 옵션 생성 (스레드 수는 사용 가능한 CPU 수로 제한)
+func buildSevenZipOptions(ctx context.Context, event FileCompressionForm) sevenZipOptions {
+	threads := event.Threads
+	if cpus := runtime.NumCPU(); threads > cpus {
+		loggerFrom(ctx).Warn("Requested threads exceed available CPUs, clamping", "stage", "validate", "requested", threads, "cpus", cpus)
+		threads = cpus
+	}
+	return sevenZipOptions{
+		Level:          event.CompressionLevel,
+		DictionarySize: strings.ToLower(event.DictionarySize),
+		Threads:        threads,
+		Password:       event.Password,
+		VolumeSize:     strings.ToLower(event.VolumeSize),
+		Solid:          strings.ToLower(event.Solid),
+		Reproducible:   event.Reproducible,
+		Verbose:        event.VerboseCompress,
+
+		TolerateWarnings: event.TolerateWarnings,
+	}
+}
+
+// 업로드 시 PutObjectInput에 추가로 설정할 옵션
+type uploadOptions struct {
+	SSEAlgorithm   s3types.ServerSideEncryption
+	SSEKmsKeyId    string
+	VerifyChecksum bool   // SHA256 체크섬을 함께 업로드하고 업로드 후 저장된 값과 비교
+	ResumeUpload   bool   // 중단된 멀티파트 업로드를 이어서 사용하고, 실패해도 올라간 파트를 남김
+	ProcessUuid    string // 이어서 사용할 업로드를 구분 (ResumeUpload일 때만 사용)
+	ContentType    string
+	Metadata       map[string]string
+	StorageClass   s3types.StorageClass
+	Tagging        string // URL 인코딩된 태그 (key=value&...)
+
+	ContentTypeOverride string // 요청에서 지정한 ContentType (포맷 기본값, 원본 ContentType보다 우선)
+	ContentDisposition  string // 비어있으면 설정 안 함
+
+	ObjectLockMode        s3types.ObjectLockMode // 비어있으면 객체 잠금 보존 설정 안 함
+	ObjectLockRetainUntil time.Time
+}
+
+// 다운로드한 원본 객체 정보
+type objectInfo struct {
+	Size         int64
+	ContentType  string
+	Metadata     map[string]string
+	LastModified time.Time
+	Hash         string // 다운로드하면서 계산한 원본 해시 (hex, originHash를 지정하지 않았으면 비어있음)
+}
+
+// 원본 객체의 ContentType과 사용자 메타데이터를 이어받은 업로드 옵션 반환
+// 원본 키는 x-amz-meta-original-key 로 추가
+func (o uploadOptions) withOriginMetadata(origin objectInfo, originKey string) uploadOptions {
+	metadata := make(map[string]string, len(origin.Metadata)+1)
+	for k, v := range origin.Metadata {
+		metadata[k] = v
+	}
+	metadata["original-key"] = originKey
+	o.ContentType = origin.ContentType
+	o.Metadata = metadata
+	return o
+}
+
+// 원본의 수정 시각을 x-amz-meta-original-last-modified (RFC3339, UTC)로 추가한 업로드 옵션 반환
+// 시각을 알 수 없으면(zero) 그대로 반환
+func (o uploadOptions) withOriginTimestamp(lastModified time.Time) uploadOptions {
+	if lastModified.IsZero() {
+		return o
+	}
+	metadata := make(map[string]string, len(o.Metadata)+1)
+	for k, v := range o.Metadata {
+		metadata[k] = v
+	}
+	metadata["original-last-modified"] = lastModified.UTC().Format(time.RFC3339)
+	o.Metadata = metadata
+	return o
+}
+
+func (o uploadOptions) apply(input *s3.PutObjectInput) {
+	if o.SSEAlgorithm != "" {
+		input.ServerSideEncryption = o.SSEAlgorithm
+	}
+	if o.SSEKmsKeyId != "" {
+		input.SSEKMSKeyId = aws.String(o.SSEKmsKeyId)
+	}
+	if contentType := defaultIfEmpty(o.ContentTypeOverride, o.ContentType); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if len(o.Metadata) > 0 {
+		input.Metadata = o.Metadata
+	}
+	if o.StorageClass != "" {
+		input.StorageClass = o.StorageClass
+	}
+	if o.Tagging != "" {
+		input.Tagging = aws.String(o.Tagging)
+	}
+	if o.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(o.ContentDisposition)
+	}
+	if o.ObjectLockMode != "" {
+		input.ObjectLockMode = o.ObjectLockMode
+		input.ObjectLockRetainUntilDate = aws.Time(o.ObjectLockRetainUntil)
+	}
+}
+
+// 기본값이 적용된 실제 처리 계획을 디버그 로그로 기록 (암호, 자격 증명은 설정 여부만 기록)
+func (j compressionJob) logPlan(ctx context.Context) {
+	e := j.Event
+	loggerFrom(ctx).Debug("Resolved plan", "stage", "plan",
+		slog.Group("origin", "region", j.OriginRegion, "bucket", e.OriginBucket, "keys", j.OriginKeys, "prefix", e.OriginPrefix, "versionId", e.OriginVersionId),
+		slog.Group("target", "region", j.TargetRegion, "bucket", j.TargetBucket, "key", j.TargetKey),
+		slog.Group("queue", "region", e.QueueRegion, "success", defaultIfEmpty(e.SuccessQueueUrl, e.QueueUrl), "failure", defaultIfEmpty(e.FailureQueueUrl, e.QueueUrl), "format", defaultIfEmpty(e.MessageFormat, DefaultMessageFormat)),
+		"operation", j.Operation,
+		"format", defaultIfEmpty(e.Format, DefaultFormat),
+		"compressionLevel", j.SevenZip.Level,
+		"dictionarySize", j.SevenZip.DictionarySize,
+		"threads", j.SevenZip.Threads,
+		"volumeSize", j.SevenZip.VolumeSize,
+		"solid", defaultIfEmpty(j.SevenZip.Solid, "default"),
+		"encrypted", j.SevenZip.Password != "",
+		"staticCredentials", credentialsFromForm(e).isSet(),
+		"streamMode", e.StreamMode,
+		"deleteOriginal", e.DeleteOriginal,
+		"overwrite", e.Overwrite,
+		"skipIfLarger", e.SkipIfLarger,
+		"dryRun", e.DryRun,
+		"maxRetries", j.MaxRetries,
+		"storageClass", e.StorageClass,
+	)
+}
+
+// 원본/대상 버킷 접근용 S3 클라이언트 준비 (RoleArn이 있으면 AssumeRole 자격 증명 사용)
+func (j *compressionJob) resolveClients() error {
+	originClient, err := newOriginClient(j.OriginRegion, j.Event)
+	if err != nil {
+		return err
+	}
+	targetClient, err := s3ClientFor(j.TargetRegion, j.Event.TargetRoleArn, credentialsFromForm(j.Event))
+	if err != nil {
+		return err
+	}
+	j.OriginClient = originClient
+	j.TargetClient = targetClient
+	return nil
+}
+
+// 원본 읽기용 S3 클라이언트 (SSE-C 키가 있으면 읽기 요청에 키를 추가)
+func newOriginClient(region string, event FileCompressionForm) (S3API, error) {
+	client, err := s3ClientFor(region, event.OriginRoleArn, credentialsFromForm(event))
+	if err != nil {
+		return nil, err
+	}
+	if key, ok, _ := sseCustomerKeyFromForm(event); ok {
+		return sseCustomerClient{S3API: client, key: key}, nil
+	}
+	return client, nil
+}
+
+// 처리 결과 크기 및 단계별 소요 시간
+type compressionStats struct {
+	OriginalSize     int64
+	CompressedSize   int64
+	DownloadDuration time.Duration
+	CompressDuration time.Duration // 스트리밍 모드에서는 다운로드~업로드 전체 소요 시간
+	UploadDuration   time.Duration
+	UploadedOriginal bool     // 압축 결과가 원본보다 커서 원본을 그대로 업로드한 경우
+	UploadedKeys     []string // 분할 압축 시 업로드한 볼륨 키 목록
+	TempFreeBytes    int64    // 압축(압축 해제) 직후 임시 디렉토리 여유 공간 (조회 실패 시 0)
+}
+
+// Result Response 구조체
+type CompressionResultData struct {
+	Result      string   `json:"result"`           // SUCCEED, FAILED, SKIPPED
+	Reason      string   `json:"reason,omitempty"` // SKIPPED인 경우 건너뛴 이유 (SkipReason*)
+	Message     string   `json:"message"`
+	ProcessUuid string   `json:"processUuid"`
+	Region      string   `json:"region"`
+	Bucket      string   `json:"bucket"`
+	Key         string   `json:"key"`
+	ErrorCode   string   `json:"errorCode,omitempty"`
+	Keys        []string `json:"keys,omitempty"`        // 분할 압축 시 업로드된 볼륨 키 목록 (Key는 볼륨 공통 접두 키)
+	Passthrough FieldMap `json:"passthrough,omitempty"` // 요청의 Passthrough (성공/실패/건너뜀 결과 모두 포함)
+
+	// 처리 크기 및 단계별 소요 시간 (성공 결과에만 포함, 스트리밍 모드는 CompressMs에 전체 전송 시간 포함)
+	OriginalSize   int64 `json:"originalSize,omitempty"`
+	CompressedSize int64 `json:"compressedSize,omitempty"`
+	DownloadMs     int64 `json:"downloadMs,omitempty"`
+	CompressMs     int64 `json:"compressMs,omitempty"`
+	UploadMs       int64 `json:"uploadMs,omitempty"`
+	TotalMs        int64 `json:"totalMs,omitempty"`
+
+	// PresignExpirySeconds를 지정한 경우 대상 객체의 임시 다운로드 URL
+	PresignedUrl string `json:"presignedUrl,omitempty"`
+
+	// individual 모드에서 원본 키별 결과 (요청의 originKeys 순서)
+	Items []CompressionResultData `json:"items,omitempty"`
+}
+
+// 처리 결과
+const (
+	ResultSucceed = "SUCCEED"
+	ResultFailed  = "FAILED"
+	ResultSkipped = "SKIPPED" // 의도적으로 아무것도 하지 않음 (실패가 아님)
+	ResultPartial = "PARTIAL" // individual 모드에서 일부 파일만 실패
+)
+
+// SKIPPED 결과의 이유 코드
+const (
+	SkipReasonAlreadyCompressed = "ALREADY_COMPRESSED"
+	SkipReasonTooYoung          = "TOO_YOUNG"
+	SkipReasonDryRun            = "DRY_RUN"
+	SkipReasonWarmup            = "WARMUP"
+	SkipReasonUnsupportedEvent  = "UNSUPPORTED_EVENT"
+)
+
+// 실패 유형 코드 (결과의 ErrorCode로 전달되어 소비자가 문자열 매칭 없이 분기 가능)
+const (
+	ErrCodeValidation     = "VALIDATION_FAILED"
+	ErrCodeTargetExists   = "TARGET_EXISTS"
+	ErrCodeOriginNotFound = "ORIGIN_NOT_FOUND"
+	ErrCodeAccessDenied   = "ACCESS_DENIED"
+	ErrCodeWrongRegion    = "WRONG_REGION"
+	ErrCodeEmptyOrigin    = "EMPTY_ORIGIN"
+	ErrCodeDownload       = "DOWNLOAD_FAILED"
+	ErrCodeCompress       = "COMPRESS_FAILED"
+	ErrCodeCompressWarn   = "COMPRESS_WARNING"       // 7za 종료 코드 1
+	ErrCodeCompressArgs   = "COMPRESS_BAD_ARGS"      // 7za 종료 코드 7
+	ErrCodeOutOfMemory    = "COMPRESS_OUT_OF_MEMORY" // 7za 종료 코드 8
+	ErrCodeUpload         = "UPLOAD_FAILED"
+	ErrCodeQueue          = "QUEUE_FAILED"
+	ErrCodeTimeBudget     = "INSUFFICIENT_TIME"
+	ErrCodeTempSpace      = "INSUFFICIENT_TEMP_SPACE"
+	ErrCodeUnknown        = "UNKNOWN"
+)
+
+// 실패 유형 코드와 원인 에러를 함께 담는 에러 타입
+type CompressionError struct {
+	Code string
+	Err  error
+}
+
+func (e *CompressionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CompressionError) Unwrap() error {
+	return e.Err
+}
+
+func newCompressionError(code string, err error) error {
+	return &CompressionError{Code: code, Err: err}
+}
+
+// 이미 실패 유형 코드가 있는 에러는 그대로 두고, 없으면 code 지정
+func withErrorCode(code string, err error) error {
+	var compressionErr *CompressionError
+	if errors.As(err, &compressionErr) {
+		return err
+	}
+	return newCompressionError(code, err)
+}
+
+// 에러에서 실패 유형 코드 추출 (CompressionError가 아니면 UNKNOWN)
+func errorCode(err error) string {
+	var compressionErr *CompressionError
+	if errors.As(err, &compressionErr) {
+		return compressionErr.Code
+	}
+	return ErrCodeUnknown
+}
+
+// 초기화: 환경 변수로부터 리전 받아서 S3/SQS 클라이언트 생성
+func init() {
+	initLogger()
+	initChaos()
+
+	// 사용자 지정 엔드포인트(LocalStack 등)와 Transfer Acceleration 설정 (클라이언트 생성 전에 읽음)
+	// 사용자 지정 엔드포인트는 보통 가상 호스트 방식을 지원하지 않으므로 기본적으로 경로 방식 사용
+	s3EndpointURL = os.Getenv("S3_ENDPOINT_URL")
+	s3UsePathStyle = s3EndpointURL != "" && os.Getenv("S3_FORCE_PATH_STYLE") != "false"
+	s3UseAccelerate = os.Getenv("S3_USE_ACCELERATE") == "true"
+	if s3UseAccelerate && s3EndpointURL != "" {
+		slog.Warn("S3_USE_ACCELERATE is ignored when S3_ENDPOINT_URL is set", "stage", "init")
+		s3UseAccelerate = false
+	}
+	sqsEndpointURL = os.Getenv("SQS_ENDPOINT_URL")
+
+	// 클라이언트 생성 전에 캐시 크기 설정
+	maxCachedClients := int(getEnvInt64("MAX_CACHED_CLIENTS", DefaultMaxCachedClients))
+	s3Clients = newClientCache[*s3.Client](maxCachedClients)
+	sqsClients = newClientCache[*sqs.Client](maxCachedClients)
+
+	s3Region := os.Getenv("DEFAULT_S3_REGION")
+	if s3Region == "" {
+		s3Region = getLambdaRegion()
+		slog.Warn("DEFAULT_S3_REGION not set, fallback to Lambda region", "stage", "init", "region", s3Region)
+	}
+	s3Client, err := createS3Client(s3Region)
+	if err != nil {
+		fatal("Failed to create default S3 client", "stage", "init", errorAttr(err))
+	}
+	s3Clients.put(s3Region, s3Client)
+
+	sqsRegion := os.Getenv("DEFAULT_SQS_REGION")
+	if sqsRegion == "" {
+		sqsRegion = getLambdaRegion()
+		slog.Warn("DEFAULT_SQS_REGION not set, fallback to Lambda region", "stage", "init", "region", sqsRegion)
+	}
+	sqsClient, err := createSQSClient(sqsRegion, staticCredentials{})
+	if err != nil {
+		fatal("Failed to create default SQS client", "stage", "init", errorAttr(err))
+	}
+	sqsClients.put(sqsRegion, sqsClient)
+
+	loadPathConfig()
+	sweepStaleWorkDirs(time.Duration(getEnvInt64("STALE_TEMP_MINUTES", DefaultStaleTempMinutes))*time.Minute, time.Now())
+
+	multipartThreshold = getEnvInt64("MULTIPART_THRESHOLD_BYTES", DefaultMultipartThreshold)
+	if n := getEnvInt64("COPY_BUFFER_SIZE_BYTES", BufferSize); n > 0 {
+		copyBufferSize = int(n)
+	}
+	defaultMaxRetries = int(getEnvInt64("S3_MAX_RETRIES", DefaultMaxRetries))
+	sevenZipRetries = int(getEnvInt64("SEVENZIP_MAX_RETRIES", DefaultSevenZipRetries))
+	defaultMaxInputBytes = getEnvInt64("MAX_INPUT_BYTES", DefaultMaxInputBytes)
+	maxPrefixObjects = int(getEnvInt64("MAX_PREFIX_OBJECTS", DefaultMaxPrefixObjects))
+	downloadConcurrency = int(getEnvInt64("DOWNLOAD_CONCURRENCY", DefaultDownloadConcurrency))
+	if n := getEnvInt64("MAX_COMPRESS_PROCESSES", int64(runtime.NumCPU())); n > 0 {
+		compressSlots = make(chan struct{}, n)
+	}
+	defaultProcessor = NewProcessor(ProcessorOptions{MaxConcurrent: int(getEnvInt64("MAX_CONCURRENT_REQUESTS", 0))})
+	processingBytesPerSecond = getEnvInt64("PROCESSING_BYTES_PER_SECOND", DefaultBytesPerSecond)
+	timeBudgetHeadroom = time.Duration(getEnvInt64("TIME_BUDGET_HEADROOM_SECONDS", DefaultTimeBudgetHeadroom)) * time.Second
+	progressInterval = getEnvInt64("PROGRESS_LOG_INTERVAL_MB", DefaultProgressIntervalMB) * 1024 * 1024
+	autoLevelSmallBytes = getEnvInt64("AUTO_LEVEL_SMALL_BYTES", DefaultAutoLevelSmallBytes)
+	autoLevelLargeBytes = getEnvInt64("AUTO_LEVEL_LARGE_BYTES", DefaultAutoLevelLargeBytes)
+	if autoLevelLargeBytes < autoLevelSmallBytes {
+		slog.Warn("Invalid AUTO_LEVEL_SMALL_BYTES/AUTO_LEVEL_LARGE_BYTES, using defaults", "stage", "init", "small", autoLevelSmallBytes, "large", autoLevelLargeBytes)
+		autoLevelSmallBytes, autoLevelLargeBytes = DefaultAutoLevelSmallBytes, DefaultAutoLevelLargeBytes
+	}
+	originHTTPClient.Timeout = time.Duration(getEnvInt64("ORIGIN_URL_TIMEOUT", DefaultOriginURLTimeout)) * time.Second
+	tempFileStrategy = defaultIfEmpty(os.Getenv("TEMP_FILE_STRATEGY"), TempFileStrategyPath)
+	if tempFileStrategy != TempFileStrategyPath && tempFileStrategy != TempFileStrategyUnlinked {
+		slog.Warn("Unknown TEMP_FILE_STRATEGY, using default", "stage", "init", "value", tempFileStrategy, "default", TempFileStrategyPath)
+		tempFileStrategy = TempFileStrategyPath
+	}
+	queueLimiter = newRateLimiter(float64(getEnvInt64("QUEUE_SEND_RATE", DefaultQueueSendRate)))
+	queueBreaker = newCircuitBreaker(
+		int(getEnvInt64("QUEUE_BREAKER_THRESHOLD", DefaultQueueBreakerThreshold)),
+		time.Duration(getEnvInt64("QUEUE_BREAKER_COOLDOWN_SECONDS", DefaultQueueBreakerCooldown))*time.Second,
+	)
+
+	// CloudWatch 커스텀 메트릭 발행(선택 옵션)
+	emitMetrics = os.Getenv("EMIT_METRICS") == "true"
+	if emitMetrics {
+		metricsNamespace = defaultIfEmpty(os.Getenv("METRICS_NAMESPACE"), DefaultMetricsNamespace)
+		cloudWatchClient = createCloudWatchClient(getLambdaRegion())
+	}
+
+	initIdempotency()
+}
+
+// 로컬 테스트, 컨테이너 구성 차이를 위해 7za 경로와 임시 디렉토리 변경 가능 (없으면 기본값)
+func loadPathConfig() {
+	sevenZipCmd = defaultIfEmpty(os.Getenv("SEVENZIP_PATH"), SevenZipCmd)
+	tempDir = defaultIfEmpty(os.Getenv("TEMP_DIR"), TempDir)
+}
+
+// Lambda 엔트리 포인트 핸들러 (기본 처리기의 동시 처리 수 제한 안에서 처리)
+func Handler(ctx context.Context, event FileCompressionForm) (CompressionResultData, error) {
+	return defaultProcessor.Process(ctx, event)
+}
+
+// 워밍업 호출: 콜드 스타트를 줄이기 위해 컨테이너만 유지하고 검증/처리는 생략
+// Lambda 리전 클라이언트를 미리 만들어 두어 이후 요청에서 생성 비용이 들지 않도록 함 (실패해도 무시)
+func handleWarmup(ctx context.Context) CompressionResultData {
+	region := getLambdaRegion()
+	if _, err := getS3Client(region); err != nil {
+		loggerFrom(ctx).Debug("Failed to pre-create S3 client", "stage", "warmup", errorAttr(err))
+	}
+	if _, err := getSQSClient(region, staticCredentials{}); err != nil {
+		loggerFrom(ctx).Debug("Failed to pre-create SQS client", "stage", "warmup", errorAttr(err))
+	}
+	loggerFrom(ctx).Debug("Warmup invocation", "stage", "warmup")
+	return CompressionResultData{
+		Result:  ResultSkipped,
+		Reason:  SkipReasonWarmup,
+		Message: "warmup",
+	}
+}
+
+// 요청 1건 처리: 검증 → 다운로드 → 압축 → 업로드 → SQS 결과 전송 → 원본 삭제
+func processRequest(ctx context.Context, event FileCompressionForm) (CompressionResultData, error) {
+	startTime := time.Now()
+
+	// request input 유효성 검사
+	if err := validateRequest(event); err != nil {
+		loggerFrom(ctx).Error("Invalid request", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, newCompressionError(ErrCodeValidation, err))
+	}
+	if event.CollisionStrategy == CollisionOverwrite {
+		event.Overwrite = true
+	}
+	if event.Mode == ModeIndividual {
+		return processIndividual(ctx, event, startTime)
+	}
+
+	// 원본 ContentType으로 포맷 자동 선택 (선택 옵션), 이미 압축된 형식이면 압축하지 않음
+	if event.AutoFormat {
+		resolved, contentType, skip, err := resolveAutoFormat(ctx, event)
+		if err != nil {
+			loggerFrom(ctx).Error("Failed to select format from content type", "stage", "validate", errorAttr(err))
+			return handleFailure(ctx, event, err)
+		}
+		if skip {
+			return handleSkip(ctx, event, SkipReasonIncompressible, CompressionResultData{
+				Message:     fmt.Sprintf("origin content type %s is already compressed", contentType),
+				Region:      defaultIfEmpty(event.OriginRegion, getLambdaRegion()),
+				Bucket:      event.OriginBucket,
+				Key:         event.OriginKey,
+				ProcessUuid: event.ProcessUuid,
+			})
+		}
+		event = resolved
+	}
+
+	// 기본값 설정 - 별도로 Target을 지정하지 않는 경우, Origin 값을 기본 값으로 사용, TargetKey가 비어있으면 OriginKey의 확장자를 압축 포맷의 확장자로 변경하여 사용
+	format := compressionFormats[defaultIfEmpty(event.Format, DefaultFormat)]
+	originRegion := defaultIfEmpty(event.OriginRegion, getLambdaRegion())
+	targetRegion := defaultIfEmpty(event.TargetRegion, originRegion)
+	targetBucket := defaultIfEmpty(event.TargetBucket, event.OriginBucket)
+	operation := defaultIfEmpty(event.Operation, OperationCompress)
+	targetKey := resolveTargetKey(event, operation, format, startTime)
+	if targetKey == "" {
+		err := newCompressionError(ErrCodeValidation, fmt.Errorf("targetKeyTemplate produced an empty key: %s", event.TargetKeyTemplate))
+		loggerFrom(ctx).Error("Invalid request", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	}
+	maxRetries := event.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	// 이미 압축된 파일은 다시 압축하지 않음 (ForceRecompress면 의도적인 재압축으로 보고 생략)
+	if operation == OperationCompress && !event.ForceRecompress && !event.CopyOnly {
+		for _, key := range resolveOriginKeys(event) {
+			if isAlreadyCompressed(key, event.Format) {
+				loggerFrom(ctx).Info("Origin is already compressed, skipping", "stage", "validate", "key", key)
+				return handleSkip(ctx, event, SkipReasonAlreadyCompressed, CompressionResultData{
+					Message:     fmt.Sprintf("file is already compressed: %s (set forceRecompress to compress anyway)", key),
+					Region:      targetRegion,
+					Bucket:      targetBucket,
+					Key:         targetKey,
+					ProcessUuid: event.ProcessUuid,
+				})
+			}
+		}
+	}
+
+	job := compressionJob{
+		Event:        event,
+		Operation:    operation,
+		Format:       format,
+		OriginKeys:   resolveOriginKeys(event),
+		OriginRegion: originRegion,
+		TargetRegion: targetRegion,
+		TargetBucket: targetBucket,
+		TargetKey:    targetKey,
+		MaxRetries:   maxRetries,
+		SevenZip:     buildSevenZipOptions(ctx, event),
+		Compressor:   selectCompressor(ctx, format),
+		Upload:       buildUploadOptions(event, format, targetKey),
+	}
+	job.logPlan(ctx)
+	if err := job.resolveClients(); err != nil {
+		loggerFrom(ctx).Error("Failed to create S3 client", "stage", "init", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	}
+	// validateRequest에서 이미 검사했으므로 에러는 발생하지 않음
+	exclude, _ := compileExcludePatterns(event.ExcludePatterns)
+	if event.OriginPrefix != "" {
+		keys, excluded, err := listPrefixKeys(ctx, job.OriginClient, event.OriginBucket, event.OriginPrefix, exclude)
+		if regionErr := regionMismatchError(err, event.OriginBucket, originRegion); regionErr != nil {
+			err = regionErr
+		}
+		if err != nil {
+			loggerFrom(ctx).Error("Failed to list origin prefix", "stage", "download", "prefix", event.OriginPrefix, errorAttr(err))
+			return handleFailure(ctx, event, err)
+		}
+		loggerFrom(ctx).Info("Listed origin prefix", "stage", "download", "prefix", event.OriginPrefix, "objects", len(keys), "excluded", excluded)
+		job.OriginKeys = keys
+	} else if err := excludeOriginKeys(ctx, &job, exclude); err != nil {
+		loggerFrom(ctx).Error("Invalid request", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	} else if lastModified, err := checkOriginsExist(ctx, job); err != nil {
+		// 원본이 없거나(404) 권한이 없는(403) 경우 다운로드 전에 명확한 에러로 실패
+		loggerFrom(ctx).Error("Origin check failed", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	} else if minAge := time.Duration(event.MinAgeHours) * time.Hour; minAge > 0 && startTime.Sub(lastModified) < minAge {
+		// 아직 변경될 수 있는 최근 객체는 압축하지 않음 (정기 일괄 처리에서 안정된 데이터만 압축)
+		loggerFrom(ctx).Info("Origin is younger than minAgeHours, skipping", "stage", "validate", "lastModified", lastModified, "minAgeHours", event.MinAgeHours)
+		return handleSkip(ctx, event, SkipReasonTooYoung, CompressionResultData{
+			Message:     fmt.Sprintf("origin object was modified less than %d hours ago", event.MinAgeHours),
+			Region:      targetRegion,
+			Bucket:      targetBucket,
+			Key:         targetKey,
+			ProcessUuid: event.ProcessUuid,
+		})
+	}
+	// collisionStrategy rename: 대상 키가 이미 있으면 번호를 붙인 키를 사용하고 결과에도 그 키를 반환
+	if event.CollisionStrategy == CollisionRename {
+		key, err := resolveCollisionKey(ctx, job)
+		if err != nil {
+			loggerFrom(ctx).Error("Target key resolution failed", "stage", "validate", errorAttr(err))
+			return handleFailure(ctx, event, err)
+		}
+		targetKey = key
+		job.TargetKey = key
+		job.Upload = buildUploadOptions(event, format, key)
+	}
+	// 원본과 대상이 같은 객체면 업로드한 결과를 바로 삭제하게 되므로 거부 (버킷 이름은 전역 고유하므로 리전과 무관하게 비교)
+	if event.DeleteOriginal && targetBucket == event.OriginBucket && slices.Contains(job.OriginKeys, targetKey) {
+		err := newCompressionError(ErrCodeValidation, fmt.Errorf("deleteOriginal cannot be used when origin and target are the same object: %s/%s", targetBucket, targetKey))
+		loggerFrom(ctx).Error("Invalid request", "stage", "validate", errorAttr(err))
+		return handleFailure(ctx, event, err)
+	}
+
+	// 객체 잠금 보존을 요청했으면 대상 버킷에 객체 잠금이 활성화되어 있는지 먼저 확인 (다운로드/압축 전에 실패)
+	if event.ObjectLockMode != "" {
+		if err := checkTargetObjectLock(ctx, job.TargetClient, targetBucket); err != nil {
+			loggerFrom(ctx).Error("Target object lock check failed", "stage", "validate", "bucket", targetBucket, errorAttr(err))
+			return handleFailure(ctx, event, err)
+		}
+	}
+
+	// 드라이런: 원본 존재 여부와 대상 경로만 확인하고 다운로드/압축/업로드/삭제/SQS 전송은 생략
+	if event.DryRun {
+		loggerFrom(ctx).Info("Dry run success", "stage", "dryrun", "originRegion", originRegion, "originBucket", event.OriginBucket, "targetRegion", targetRegion, "targetBucket", targetBucket, "targetKey", targetKey)
+		return handleSkip(ctx, event, SkipReasonDryRun, CompressionResultData{
+			Message:     "dry run",
+			Region:      targetRegion,
+			Bucket:      targetBucket,
+			Key:         targetKey,
+			ProcessUuid: event.ProcessUuid,
+		})
+	}
+
+	// 스트리밍 모드는 /tmp를 거치지 않고 S3 → 7za → S3 로 직접 전송
+	var stats compressionStats
+	var err error
+	switch {
+	case operation == OperationDecompress:
+		stats, err = runDecompression(ctx, job)
+	case event.CopyOnly:
+		stats, err = runServerSideCopy(ctx, job)
+	case event.StreamMode:
+		stats, err = runStreamCompression(ctx, job)
+	default:
+		stats, err = runTempFileCompression(ctx, job)
+	}
+	if err != nil {
+		return handleFailure(ctx, event, err)
+	}
+
+	result := CompressionResultData{
+		Result:         ResultSucceed,
+		Message:        successMessage(job, stats),
+		Region:         targetRegion,
+		Bucket:         targetBucket,
+		Key:            targetKey,
+		ProcessUuid:    event.ProcessUuid,
+		Keys:           stats.UploadedKeys,
+		Passthrough:    event.Passthrough,
+		OriginalSize:   stats.OriginalSize,
+		CompressedSize: stats.CompressedSize,
+		DownloadMs:     stats.DownloadDuration.Milliseconds(),
+		CompressMs:     stats.CompressDuration.Milliseconds(),
+		UploadMs:       stats.UploadDuration.Milliseconds(),
+		TotalMs:        time.Since(startTime).Milliseconds(),
+	}
+	// URL 생성에 실패해도 업로드는 끝났으므로 경고만 남기고 URL 없이 성공 처리
+	if event.PresignExpiry > 0 {
+		if url, err := presignTargetURL(ctx, job); err != nil {
+			loggerFrom(ctx).Warn("Failed to presign target object", "stage", "upload", errorAttr(err))
+		} else {
+			result.PresignedUrl = url
+		}
+	}
+
+	// 원본 삭제(선택 옵션)
+	// 되돌릴 수 없는 작업이므로 업로드와 결과 전송까지 모두 성공한 뒤 마지막에 수행 (결과 전송 실패 시 원본 유지, 배치 전송이면 전송된 뒤 수행)
+	deleteOriginals := func() {
+		if !event.DeleteOriginal {
+			return
+		}
+		for _, key := range job.OriginKeys {
+			err := withRetry(ctx, "delete", maxRetries, func() error {
+				return deleteFromS3(ctx, job.OriginClient, event.OriginBucket, key, event.OriginVersionId)
+			})
+			if err != nil {
+				loggerFrom(ctx).Warn("Failed to delete original file", "stage", "delete", "key", key, errorAttr(err))
+			} else {
+				loggerFrom(ctx).Info("Original file deleted", "stage", "delete", "bucket", event.OriginBucket, "key", key)
+			}
+		}
+	}
+
+	// SQS로 결과 전송 (SuccessQueueUrl이 없으면 QueueUrl 사용, 둘 다 없으면 생략)
+	if queueUrl := defaultIfEmpty(event.SuccessQueueUrl, event.QueueUrl); queueUrl != "" {
+		if err := sendResultToQueue(ctx, event, queueUrl, result, deleteOriginals); err != nil {
+			if !errors.Is(err, errCircuitOpen) {
+				loggerFrom(ctx).Error("Failed to send SQS message", "stage", "queue", errorAttr(err))
+			}
+			return handleFailure(ctx, event, newCompressionError(ErrCodeQueue, err))
+		}
+	} else {
+		deleteOriginals()
+	}
+
+	if emitMetrics {
+		publishMetrics(ctx, job, stats)
+	}
+
+	loggerFrom(ctx).Info("File processing success", "stage", "complete", durationAttr(time.Since(startTime)))
+	return result, nil
+}
+
+// 모든 원본 객체가 존재하는지 HeadObject로 확인 (권한 확인 겸용)하고 가장 최근 LastModified 반환
+// s3:ListBucket 권한이 없으면 없는 키에 대해서도 S3가 403을 반환하므로 ACCESS_DENIED 메시지에 함께 안내
+func checkOriginsExist(ctx context.Context, job compressionJob) (time.Time, error) {
+	// URL 원본은 다운로드할 때 상태 코드로 확인
+	if job.Event.OriginUrl != "" {
+		return time.Time{}, nil
+	}
+	client := job.OriginClient
+	var lastModified time.Time
+	for _, key := range job.OriginKeys {
+		out, err := headObject(ctx, client, job.Event.OriginBucket, key, job.Event.OriginVersionId)
+		if regionErr := regionMismatchError(err, job.Event.OriginBucket, job.OriginRegion); regionErr != nil {
+			return time.Time{}, regionErr
+		}
+		if sseErr := describeSSECustomerError(err, job.Event, key); sseErr != err {
+			return time.Time{}, sseErr
+		}
+		if isAccessDenied(err) {
+			return time.Time{}, newCompressionError(ErrCodeAccessDenied, fmt.Errorf("access denied to origin object: %s/%s (the key may also not exist without s3:ListBucket permission)", job.Event.OriginBucket, key))
+		}
+		if err != nil {
+			return time.Time{}, newCompressionError(ErrCodeDownload, err)
+		}
+		if out == nil {
+			return time.Time{}, newCompressionError(ErrCodeOriginNotFound, fmt.Errorf("origin object not found: %s/%s", job.Event.OriginBucket, key))
+		}
+		if modified := aws.ToTime(out.LastModified); modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+	return lastModified, nil
+}
+
+// 버킷이 다른 리전에 있을 때 S3가 반환하는 에러(301 PermanentRedirect, 400 AuthorizationHeaderMalformed)에서 실제 리전 추출
+// x-amz-bucket-region 헤더를 우선 사용하고, 없으면 에러 메시지의 "expecting '<region>'" 부분을 사용
+var expectingRegionPattern = regexp.MustCompile(`expecting '([a-z0-9-]+)'`)
+
+func bucketRegionFromError(err error) (string, bool) {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return "", false
+	}
+	if respErr.Response != nil && respErr.Response.Response != nil {
+		if region := respErr.Response.Header.Get("X-Amz-Bucket-Region"); region != "" {
+			return region, true
+		}
+	}
+	if match := expectingRegionPattern.FindStringSubmatch(err.Error()); match != nil {
+		return match[1], true
+	}
+	return "", false
+}
+
+// 리전 불일치 에러면 실제 리전을 알려주는 에러 반환 (아니면 nil)
+func regionMismatchError(err error, bucket, region string) error {
+	actual, ok := bucketRegionFromError(err)
+	if !ok || actual == region {
+		return nil
+	}
+	return newCompressionError(ErrCodeWrongRegion, fmt.Errorf("bucket %s is in region %s, not %s (set originRegion to %s)", bucket, actual, region, actual))
+}
+
+// S3 응답이 403(Forbidden)인지 확인
+func isAccessDenied(err error) bool {
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusForbidden
+}
+
+// HeadObject로 원본 전체 크기 조회
+func originSize(ctx context.Context, job compressionJob) (int64, error) {
+	client := job.OriginClient
+	var total int64
+	for _, key := range job.OriginKeys {
+		out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:    aws.String(job.Event.OriginBucket),
+			Key:       aws.String(key),
+			VersionId: versionIdParam(job.Event.OriginVersionId),
+		})
+		if err != nil {
+			return 0, newCompressionError(ErrCodeDownload, fmt.Errorf("failed to head S3 object: %w", err))
+		}
+		size := aws.ToInt64(out.ContentLength)
+		if byteRangeParam(job.Event) != nil {
+			if size, err = byteRangeSize(job.Event, size); err != nil {
+				return 0, err
+			}
+		}
+		total += size
+	}
+	loggerFrom(ctx).Info("Detected input size", "stage", "validate", bytesAttr(total))
+	return total, nil
+}
+
+// 원본 전체 크기가 MaxInputBytes(요청 값, 없으면 MAX_INPUT_BYTES)를 넘는지 확인
+func checkInputSize(job compressionJob, total int64) error {
+	if limit := maxInputBytes(job); total > limit {
+		return newCompressionError(ErrCodeValidation, fmt.Errorf("input size %d bytes exceeds limit of %d bytes", total, limit))
+	}
+	return nil
+}
+
+// 요청의 MaxInputBytes (없으면 MAX_INPUT_BYTES)
+func maxInputBytes(job compressionJob) int64 {
+	if job.Event.MaxInputBytes == 0 {
+		return defaultMaxInputBytes
+	}
+	return job.Event.MaxInputBytes
+}
+
+// 남은 실행 시간(context 데드라인)이 예상 처리 시간 + 여유 시간보다 짧으면 미리 실패 처리
+// Lambda 강제 종료 시에는 임시 파일 정리와 실패 큐 전송이 불가능하므로 시작 전에 거부
+// 예상 처리 시간은 PROCESSING_BYTES_PER_SECOND 기준 추정값
+func checkTimeBudget(ctx context.Context, total int64) error {
+	deadline, ok := ctx.Deadline()
+	if !ok || processingBytesPerSecond <= 0 {
+		return nil
+	}
+	estimated := time.Duration(float64(total) / float64(processingBytesPerSecond) * float64(time.Second))
+	remaining := time.Until(deadline)
+	if remaining < estimated+timeBudgetHeadroom {
+		return newCompressionError(ErrCodeTimeBudget, fmt.Errorf("insufficient time budget: %s remaining, estimated %s for %d bytes plus %s headroom", remaining.Round(time.Millisecond), estimated.Round(time.Millisecond), total, timeBudgetHeadroom))
+	}
+	return nil
+}
+
+// 크기가 0인 원본은 경고 로그를 남기고, RejectEmpty면 빈 아카이브를 만들지 않고 실패 처리
+func checkEmptyOrigin(ctx context.Context, job compressionJob, key string, size int64) error {
+	if size != 0 {
+		return nil
+	}
+	loggerFrom(ctx).Warn("Origin object is empty (0 bytes)", "stage", "validate", "key", key, bytesAttr(size))
+	if job.Event.RejectEmpty {
+		return newCompressionError(ErrCodeEmptyOrigin, fmt.Errorf("origin object is empty: %s/%s", job.Event.OriginBucket, key))
+	}
+	return nil
+}
+
+// 원본 크기 조회 후 /tmp 용량 제한, 임시 디렉토리 여유 공간, 실행 시간 예산 확인
+// 다운로드한 원본과 압축 결과가 함께 남으므로 여유 공간은 원본 크기의 2배 이상 필요 (압축 해제는 최소 추정치)
+func checkInputLimits(ctx context.Context, job compressionJob) error {
+	// URL 원본은 미리 크기를 알 수 없으므로 다운로드하면서 MaxInputBytes를 확인
+	if job.Event.OriginUrl != "" {
+		return nil
+	}
+	total, err := originSize(ctx, job)
+	if err != nil {
+		return err
+	}
+	if err := checkInputSize(job, total); err != nil {
+		return err
+	}
+	if err := checkTempSpace(ctx, "validate", 2*total); err != nil {
+		return err
+	}
+	return checkTimeBudget(ctx, total)
+}
+
+// 임시 파일(/tmp)에 다운로드 후 압축하여 업로드
+func runTempFileCompression(ctx context.Context, job compressionJob) (compressionStats, error) {
+	var stats compressionStats
+
+	// 덮어쓰기 옵션이 없으면 대상 객체 존재 여부를 먼저 확인 (불필요한 다운로드/압축 방지)
+	// 이어 붙이기 모드는 기존 대상 객체를 사용하므로 존재 여부 확인 생략
+	if !job.Event.Overwrite && !job.Event.Append {
+		// 분할 압축이면 첫 번째 볼륨 키로 확인
+		targetKey := job.TargetKey
+		if job.SevenZip.VolumeSize != "" {
+			targetKey += firstVolumeSuffix
+		}
+		exists, err := objectExists(ctx, job.TargetClient, job.TargetBucket, targetKey, "")
+		if err != nil {
+			loggerFrom(ctx).Error("Target existence check failed", "stage", "upload", errorAttr(err))
+			return stats, newCompressionError(ErrCodeUpload, err)
+		}
+		if exists {
+			return stats, newCompressionError(ErrCodeTargetExists, fmt.Errorf("target object already exists: %s/%s", job.TargetBucket, targetKey))
+		}
+	}
+
+	// 다운로드 전에 원본 크기를 확인하여 /tmp 용량을 넘거나 제한 시간 안에 끝낼 수 없는 요청은 미리 거부
+	if err := checkInputLimits(ctx, job); err != nil {
+		loggerFrom(ctx).Error("Input size check failed", "stage", "validate", errorAttr(err))
+		return stats, err
+	}
+
+	// 임시 파일 경로 설정 (요청별 작업 디렉토리 아래에 생성하여 동시 실행 간 충돌 방지)
+	workDir, err := createWorkDir(job.Event.ProcessUuid)
+	if err != nil {
+		return stats, newCompressionError(ErrCodeDownload, err)
+	}
+	defer cleanupTemp(ctx, workDir)
+	inputPaths, outputPath := buildTempPaths(workDir, job.OriginKeys, job.Format.Extension)
+	archiveInputs := inputPaths
+	entryRoot := "" // 아카이브 항목 경로의 기준 디렉토리 (비어있으면 파일명만 저장됨)
+	if job.Event.OriginPrefix != "" {
+		// 접두사 압축: 별도 디렉토리에 하위 경로를 유지하여 다운로드하고 디렉토리 내용 전체를 압축
+		entryRoot = filepath.Join(workDir, "prefix")
+		if inputPaths, err = buildPrefixPaths(entryRoot, job.Event.OriginPrefix, job.OriginKeys); err != nil {
+			return stats, newCompressionError(ErrCodeDownload, err)
+		}
+		archiveInputs = []string{filepath.Join(entryRoot, "*")}
+	}
+
+	// 압축할 파일 다운로드 (여러 파일이면 병렬)
+	start := time.Now()
+	infos, err := downloadAll(ctx, job, inputPaths)
+	if err != nil {
+		return stats, withErrorCode(ErrCodeDownload, err)
+	}
+	origin := infos[0]
+	for i, info := range infos {
+		if err := checkEmptyOrigin(ctx, job, job.OriginKeys[i], info.Size); err != nil {
+			return stats, err
+		}
+		stats.OriginalSize += info.Size
+	}
+	stats.DownloadDuration = time.Since(start)
+	loggerFrom(ctx).Info("Download success", "stage", "download", "files", len(job.OriginKeys), bytesAttr(stats.OriginalSize), durationAttr(stats.DownloadDuration))
+	if job.Event.ResumeUpload || job.Event.Reproducible {
+		if err := setOriginModTimes(inputPaths, infos); err != nil {
+			return stats, newCompressionError(ErrCodeDownload, err)
+		}
+		if entryRoot != "" {
+			if err := setDirModTimes(entryRoot, latestModTime(infos)); err != nil {
+				return stats, newCompressionError(ErrCodeDownload, err)
+			}
+		}
+	}
+
+	// 다운로드 중 다른 동시 실행이 공간을 사용했을 수 있으므로 압축 출력을 쓸 공간이 남았는지 다시 확인
+	if err := checkTempSpace(ctx, "compress", stats.OriginalSize); err != nil {
+		loggerFrom(ctx).Error("Temp space check failed", "stage", "compress", errorAttr(err))
+		return stats, err
+	}
+
+	// 파일 압축 수행 (autoLevel이면 다운로드한 원본 크기(HeadObject 크기와 같음)로 레벨 선택)
+	if job.Event.AutoLevel {
+		applyAutoLevel(ctx, &job.SevenZip, stats.OriginalSize)
+	}
+	start = time.Now()
+	if err := compressFile(ctx, job.Compressor, archiveInputs, outputPath, job.SevenZip); err != nil {
+		loggerFrom(ctx).Error("Compression failed", "stage", "compress", durationAttr(time.Since(start)), errorAttr(err))
+		return stats, withErrorCode(ErrCodeCompress, err)
+	}
+	stats.CompressDuration = time.Since(start)
+	stats.TempFreeBytes = recordTempFreeBytes(ctx, "compress")
+	loggerFrom(ctx).Info("Compression success", "stage", "compress", durationAttr(stats.CompressDuration))
+
+	// 아카이브 내용 확인(선택 옵션): 항목 목록이 입력 파일과 다르면 업로드하지 않음
+	if job.Event.VerifyContents {
+		if err := verifyArchiveContents(ctx, outputPath, expectedArchiveEntries(inputPaths, entryRoot), job.SevenZip); err != nil {
+			loggerFrom(ctx).Error("Archive contents verification failed", "stage", "verify", errorAttr(err))
+			return stats, withErrorCode(ErrCodeVerify, err)
+		}
+	}
+
+	// 압축 결과가 원본보다 작지 않으면 경고, SkipIfLarger 옵션이면 원본 파일을 그대로 업로드 (단일 파일만)
+	uploadPath := outputPath
+	if info, err := os.Stat(outputPath); err == nil && info.Size() >= stats.OriginalSize {
+		loggerFrom(ctx).Warn("Compressed size is not smaller than original", "stage", "compress", bytesAttr(info.Size()), "originalBytes", stats.OriginalSize, "ratio", compressionRatio(info.Size(), stats.OriginalSize))
+		if job.Event.SkipIfLarger && len(inputPaths) == 1 {
+			loggerFrom(ctx).Info("SkipIfLarger set, uploading original file instead of compressed output", "stage", "compress")
+			uploadPath = inputPaths[0]
+			stats.UploadedOriginal = true
+			// 압축되지 않은 원본이므로 원본의 ContentType 사용
+			job.Upload.ContentType = origin.ContentType
+		}
+	}
+
+	// 이어 붙이기: 기존 대상 객체 뒤에 새 압축 결과를 붙인 파일을 업로드
+	if job.Event.Append {
+		if uploadPath, err = appendToExistingTarget(ctx, job, workDir, uploadPath); err != nil {
+			loggerFrom(ctx).Error("Failed to append to existing target", "stage", "append", errorAttr(err))
+			return stats, err
+		}
+	}
+
+	// 업로드할 파일 목록 (분할 압축이면 생성된 볼륨 파일 각각을 대상 키 + 볼륨 번호로 업로드)
+	uploads := []volumeFile{{Path: uploadPath, Key: job.TargetKey}}
+	if job.SevenZip.VolumeSize != "" {
+		if uploads, err = listVolumeFiles(outputPath, job.TargetKey); err != nil {
+			loggerFrom(ctx).Error("Compression failed", "stage", "compress", errorAttr(err))
+			return stats, newCompressionError(ErrCodeCompress, err)
+		}
+		loggerFrom(ctx).Info("Split archive into volumes", "stage", "compress", "volumes", len(uploads))
+	}
+
+	// 압축된 파일 지정된 버킷에 업로드
+	uploadOpts := job.Upload
+	if job.Event.PreserveMetadata {
+		uploadOpts = uploadOpts.withOriginMetadata(origin, job.OriginKeys[0])
+	}
+	if job.Event.PreserveTimestamp {
+		uploadOpts = uploadOpts.withOriginTimestamp(origin.LastModified)
+	}
+	if job.Event.OriginHash != "" {
+		uploadOpts = uploadOpts.withOriginHash(job.Event.OriginHash, origin.Hash)
+	}
+	uploadPaths := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		uploadPaths = append(uploadPaths, upload.Path)
+	}
+	sources, closeSources, err := openUploadSources(ctx, workDir, uploadPaths)
+	if err != nil {
+		loggerFrom(ctx).Error("Upload failed", "stage", "upload", errorAttr(err))
+		return stats, newCompressionError(ErrCodeUpload, err)
+	}
+	defer closeSources()
+	s3Client := job.TargetClient
+	start = time.Now()
+	for i, upload := range uploads {
+		var size int64
+		err = withRetry(ctx, "upload", job.MaxRetries, func() error {
+			var err error
+			size, err = uploadToS3(ctx, s3Client, job.TargetBucket, upload.Key, sources[i], uploadOpts)
+			return err
+		})
+		if err != nil {
+			loggerFrom(ctx).Error("Upload failed", "stage", "upload", "key", upload.Key, durationAttr(time.Since(start)), errorAttr(err))
+			return stats, newCompressionError(ErrCodeUpload, err)
+		}
+		stats.CompressedSize += size
+		if job.SevenZip.VolumeSize != "" {
+			stats.UploadedKeys = append(stats.UploadedKeys, upload.Key)
+		}
+	}
+	stats.UploadDuration = time.Since(start)
+	loggerFrom(ctx).Info("Upload success", "stage", "upload", bytesAttr(stats.CompressedSize), durationAttr(stats.UploadDuration))
+	return stats, nil
+}
+
+// 원본 키들을 최대 downloadConcurrency개씩 병렬로 다운로드 (결과는 OriginKeys 순서)
+// 하나라도 실패하면 나머지 다운로드를 context로 취소하고 첫 번째 에러 반환 (받다 만 파일은 작업 디렉토리와 함께 삭제됨)
+func downloadAll(ctx context.Context, job compressionJob, destPaths []string) ([]objectInfo, error) {
+	if err := injectFault("download"); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	infos := make([]objectInfo, len(job.OriginKeys))
+	sem := make(chan struct{}, max(downloadConcurrency, 1))
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	start := time.Now()
+	for i, key := range job.OriginKeys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			err := withRetry(ctx, "download", job.MaxRetries, func() error {
+				var err error
+				if job.Event.OriginUrl != "" {
+					infos[i], err = downloadFromURL(ctx, job, destPaths[i])
+					return err
+				}
+				infos[i], err = downloadFromS3(ctx, job.OriginClient, job.Event.OriginBucket, key, job.Event.OriginVersionId, byteRangeParam(job.Event), destPaths[i], job.Event.OriginHash)
+				return err
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					loggerFrom(ctx).Error("Download failed", "stage", "download", "key", key, durationAttr(time.Since(start)), errorAttr(err))
+					firstErr = describeSSECustomerError(err, job.Event, key)
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// 다운로드 시작 전에 상위 context가 취소된 경우
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// S3 GetObject 본문을 압축기(7za 표준입력 또는 Go 구현 Writer)로, 압축 결과를 S3 업로드로 바로 연결
+func runStreamCompression(ctx context.Context, job compressionJob) (compressionStats, error) {
+	var stats compressionStats
+
+	// 스트리밍은 /tmp를 쓰지 않으므로 크기 제한 없이 실행 시간 예산만 확인
+	total, err := originSize(ctx, job)
+	if err == nil {
+		err = checkEmptyOrigin(ctx, job, job.Event.OriginKey, total)
+	}
+	if err == nil {
+		err = checkTimeBudget(ctx, total)
+	}
+	if err != nil {
+		loggerFrom(ctx).Error("Input size check failed", "stage", "validate", errorAttr(err))
+		return stats, err
+	}
+	if job.Event.AutoLevel {
+		applyAutoLevel(ctx, &job.SevenZip, total)
+	}
+
+	// 스트리밍은 다운로드/압축/업로드가 동시에 진행되므로 시작 전에 세 단계의 장애 주입을 모두 확인
+	if err := injectStreamFault(); err != nil {
+		loggerFrom(ctx).Error("Stream compression failed", "stage", "stream", errorAttr(err))
+		return stats, err
+	}
+
+	start := time.Now()
+	var originalSize, compressedSize int64
+	if sc, ok := job.Compressor.(StreamCompressor); ok {
+		originalSize, compressedSize, err = streamCompressNative(ctx, job.OriginClient, job.TargetClient, job, sc)
+	} else {
+		originalSize, compressedSize, err = streamCompressS3(ctx, job.OriginClient, job.TargetClient, job)
+	}
+	if err != nil {
+		loggerFrom(ctx).Error("Stream compression failed", "stage", "stream", durationAttr(time.Since(start)), errorAttr(err))
+		return stats, err
+	}
+	stats.OriginalSize = originalSize
+	stats.CompressedSize = compressedSize
+	stats.CompressDuration = time.Since(start)
+	loggerFrom(ctx).Info("Stream compression success", "stage", "stream", "originalBytes", originalSize, bytesAttr(compressedSize), durationAttr(stats.CompressDuration))
+	if compressedSize >= originalSize {
+		loggerFrom(ctx).Warn("Compressed size is not smaller than original", "stage", "stream", bytesAttr(compressedSize), "originalBytes", originalSize, "ratio", compressionRatio(compressedSize, originalSize))
+	}
+	return stats, nil
+}
+
+// .7z 아카이브를 임시 디렉토리에 풀어서 내부 파일(1개)을 업로드
+func runDecompression(ctx context.Context, job compressionJob) (compressionStats, error) {
+	var stats compressionStats
+
+	if !job.Event.Overwrite {
+		exists, err := objectExists(ctx, job.TargetClient, job.TargetBucket, job.TargetKey, "")
+		if err != nil {
+			loggerFrom(ctx).Error("Target existence check failed", "stage", "upload", errorAttr(err))
+			return stats, newCompressionError(ErrCodeUpload, err)
+		}
+		if exists {
+			return stats, newCompressionError(ErrCodeTargetExists, fmt.Errorf("target object already exists: %s/%s", job.TargetBucket, job.TargetKey))
+		}
+	}
+
+	if err := checkInputLimits(ctx, job); err != nil {
+		loggerFrom(ctx).Error("Input size check failed", "stage", "validate", errorAttr(err))
+		return stats, err
+	}
+
+	// 아카이브와 압축 해제 결과 모두 요청별 작업 디렉토리 아래에 저장
+	workDir, err := createWorkDir(job.Event.ProcessUuid)
+	if err != nil {
+		return stats, newCompressionError(ErrCodeDownload, err)
+	}
+	defer cleanupTemp(ctx, workDir)
+	archivePath := filepath.Join(workDir, filepath.Base(job.Event.OriginKey))
+	extractDir := filepath.Join(workDir, "extract")
+	if err := os.Mkdir(extractDir, 0o755); err != nil {
+		return stats, newCompressionError(ErrCodeCompress, fmt.Errorf("failed to create extract directory: %w", err))
+	}
+
+	s3Client := job.OriginClient
+	start := time.Now()
+	var origin objectInfo
+	if err := injectFault("download"); err != nil {
+		return stats, newCompressionError(ErrCodeDownload, err)
+	}
+	err = withRetry(ctx, "download", job.MaxRetries, func() error {
+		var err error
+		origin, err = downloadFromS3(ctx, s3Client, job.Event.OriginBucket, job.Event.OriginKey, job.Event.OriginVersionId, nil, archivePath, "")
+		return err
+	})
+	if err != nil {
+		loggerFrom(ctx).Error("Download failed", "stage", "download", "key", job.Event.OriginKey, durationAttr(time.Since(start)), errorAttr(err))
+		return stats, newCompressionError(ErrCodeDownload, err)
+	}
+	stats.OriginalSize = origin.Size
+	stats.DownloadDuration = time.Since(start)
+	loggerFrom(ctx).Info("Download success", "stage", "download", bytesAttr(stats.OriginalSize), durationAttr(stats.DownloadDuration))
+
+	start = time.Now()
+	if err := injectFault("compress"); err != nil {
+		return stats, newCompressionError(ErrCodeCompress, err)
+	}
+	extractedPath, err := extractFile(ctx, archivePath, extractDir, job.SevenZip)
+	if err != nil {
+		loggerFrom(ctx).Error("Decompression failed", "stage", "decompress", durationAttr(time.Since(start)), errorAttr(err))
+		return stats, err
+	}
+	stats.CompressDuration = time.Since(start)
+	stats.TempFreeBytes = recordTempFreeBytes(ctx, "decompress")
+	loggerFrom(ctx).Info("Decompression success", "stage", "decompress", durationAttr(stats.CompressDuration))
+
+	// 아카이브의 ContentType은 압축 해제 결과에 맞지 않으므로 사용자 메타데이터만 이어받음
+	uploadOpts := job.Upload
+	uploadOpts.ContentType = ""
+	if job.Event.PreserveMetadata {
+		uploadOpts = uploadOpts.withOriginMetadata(objectInfo{Metadata: origin.Metadata}, job.Event.OriginKey)
+	}
+	if job.Event.PreserveTimestamp {
+		uploadOpts = uploadOpts.withOriginTimestamp(origin.LastModified)
+	}
+	sources, closeSources, err := openUploadSources(ctx, workDir, []string{extractedPath})
+	if err != nil {
+		loggerFrom(ctx).Error("Upload failed", "stage", "upload", errorAttr(err))
+		return stats, newCompressionError(ErrCodeUpload, err)
+	}
+	defer closeSources()
+	s3Client = job.TargetClient
+	start = time.Now()
+	err = withRetry(ctx, "upload", job.MaxRetries, func() error {
+		var err error
+		stats.CompressedSize, err = uploadToS3(ctx, s3Client, job.TargetBucket, job.TargetKey, sources[0], uploadOpts)
+		return err
+	})
+	if err != nil {
+		loggerFrom(ctx).Error("Upload failed", "stage", "upload", durationAttr(time.Since(start)), errorAttr(err))
+		return stats, newCompressionError(ErrCodeUpload, err)
+	}
+	stats.UploadDuration = time.Since(start)
+	loggerFrom(ctx).Info("Upload success", "stage", "upload", bytesAttr(stats.CompressedSize), durationAttr(stats.UploadDuration))
+	return stats, nil
+}
+
+func successMessage(job compressionJob, stats compressionStats) string {
+	if job.Operation == OperationDecompress {
+		return "Decompression succeeded"
+	}
+	if job.Event.CopyOnly {
+		return "Original copied without compression"
+	}
+	if stats.UploadedOriginal {
+		return "Compressed output was not smaller than original; original uploaded uncompressed"
+	}
+	return "Compression succeeded"
+}
+
+// 압축 크기 / 원본 크기 (원본이 0이면 0)
+func compressionRatio(compressedSize, originalSize int64) float64 {
+	if originalSize == 0 {
+		return 0
+	}
+	return float64(compressedSize) / float64(originalSize)
+}
+
+func defaultIfEmpty(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// AWS 리전 형식 (예: ap-northeast-2, us-gov-west-1, cn-north-1)
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// 요청에 지정된 리전 값 검사 (비어있으면 기본 리전을 사용하므로 통과)
+func validateRegions(event FileCompressionForm) error {
+	regions := []struct{ name, value string }{
+		{"originRegion", event.OriginRegion},
+		{"targetRegion", event.TargetRegion},
+		{"queueRegion", event.QueueRegion},
+	}
+	for _, region := range regions {
+		if region.value != "" && !regionPattern.MatchString(region.value) {
+			return fmt.Errorf("invalid %s: %s", region.name, region.value)
+		}
+	}
+	return nil
+}
+
+// SQS 큐 URL 형식: https://sqs.<region>.amazonaws.com/<계정 ID>/<큐 이름>
+var queueUrlPattern = regexp.MustCompile(`^https://sqs\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?/[0-9]{12}/[A-Za-z0-9_-]{1,80}(\.fifo)?$`)
+
+// 결과 큐 URL 형식과 QueueRegion이 URL의 리전과 일치하는지 확인 (S3 작업 전에 잘못된 설정을 걸러냄)
+func validateQueueUrls(event FileCompressionForm) error {
+	queues := []struct{ name, url string }{
+		{"queueUrl", event.QueueUrl},
+		{"successQueueUrl", event.SuccessQueueUrl},
+		{"failureQueueUrl", event.FailureQueueUrl},
+	}
+	for _, queue := range queues {
+		if queue.url == "" {
+			continue
+		}
+		match := queueUrlPattern.FindStringSubmatch(queue.url)
+		if match == nil {
+			return fmt.Errorf("invalid %s: %s", queue.name, queue.url)
+		}
+		if event.QueueRegion != "" && match[1] != event.QueueRegion {
+			return fmt.Errorf("%s region %s does not match queueRegion %s", queue.name, match[1], event.QueueRegion)
+		}
+	}
+	switch event.MessageFormat {
+	case "", MessageFormatRaw, MessageFormatSNS:
+		return nil
+	default:
+		return fmt.Errorf("unsupported messageFormat: %s (expected %s or %s)", event.MessageFormat, MessageFormatRaw, MessageFormatSNS)
+	}
+}
+
+// 7za 크기 형식(사전 크기, 볼륨 크기): 숫자 + 단위(b, k, m, g)
+var dictionarySizePattern = regexp.MustCompile(`^(?i)[1-9][0-9]*[bkmg]?$`)
+
+// 솔리드 블록 크기 (예: 64m, 1g) 또는 블록당 파일 수 (예: 100f)
+var solidBlockPattern = regexp.MustCompile(`^(?i)[1-9][0-9]*[bkmgf]$`)
+
+func validateRequest(event FileCompressionForm) error {
+	if event.OriginUrl != "" {
+		if err := validateOriginUrl(event); err != nil {
+			return err
+		}
+	} else if event.OriginPrefix != "" {
+		if err := validateOriginPrefix(event); err != nil {
+			return err
+		}
+	} else if len(event.OriginKeys) > 0 {
+		if err := validateOriginKeys(event); err != nil {
+			return err
+		}
+	} else if event.OriginBucket == "" || event.OriginKey == "" {
+		return fmt.Errorf("origin bucket and key required")
+	}
+	switch defaultIfEmpty(event.Operation, OperationCompress) {
+	case OperationCompress:
+		// 이미 압축된 파일은 processRequest에서 SKIPPED로 처리
+	case OperationDecompress:
+		if err := validateDecompress(event); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported operation: %s", event.Operation)
+	}
+	if _, ok := compressionFormats[defaultIfEmpty(event.Format, DefaultFormat)]; !ok {
+		return fmt.Errorf("unsupported format: %s", event.Format)
+	}
+	if event.StreamMode && !compressionFormats[defaultIfEmpty(event.Format, DefaultFormat)].SupportsStream {
+		return fmt.Errorf("stream mode is not supported for format: %s", defaultIfEmpty(event.Format, DefaultFormat))
+	}
+	if event.MaxInputBytes < 0 {
+		return fmt.Errorf("max input bytes must not be negative")
+	}
+	if event.MaxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative")
+	}
+	if event.StreamMode && event.VerboseCompress {
+		return fmt.Errorf("verboseCompress is not supported in stream mode")
+	}
+	if event.StreamMode && event.VerifyChecksum {
+		return fmt.Errorf("verifyChecksum is not supported in stream mode")
+	}
+	if err := validateRegions(event); err != nil {
+		return err
+	}
+	if err := validateQueueUrls(event); err != nil {
+		return err
+	}
+	if err := validateTargetKeyTemplate(event.TargetKeyTemplate); err != nil {
+		return err
+	}
+	if err := validateEncryption(event); err != nil {
+		return err
+	}
+	if err := validateSSECustomerKey(event); err != nil {
+		return err
+	}
+	if err := validateStorageClass(event.StorageClass); err != nil {
+		return err
+	}
+	if err := validateTags(event.Tags); err != nil {
+		return err
+	}
+	if err := validateDisposition(event.Disposition); err != nil {
+		return err
+	}
+	if err := validatePresignExpiry(event); err != nil {
+		return err
+	}
+	if err := validateCopyOnly(event); err != nil {
+		return err
+	}
+	if err := validateAppend(event); err != nil {
+		return err
+	}
+	if err := validateVerifyContents(event); err != nil {
+		return err
+	}
+	if err := validateResumeUpload(event); err != nil {
+		return err
+	}
+	if err := validateAutoFormat(event); err != nil {
+		return err
+	}
+	if err := validateReproducible(event); err != nil {
+		return err
+	}
+	if err := validateAutoLevel(event); err != nil {
+		return err
+	}
+	if err := validateObjectLock(event); err != nil {
+		return err
+	}
+	if err := validateCollisionStrategy(event); err != nil {
+		return err
+	}
+	if err := validateOriginHash(event); err != nil {
+		return err
+	}
+	if err := validateCredentials(event); err != nil {
+		return err
+	}
+	if err := validateByteRange(event); err != nil {
+		return err
+	}
+	switch event.Mode {
+	case "", ModeArchive:
+	case ModeIndividual:
+		if len(event.OriginKeys) == 0 {
+			return fmt.Errorf("mode %s requires originKeys", ModeIndividual)
+		}
+	default:
+		return fmt.Errorf("unsupported mode: %s", event.Mode)
+	}
+	if event.MinAgeHours < 0 {
+		return fmt.Errorf("minAgeHours must not be negative")
+	}
+	if event.MinAgeHours > 0 && event.OriginPrefix != "" {
+		return fmt.Errorf("minAgeHours is not supported with originPrefix")
+	}
+	if len(event.ExcludePatterns) > 0 {
+		if event.OriginPrefix == "" && len(event.OriginKeys) == 0 {
+			return fmt.Errorf("excludePatterns is only supported with originKeys or originPrefix")
+		}
+		if _, err := compileExcludePatterns(event.ExcludePatterns); err != nil {
+			return err
+		}
+	}
+	if event.CompressionLevel < 0 || event.CompressionLevel > MaxCompressionLevel {
+		return fmt.Errorf("compression level must be between 0 and %d", MaxCompressionLevel)
+	}
+	if event.DictionarySize != "" {
+		if defaultIfEmpty(event.Format, DefaultFormat) != "7z" {
+			return fmt.Errorf("dictionarySize is only supported for 7z format")
+		}
+		if !dictionarySizePattern.MatchString(event.DictionarySize) {
+			return fmt.Errorf("invalid dictionarySize: %s (expected e.g. 64m, 1g)", event.DictionarySize)
+		}
+	}
+	if event.Password != "" && defaultIfEmpty(event.Format, DefaultFormat) != "7z" {
+		return fmt.Errorf("password is only supported for 7z format")
+	}
+	if err := validateVolumeSize(event); err != nil {
+		return err
+	}
+	if err := validateSolid(event); err != nil {
+		return err
+	}
+	if event.Threads < 0 {
+		return fmt.Errorf("threads must not be negative")
+	}
+	return nil
+}
+
+// 분할 압축 옵션 검사 (7z 포맷, 임시 파일 모드에서만 지원)
+func validateVolumeSize(event FileCompressionForm) error {
+	if event.VolumeSize == "" {
+		return nil
+	}
+	if !dictionarySizePattern.MatchString(event.VolumeSize) {
+		return fmt.Errorf("invalid volumeSize: %s (expected e.g. 100m, 1g)", event.VolumeSize)
+	}
+	if defaultIfEmpty(event.Format, DefaultFormat) != "7z" {
+		return fmt.Errorf("volumeSize is only supported for 7z format")
+	}
+	if event.StreamMode {
+		return fmt.Errorf("volumeSize is not supported in stream mode")
+	}
+	if event.SkipIfLarger {
+		return fmt.Errorf("volumeSize cannot be used with skipIfLarger")
+	}
+	if event.Operation == OperationDecompress {
+		return fmt.Errorf("volumeSize is not supported for decompress")
+	}
+	return nil
+}
+
+// 솔리드 압축 설정 검사 (7z 아카이브 전용, 무압축(Copy) 모드에서는 의미가 없으므로 거부)
+func validateSolid(event FileCompressionForm) error {
+	if event.Solid == "" {
+		return nil
+	}
+	switch {
+	case defaultIfEmpty(event.Format, DefaultFormat) != "7z":
+		return fmt.Errorf("solid is only supported for 7z format")
+	case event.Mode == ModeIndividual:
+		return fmt.Errorf("solid is not supported in %s mode", ModeIndividual)
+	case event.CompressionLevel == 0 && !event.AutoLevel:
+		return fmt.Errorf("solid cannot be used with compressionLevel 0 (Copy)")
+	case event.Operation == OperationDecompress:
+		return fmt.Errorf("solid is not supported for decompress")
+	case event.CopyOnly:
+		return fmt.Errorf("solid cannot be used with copyOnly")
+	}
+	switch strings.ToLower(event.Solid) {
+	case "on", "off":
+		return nil
+	}
+	if !solidBlockPattern.MatchString(event.Solid) {
+		return fmt.Errorf("invalid solid: %s (expected on, off or a block size e.g. 64m)", event.Solid)
+	}
+	return nil
+}
+
+// 여러 원본 키를 하나의 아카이브로 묶는 요청 검사
+func validateOriginKeys(event FileCompressionForm) error {
+	if event.OriginBucket == "" {
+		return fmt.Errorf("origin bucket required")
+	}
+	if event.OriginKey != "" {
+		return fmt.Errorf("originKey and originKeys cannot be used together")
+	}
+	if event.OriginVersionId != "" {
+		return fmt.Errorf("originVersionId is not supported with originKeys")
+	}
+	if event.Mode == ModeIndividual {
+		return validateIndividualKeys(event)
+	}
+	if event.TargetKey == "" && event.TargetKeyTemplate == "" {
+		return fmt.Errorf("targetKey or targetKeyTemplate is required when originKeys is set")
+	}
+	format := defaultIfEmpty(event.Format, DefaultFormat)
+	if f, ok := compressionFormats[format]; ok && !f.MultiFile {
+		return fmt.Errorf("format %s does not support multiple files", format)
+	}
+	if event.StreamMode {
+		return fmt.Errorf("stream mode is not supported with originKeys")
+	}
+	if event.PreserveMetadata {
+		return fmt.Errorf("preserveMetadata is not supported with originKeys")
+	}
+	if event.PreserveTimestamp {
+		return fmt.Errorf("preserveTimestamp is not supported with originKeys")
+	}
+	// 아카이브 내부에는 파일명만 저장되므로 파일명이 겹치면 안 됨
+	names := make(map[string]string, len(event.OriginKeys))
+	for _, key := range event.OriginKeys {
+		if key == "" {
+			return fmt.Errorf("originKeys must not contain empty keys")
+		}
+		name := filepath.Base(key)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("duplicate file name %s in originKeys: %s, %s", name, prev, key)
+		}
+		names[name] = key
+	}
+	return nil
+}
+
+// 접두사 아래 객체 전체를 하나의 아카이브로 묶는 요청 검사
+func validateOriginPrefix(event FileCompressionForm) error {
+	if event.OriginBucket == "" {
+		return fmt.Errorf("origin bucket required")
+	}
+	if event.OriginKey != "" || len(event.OriginKeys) > 0 {
+		return fmt.Errorf("originPrefix cannot be used with originKey or originKeys")
+	}
+	if event.TargetKey == "" && event.TargetKeyTemplate == "" {
+		return fmt.Errorf("targetKey or targetKeyTemplate is required when originPrefix is set")
+	}
+	format := defaultIfEmpty(event.Format, DefaultFormat)
+	if f, ok := compressionFormats[format]; ok && !f.MultiFile {
+		return fmt.Errorf("format %s does not support multiple files", format)
+	}
+	if event.StreamMode {
+		return fmt.Errorf("stream mode is not supported with originPrefix")
+	}
+	if event.PreserveMetadata {
+		return fmt.Errorf("preserveMetadata is not supported with originPrefix")
+	}
+	if event.PreserveTimestamp {
+		return fmt.Errorf("preserveTimestamp is not supported with originPrefix")
+	}
+	if event.OriginVersionId != "" {
+		return fmt.Errorf("originVersionId is not supported with originPrefix")
+	}
+	return nil
+}
+
+// 키가 .7z 또는 요청한 출력 포맷의 확장자로 끝나는지 확인 (대소문자 무시)
+func isAlreadyCompressed(key, format string) bool {
+	ext := strings.ToLower(filepath.Ext(key))
+	if ext == CompressExtension {
+		return true
+	}
+	f, ok := compressionFormats[defaultIfEmpty(format, DefaultFormat)]
+	return ok && ext == f.Extension
+}
+
+// 압축 해제 요청 검사: 단일 .7z 원본만 지원
+func validateDecompress(event FileCompressionForm) error {
+	if len(event.OriginKeys) > 0 || event.OriginPrefix != "" {
+		return fmt.Errorf("originKeys and originPrefix are not supported for decompress")
+	}
+	if !strings.HasSuffix(event.OriginKey, CompressExtension) {
+		return fmt.Errorf("decompress requires a %s origin key: %s", CompressExtension, event.OriginKey)
+	}
+	if event.Format != "" && event.Format != DefaultFormat {
+		return fmt.Errorf("decompress only supports %s format", DefaultFormat)
+	}
+	if event.StreamMode {
+		return fmt.Errorf("stream mode is not supported for decompress")
+	}
+	return nil
+}
+
+// 요청의 원본 키 목록 (OriginKeys가 없으면 OriginKey 하나, 접두사 요청은 이후 조회 결과로 채움)
+func resolveOriginKeys(event FileCompressionForm) []string {
+	if event.OriginPrefix != "" {
+		return nil
+	}
+	if event.OriginUrl != "" {
+		return []string{originURLName(event.OriginUrl)}
+	}
+	if len(event.OriginKeys) > 0 {
+		return event.OriginKeys
+	}
+	return []string{event.OriginKey}
+}
+
+// 접두사 아래의 객체 키 목록 조회 (ListObjectsV2 페이지 단위)
+// 폴더 표시용 객체("/"로 끝나는 키)는 제외하고, /tmp 고갈을 막기 위해 maxPrefixObjects개를 넘으면 실패
+func listPrefixKeys(ctx context.Context, client S3API, bucket, prefix string, exclude []excludePattern) ([]string, int, error) {
+	var keys []string
+	excluded := 0
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, 0, newCompressionError(ErrCodeDownload, fmt.Errorf("failed to list S3 objects: %w", err))
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			// 제외된 객체는 다운로드하지 않으므로 최대 객체 수에도 포함하지 않음
+			if isExcluded(key, prefix, exclude) {
+				excluded++
+				continue
+			}
+			if len(keys) >= maxPrefixObjects {
+				return nil, 0, newCompressionError(ErrCodeValidation, fmt.Errorf("prefix %s has more than %d objects", prefix, maxPrefixObjects))
+			}
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		if excluded > 0 {
+			return nil, 0, newCompressionError(ErrCodeValidation, fmt.Errorf("all %d objects under prefix %s/%s were excluded", excluded, bucket, prefix))
+		}
+		return nil, 0, newCompressionError(ErrCodeValidation, fmt.Errorf("no objects found under prefix: %s/%s", bucket, prefix))
+	}
+	return keys, excluded, nil
+}
+
+// 다중 키 모드에서 제외 패턴과 일치하는 키를 job.OriginKeys에서 제거
+func excludeOriginKeys(ctx context.Context, job *compressionJob, exclude []excludePattern) error {
+	if len(exclude) == 0 {
+		return nil
+	}
+	keys, excluded := filterExcludedKeys(job.OriginKeys, "", exclude)
+	if len(keys) == 0 {
+		return newCompressionError(ErrCodeValidation, fmt.Errorf("all %d origin keys were excluded", excluded))
+	}
+	loggerFrom(ctx).Info("Excluded origin keys", "stage", "validate", "objects", len(keys), "excluded", excluded)
+	job.OriginKeys = keys
+	return nil
+}
+
+// 서버 측 암호화 옵션 조합 검사
+// - SSEKmsKeyId만 지정: aws:kms 사용
+// - SSEAlgorithm "AES256": KMS 키와 함께 사용할 수 없음
+func validateEncryption(event FileCompressionForm) error {
+	switch s3types.ServerSideEncryption(event.SSEAlgorithm) {
+	case "", s3types.ServerSideEncryptionAwsKms, s3types.ServerSideEncryptionAwsKmsDsse:
+		return nil
+	case s3types.ServerSideEncryptionAes256:
+		if event.SSEKmsKeyId != "" {
+			return fmt.Errorf("sseKmsKeyId cannot be used with sseAlgorithm AES256")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported sseAlgorithm: %s", event.SSEAlgorithm)
+	}
+}
+
+// SDK에 정의된 S3 스토리지 클래스인지 검사 (비어있으면 STANDARD)
+func validateStorageClass(storageClass string) error {
+	if storageClass == "" || slices.Contains(s3types.StorageClass("").Values(), s3types.StorageClass(storageClass)) {
+		return nil
+	}
+	return fmt.Errorf("unsupported storageClass: %s", storageClass)
+}
+
+// 요청 값으로부터 업로드 옵션 생성 (ContentType은 포맷 기본값, 요청에 지정되어 있으면 해당 값)
+func buildUploadOptions(event FileCompressionForm, format compressionFormat, targetKey string) uploadOptions {
+	opts := uploadOptions{
+		SSEAlgorithm:        s3types.ServerSideEncryption(event.SSEAlgorithm),
+		SSEKmsKeyId:         event.SSEKmsKeyId,
+		VerifyChecksum:      event.VerifyChecksum,
+		ResumeUpload:        event.ResumeUpload,
+		ProcessUuid:         event.ProcessUuid,
+		ContentType:         format.ContentType,
+		ContentTypeOverride: event.ContentType,
+		StorageClass:        s3types.StorageClass(event.StorageClass),
+		Tagging:             buildTagging(event.Tags, event.ProcessUuid, !event.CopyOnly),
+		ContentDisposition:  contentDisposition(event.Disposition, targetKey),
+	}
+	if opts.SSEKmsKeyId != "" && opts.SSEAlgorithm == "" {
+		opts.SSEAlgorithm = s3types.ServerSideEncryptionAwsKms
+	}
+	// validateObjectLock에서 이미 검사했으므로 에러는 발생하지 않음
+	if event.ObjectLockMode != "" {
+		opts.ObjectLockMode = s3types.ObjectLockMode(event.ObjectLockMode)
+		opts.ObjectLockRetainUntil, _ = time.Parse(time.RFC3339, event.ObjectLockRetainUntil)
+	}
+	return opts
+}
+
+// 요청의 contentDisposition 값으로 Content-Disposition 헤더 값 생성
+// "attachment"면 대상 키의 파일명을 붙여 브라우저가 그 이름으로 저장하도록 함 (비ASCII 파일명은 RFC 2231 형식으로 인코딩)
+func contentDisposition(value, targetKey string) string {
+	if value != DispositionAttachment {
+		return value
+	}
+	if header := mime.FormatMediaType(DispositionAttachment, map[string]string{"filename": filepath.Base(targetKey)}); header != "" {
+		return header
+	}
+	return DispositionAttachment
+}
+
+// Content-Disposition 값 검사 (헤더에 넣을 수 없는 제어 문자 금지)
+func validateDisposition(value string) error {
+	if strings.ContainsFunc(value, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return fmt.Errorf("contentDisposition must not contain control characters")
+	}
+	return nil
+}
+
+// S3 버킷에서 파일을 다운로드하고 파일 크기와 메타데이터 반환 (byteRange가 있으면 해당 범위만)
+// hashAlgorithm이 있으면 파일에 쓰는 동안 같은 데이터로 해시를 계산하여 objectInfo.Hash로 반환 (추가로 읽지 않음)
+func downloadFromS3(ctx context.Context, client S3API, bucket, key, versionId string, byteRange *string, destPath, hashAlgorithm string) (objectInfo, error) {
+	f, err := os.Create(destPath)
+	if err != nil {
+		return objectInfo{}, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+
+	// 파일 다운로드
+	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIdParam(versionId),
+		Range:     byteRange,
+	})
+	if err != nil {
+		return objectInfo{}, fmt.Errorf("failed to get S3 object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 파일에 S3 데이터 복사 (로컬에 임시 저장)
+	body := newProgressReader(ctx, resp.Body, "download", key, aws.ToInt64(resp.ContentLength))
+	var dst io.Writer = f
+	h := newOriginHash(hashAlgorithm)
+	if h != nil {
+		dst = io.MultiWriter(f, h)
+	}
+	bytesWritten, err := copyBuffered(dst, body)
+	if err != nil {
+		return objectInfo{}, fmt.Errorf("failed to copy S3 data: %w", err)
+	}
+
+	return objectInfo{
+		Size:         bytesWritten,
+		ContentType:  aws.ToString(resp.ContentType),
+		Metadata:     resp.Metadata,
+		LastModified: aws.ToTime(resp.LastModified),
+		Hash:         hashDigest(h),
+	}, nil
+}
+
+// 동시에 실행할 수 있는 압축 프로세스(7za, Go gzip) 수 제한 (nil이면 제한 없음)
+// 웜 컨테이너에서 여러 요청이 동시에 압축하면 메모리/CPU가 고갈되어 OOM으로 종료될 수 있음
+var compressSlots chan struct{}
+
+// 압축 슬롯을 획득하고 반환 함수를 돌려줌 (슬롯이 없으면 대기, context 취소 시 에러)
+func acquireCompressSlot(ctx context.Context) (func(), error) {
+	if compressSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case compressSlots <- struct{}{}:
+		return func() { <-compressSlots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for compress slot: %w", ctx.Err())
+	}
+}
+
+// 압축 슬롯을 얻은 뒤 job에 선택된 압축 구현으로 압축 수행
+func compressFile(ctx context.Context, compressor Compressor, inputPaths []string, outputPath string, opts sevenZipOptions) error {
+	if err := injectFault("compress"); err != nil {
+		return err
+	}
+	release, err := acquireCompressSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return compressor.Compress(ctx, inputPaths, outputPath, opts)
+}
+
+// 7za 압축 1회 실행, 재시도로 복구될 수 있는 종료 코드면 retryableError로 감싸서 반환
+func runSevenZipCompress(ctx context.Context, args []string, opts sevenZipOptions) error {
+	// 7z 명령어 실행(미리 정의된 옵션 상수 기반으로) (7z 압축은 라이브러리가 아닌 바이너리로 실행)
+	// context가 취소되면(Lambda 타임아웃 임박 등) 7za 프로세스를 kill
+	cmd := exec.CommandContext(ctx, sevenZipCmd, args...)
+	cmd.Env = append(os.Environ(), "LANG=C") // 상세한 출력을 위해 환경변수 설정
+	var out []byte
+	var err error
+	if opts.Verbose {
+		out, err = runWithLiveOutput(ctx, cmd, "compress", opts)
+	} else {
+		out, err = cmd.CombinedOutput()
+	}
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		loggerFrom(ctx).Error("7za killed by context", "stage", "compress", errorAttr(ctxErr))
+		return fmt.Errorf("7za cancelled: %w", ctxErr)
+	}
+	exitCode := sevenZipExitCode(err)
+	if exitCode == SevenZipExitWarning && opts.TolerateWarnings {
+		loggerFrom(ctx).Warn("7za completed with warnings", "stage", "compress", "exitCode", exitCode, "output", opts.redactOutput(string(out)))
+		return nil
+	}
+	loggerFrom(ctx).Error("7za failed", "stage", "compress", "exitCode", exitCode, errorAttr(err), "output", opts.redactOutput(string(out)))
+	err = sevenZipError(err)
+	if isRecoverableSevenZipExit(exitCode) {
+		return &retryableError{err: err}
+	}
+	return err
+}
+
+// 7za 종료 코드 (7-Zip 문서의 Exit Codes)
+const (
+	SevenZipExitWarning     = 1   // 경고 (일부 입력 파일을 읽을 수 없음 등), 아카이브는 생성됨
+	SevenZipExitFatal       = 2   // 치명적 오류 (디스크 공간 부족 등 I/O 오류 포함)
+	SevenZipExitCommandLine = 7   // 잘못된 명령줄 인자
+	SevenZipExitMemory      = 8   // 메모리 부족
+	SevenZipExitUserStopped = 255 // 사용자가 중단
 )
 
-// static client map
-var (
-	s3Clients  = map[string]*s3.Client{}  // 리전별 S3 클라이언트 캐시
-	sqsClients = map[string]*sqs.Client{} // 리전별 SQS 클라이언트 캐시
-)
+// 7za 실행 에러의 종료 코드 (프로세스가 종료 코드를 남기지 않았으면 -1)
+func sevenZipExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// 7za 종료 코드를 설명이 담긴 메시지와 실패 유형 코드로 변환
+func sevenZipError(err error) error {
+	exitCode := sevenZipExitCode(err)
+	code, desc := ErrCodeCompress, "error"
+	switch exitCode {
+	case SevenZipExitWarning:
+		code, desc = ErrCodeCompressWarn, "completed with warnings (some input files may be missing or unreadable)"
+	case SevenZipExitFatal:
+		desc = "fatal error"
+	case SevenZipExitCommandLine:
+		code, desc = ErrCodeCompressArgs, "command line error"
+	case SevenZipExitMemory:
+		code, desc = ErrCodeOutOfMemory, "not enough memory"
+	case SevenZipExitUserStopped:
+		desc = "stopped"
+	}
+	if exitCode < 0 {
+		return newCompressionError(code, fmt.Errorf("7za %s: %w", desc, err))
+	}
+	return newCompressionError(code, fmt.Errorf("7za %s (exit code %d): %w", desc, exitCode, err))
+}
+
+// 7za 종료 코드 중 다시 실행하면 성공할 수 있는 것 (치명적 I/O 오류, 메모리 부족)
+// 경고(입력 파일 없음 등)와 잘못된 명령줄 인자는 다시 실행해도 같으므로 재시도하지 않음
+func isRecoverableSevenZipExit(code int) bool {
+	return code == SevenZipExitFatal || code == SevenZipExitMemory
+}
+
+// 재시도 전 이전 실행이 남긴 아카이브(분할 압축이면 볼륨 파일 포함) 삭제
+func removePartialOutput(outputPath string) {
+	_ = os.Remove(outputPath)
+	if volumes, err := filepath.Glob(outputPath + ".[0-9][0-9][0-9]*"); err == nil {
+		for _, volume := range volumes {
+			_ = os.Remove(volume)
+		}
+	}
+}
+
+// 아카이브에 파일이 정확히 1개인지 확인한 뒤 destDir에 풀고 해당 파일 경로 반환
+// 암호가 없어도 -p를 전달하여 암호화된 아카이브에서 7za가 입력 대기하지 않도록 함
+func extractFile(ctx context.Context, archivePath, destDir string, opts sevenZipOptions) (string, error) {
+	if _, err := os.Stat(sevenZipCmd); os.IsNotExist(err) {
+		return "", newCompressionError(ErrCodeCompress, fmt.Errorf("7za binary not found: %s", sevenZipCmd))
+	}
+	release, err := acquireCompressSlot(ctx)
+	if err != nil {
+		return "", newCompressionError(ErrCodeCompress, err)
+	}
+	defer release()
+	passFlag := SevenZipPassFlag + opts.Password
+
+	entries, err := listArchiveFiles(ctx, archivePath, passFlag, opts)
+	if err != nil {
+		return "", newCompressionError(ErrCodeCompress, err)
+	}
+	if len(entries) != 1 {
+		return "", newCompressionError(ErrCodeValidation, fmt.Errorf("archive must contain exactly one file, found %d", len(entries)))
+	}
+	if err := checkArchiveEntryPaths(entries); err != nil {
+		return "", newCompressionError(ErrCodeValidation, err)
+	}
+
+	// e: 디렉토리 구조 없이 추출, -y: 모든 질의에 yes
+	args := []string{"e", "-o" + destDir, "-y", passFlag, archivePath}
+	loggerFrom(ctx).Info("Running 7za", "stage", "decompress", "args", redactArgs(args))
+	cmd := exec.CommandContext(ctx, sevenZipCmd, args...)
+	cmd.Env = append(os.Environ(), "LANG=C")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", newCompressionError(ErrCodeCompress, fmt.Errorf("7za cancelled: %w", ctxErr))
+		}
+		exitCode := sevenZipExitCode(err)
+		if exitCode != SevenZipExitWarning || !opts.TolerateWarnings {
+			loggerFrom(ctx).Error("7za failed", "stage", "decompress", "exitCode", exitCode, errorAttr(err), "output", opts.redactOutput(string(out)))
+			return "", sevenZipError(err)
+		}
+		loggerFrom(ctx).Warn("7za completed with warnings", "stage", "decompress", "exitCode", exitCode, "output", opts.redactOutput(string(out)))
+	}
+	if err := checkExtractedFiles(destDir); err != nil {
+		loggerFrom(ctx).Error("Unsafe archive entry", "stage", "decompress", errorAttr(err))
+		return "", newCompressionError(ErrCodeValidation, err)
+	}
+	return filepath.Join(destDir, filepath.Base(entries[0])), nil
+}
+
+// 7za l -slt 출력에서 디렉토리를 제외한 파일 경로 목록 추출
+func listArchiveFiles(ctx context.Context, archivePath, passFlag string, opts sevenZipOptions) ([]string, error) {
+	cmd := exec.CommandContext(ctx, sevenZipCmd, "l", "-slt", passFlag, archivePath)
+	cmd.Env = append(os.Environ(), "LANG=C")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive: %w", err)
+	}
+	return parseArchiveListing(opts.redactOutput(string(out))), nil
+}
+
+// 7za l -slt 출력 파싱: "----------" 이후 빈 줄로 구분된 항목별 "Key = Value" 블록
+// 7-Zip 버전/포맷에 따라 Folder 줄이 없을 수 있으므로(gzip 등) Attributes의 D(디렉토리)도 확인
+func parseArchiveListing(output string) []string {
+	var files []string
+	var path string
+	folder, listing := false, false
+	flush := func() {
+		if path != "" && !folder {
+			files = append(files, path)
+		}
+		path, folder = "", false
+	}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "----------":
+			listing = true
+		case !listing:
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "Path = "):
+			flush()
+			path = strings.TrimPrefix(line, "Path = ")
+		case line == "Folder = +":
+			folder = true
+		case strings.HasPrefix(line, "Attributes = D"):
+			folder = true
+		}
+	}
+	flush()
+	return files
+}
+
+// context가 취소되면 읽기를 중단하는 Reader (Lambda 타임아웃 임박 시 압축 중단)
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// 스트리밍 모드의 장애 주입: 다운로드, 압축, 업로드 순으로 확인하여 해당 단계의 실패 코드로 반환
+func injectStreamFault() error {
+	stages := []struct{ stage, code string }{
+		{"download", ErrCodeDownload},
+		{"compress", ErrCodeCompress},
+		{"upload", ErrCodeUpload},
+	}
+	for _, s := range stages {
+		if err := injectFault(s.stage); err != nil {
+			return newCompressionError(s.code, err)
+		}
+	}
+	return nil
+}
+
+// S3 객체를 7za로 스트리밍 압축하여 바로 업로드 (원본 크기, 압축 크기 반환)
+// 출력 크기를 미리 알 수 없으므로 멀티파트 업로드(manager.Uploader) 사용
+func streamCompressS3(ctx context.Context, originClient, targetClient S3API, job compressionJob) (int64, int64, error) {
+	if _, err := os.Stat(sevenZipCmd); os.IsNotExist(err) {
+		return 0, 0, newCompressionError(ErrCodeCompress, fmt.Errorf("7za binary not found: %s", sevenZipCmd))
+	}
+	// 슬롯을 기다리는 동안 S3 연결을 열어두지 않도록 GetObject 전에 획득
+	release, err := acquireCompressSlot(ctx)
+	if err != nil {
+		return 0, 0, newCompressionError(ErrCodeCompress, err)
+	}
+	defer release()
+
+	resp, err := originClient.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(job.Event.OriginBucket),
+		Key:       aws.String(job.Event.OriginKey),
+		VersionId: versionIdParam(job.Event.OriginVersionId),
+		Range:     byteRangeParam(job.Event),
+	})
+	if err != nil {
+		return 0, 0, withErrorCode(ErrCodeDownload, describeSSECustomerError(fmt.Errorf("failed to get S3 object: %w", err), job.Event, job.Event.OriginKey))
+	}
+	defer resp.Body.Close()
+
+	args := []string{"a", job.Format.TypeFlag}
+	args = append(args, job.SevenZip.flags(job.Format)...)
+	// -si<이름>: 표준입력을 아카이브 내부 파일명으로 사용, -so: 결과를 표준출력으로 출력
+	args = append(args, "-si"+filepath.Base(job.Event.OriginKey), "-so", "stream"+job.Format.Extension)
+	loggerFrom(ctx).Info("Running 7za", "stage", "stream", "args", redactArgs(args))
+
+	source := &countingReader{r: resp.Body}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, sevenZipCmd, args...)
+	cmd.Env = append(os.Environ(), "LANG=C")
+	cmd.Stdin = source
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, 0, newCompressionError(ErrCodeCompress, fmt.Errorf("failed to open 7za stdout: %w", err))
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, 0, newCompressionError(ErrCodeCompress, fmt.Errorf("failed to start 7za: %w", err))
+	}
+
+	// 7za 출력이 끝나면(EOF) 프로세스 종료 코드를 확인하여, 실패 시 업로드도 실패하도록 함
+	var waitOnce sync.Once
+	var waitErr error
+	wait := func() error {
+		waitOnce.Do(func() {
+			if err := cmd.Wait(); err != nil {
+				loggerFrom(ctx).Error("7za failed", "stage", "stream", "exitCode", sevenZipExitCode(err), errorAttr(err), "output", job.SevenZip.redactOutput(stderr.String()))
+				waitErr = sevenZipError(err)
+			}
+		})
+		return waitErr
+	}
+	output := &countingReader{r: &waitOnEOFReader{r: stdout, wait: wait}}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(job.TargetBucket),
+		Key:    aws.String(job.TargetKey),
+		Body:   output,
+	}
+	uploadOpts := job.Upload
+	if job.Event.PreserveMetadata {
+		uploadOpts = uploadOpts.withOriginMetadata(objectInfo{
+			ContentType: aws.ToString(resp.ContentType),
+			Metadata:    resp.Metadata,
+		}, job.Event.OriginKey)
+	}
+	if job.Event.PreserveTimestamp {
+		uploadOpts = uploadOpts.withOriginTimestamp(aws.ToTime(resp.LastModified))
+	}
+	uploadOpts.apply(input)
+	_, err = manager.NewUploader(targetClient).Upload(ctx, input)
+	if err != nil {
+		// 7za가 먼저 실패하여 업로드가 중단된 경우 압축 에러로 보고
+		if waitErr != nil {
+			return 0, 0, waitErr
+		}
+		// 업로드 실패 시 7za가 출력 대기 상태로 남지 않도록 종료
+		_ = cmd.Process.Kill()
+		_ = wait()
+		return 0, 0, newCompressionError(ErrCodeUpload, fmt.Errorf("failed to upload S3 stream: %w", err))
+	}
+	if err := wait(); err != nil {
+		return 0, 0, err
+	}
+
+	return source.n, output.n, nil
+}
+
+// S3 객체를 Go 압축 구현으로 스트리밍 압축하여 바로 업로드 (원본 크기, 압축 크기 반환)
+// 압축 Writer의 출력을 io.Pipe로 업로더에 연결하여 /tmp를 전혀 사용하지 않음
+func streamCompressNative(ctx context.Context, originClient, targetClient S3API, job compressionJob, sc StreamCompressor) (int64, int64, error) {
+	release, err := acquireCompressSlot(ctx)
+	if err != nil {
+		return 0, 0, newCompressionError(ErrCodeCompress, err)
+	}
+	defer release()
 
-// Lambda Request 구조체
-type FileCompressionForm struct {
-	ProcessUuid    string `json:"processUuid"`
-	OriginRegion   string `json:"originRegion"`
-	OriginBucket   string `json:"originBucket"`
-	OriginKey      string `json:"originKey"`
-	TargetRegion   string `json:"targetRegion"`
-	TargetBucket   string `json:"targetBucket"`
-	TargetKey      string `json:"targetKey"`
-	DeleteOriginal bool   `json:"deleteOriginal"`
-	QueueRegion    string `json:"queueRegion"`
-	QueueUrl       string `json:"queueUrl"`
+	resp, err := originClient.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(job.Event.OriginBucket),
+		Key:       aws.String(job.Event.OriginKey),
+		VersionId: versionIdParam(job.Event.OriginVersionId),
+		Range:     byteRangeParam(job.Event),
+	})
+	if err != nil {
+		return 0, 0, withErrorCode(ErrCodeDownload, describeSSECustomerError(fmt.Errorf("failed to get S3 object: %w", err), job.Event, job.Event.OriginKey))
+	}
+	defer resp.Body.Close()
+	loggerFrom(ctx).Info("Running native stream compression", "stage", "stream", "format", job.Event.Format, "level", job.SevenZip.Level)
+
+	source := &countingReader{r: resp.Body}
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		// Writer를 닫아 남은 압축 데이터를 모두 내보낸 뒤에 파이프를 닫아야 업로더가 완전한 결과를 받음
+		err := func() error {
+			zw, err := sc.NewWriter(pw, filepath.Base(job.Event.OriginKey), job.SevenZip)
+			if err != nil {
+				return err
+			}
+			if _, err := copyBuffered(zw, &contextReader{ctx: ctx, r: source}); err != nil {
+				zw.Close()
+				return err
+			}
+			return zw.Close()
+		}()
+		if err != nil {
+			err = newCompressionError(ErrCodeCompress, fmt.Errorf("stream compression failed: %w", err))
+		}
+		pw.CloseWithError(err)
+		done <- err
+	}()
+	output := &countingReader{r: pr}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(job.TargetBucket),
+		Key:    aws.String(job.TargetKey),
+		Body:   output,
+	}
+	uploadOpts := job.Upload
+	if job.Event.PreserveMetadata {
+		uploadOpts = uploadOpts.withOriginMetadata(objectInfo{
+			ContentType: aws.ToString(resp.ContentType),
+			Metadata:    resp.Metadata,
+		}, job.Event.OriginKey)
+	}
+	if job.Event.PreserveTimestamp {
+		uploadOpts = uploadOpts.withOriginTimestamp(aws.ToTime(resp.LastModified))
+	}
+	uploadOpts.apply(input)
+	_, err = manager.NewUploader(targetClient).Upload(ctx, input)
+	if err != nil {
+		// 업로드 실패 시 압축 goroutine이 파이프 쓰기에서 멈추지 않도록 읽기 쪽을 닫고 종료를 기다림
+		pr.CloseWithError(err)
+		// 압축이 먼저 실패하여 업로드가 중단된 경우 압축 에러로 보고
+		if compressErr := <-done; compressErr != nil && !errors.Is(compressErr, io.ErrClosedPipe) && !errors.Is(compressErr, err) {
+			return 0, 0, compressErr
+		}
+		return 0, 0, newCompressionError(ErrCodeUpload, fmt.Errorf("failed to upload S3 stream: %w", err))
+	}
+	if err := <-done; err != nil {
+		return 0, 0, err
+	}
+
+	return source.n, output.n, nil
 }
 
-// Result Response 구조체
-type CompressionResultData struct {
-	Result      string `json:"result"`
-	Message     string `json:"message"`
-	ProcessUuid string `json:"processUuid"`
-	Region      string `json:"region"`
-	Bucket      string `json:"bucket"`
-	Key         string `json:"key"`
+// 일정 바이트(progressInterval)마다 전송 진행률을 로그로 남기는 Reader
+type progressReader struct {
+	r       io.Reader
+	logger  *slog.Logger
+	total   int64
+	read    int64
+	nextLog int64
 }
 
-// 초기화: 환경 변수로부터 리전 받아서 S3/SQS 클라이언트 생성
-func init() {
-	s3Region := os.Getenv("DEFAULT_S3_REGION")
-	if s3Region == "" {
-		s3Region = getLambdaRegion()
-		log.Printf("[WARN] DEFAULT_S3_REGION not set, fallback to Lambda region: %s", s3Region)
+func newProgressReader(ctx context.Context, r io.Reader, stage, key string, total int64) *progressReader {
+	logger := loggerFrom(ctx).With("stage", stage, "key", key)
+	return &progressReader{r: r, logger: logger, total: total, nextLog: progressInterval}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if progressInterval > 0 && p.read >= p.nextLog {
+		if p.total > 0 {
+			p.logger.Info("Transfer progress", bytesAttr(p.read), "totalBytes", p.total, "percent", float64(p.read)*100/float64(p.total))
+		} else {
+			p.logger.Info("Transfer progress", bytesAttr(p.read))
+		}
+		p.nextLog = (p.read/progressInterval + 1) * progressInterval
 	}
-	s3Clients[s3Region] = createS3Client(s3Region)
+	return n, err
+}
 
-	sqsRegion := os.Getenv("DEFAULT_SQS_REGION")
-	if sqsRegion == "" {
-		sqsRegion = getLambdaRegion()
-		log.Printf("[WARN] DEFAULT_SQS_REGION not set, fallback to Lambda region: %s", sqsRegion)
+// 업로드 본문용: SDK가 길이 계산/재시도를 위해 Seek 할 수 있도록 원본 Seeker를 함께 노출
+type progressReadSeeker struct {
+	*progressReader
+	seeker io.Seeker
+}
+
+func (p *progressReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := p.seeker.Seek(offset, whence)
+	if err == nil {
+		p.read = pos
+		p.nextLog = progressInterval
+		if progressInterval > 0 {
+			p.nextLog = (pos/progressInterval + 1) * progressInterval
+		}
 	}
-	sqsClients[sqsRegion] = createSQSClient(sqsRegion)
+	return pos, err
 }
 
-// Lambda 엔트리 포인트 핸들러
-func Handler(ctx context.Context, event FileCompressionForm) (CompressionResultData, error) {
-	startTime := time.Now()
+// 복사 버퍼 재사용 (동시 다운로드마다 큰 버퍼를 새로 할당하지 않도록)
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
 
-	// request input 유효성 검사
-	if err := validateRequest(event); err != nil {
-		log.Printf("[ERROR] Invalid request: %v", err)
-		return buildErrorResult(event, err), err
+// io.Copy의 기본 32KB 대신 copyBufferSize 버퍼로 복사하여 큰 파일의 읽기/쓰기 호출 횟수를 줄임
+// (처리량 차이는 디스크와 페이지 캐시에 따라 다르므로 BenchmarkCopyBuffer로 측정, S3 응답 본문은 네트워크 속도가 상한)
+// dst/src가 io.ReaderFrom/io.WriterTo를 구현하면 CopyBuffer가 버퍼를 쓰지 않으므로 Writer/Reader만 노출
+func copyBuffered(dst io.Writer, src io.Reader) (int64, error) {
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+	return io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, *buf)
+}
+
+// 읽은 바이트 수를 기록하는 Reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// EOF 시점에 wait 함수를 호출하여 그 에러를 읽기 에러로 전달하는 Reader
+type waitOnEOFReader struct {
+	r    io.Reader
+	wait func() error
+}
+
+func (w *waitOnEOFReader) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	if err == io.EOF {
+		if werr := w.wait(); werr != nil {
+			return n, werr
+		}
 	}
+	return n, err
+}
 
-	// 기본값 설정 - 별도로 Target을 지정하지 않는 경우, Origin 값을 기본 값으로 사용, TargetKey가 비어있으면 OriginKey의 확장자를 7z 으로 변경하여 사용
-	originRegion := defaultIfEmpty(event.OriginRegion, getLambdaRegion())
-	targetRegion := defaultIfEmpty(event.TargetRegion, originRegion)
-	targetBucket := defaultIfEmpty(event.TargetBucket, event.OriginBucket)
-	targetKey := defaultIfEmpty(event.TargetKey, replaceExtension(event.OriginKey, CompressExtension))
+// 압축 레벨을 7za 옵션으로 변환 (0이면 기존 Copy 모드 유지, Copy 미지원 포맷은 7za 기본 레벨 사용)
+func compressionLevelFlag(format compressionFormat, level int) string {
+	if level <= 0 {
+		if !format.SupportsCopy {
+			return ""
+		}
+		return SevenZipCopyFlag
+	}
+	return fmt.Sprintf(SevenZipLevelFlag, level)
+}
 
-	// 임시 파일 경로 설정
-	inputPath, outputPath := buildTempPaths(event.OriginKey)
-	defer cleanupTemp(inputPath, outputPath)
+// 열린 파일을 처음부터 S3에 업로드하고 업로드된 파일 크기 반환
+func uploadToS3(ctx context.Context, client S3API, bucket, key string, f *os.File, opts uploadOptions) (int64, error) {
+	if err := injectFault("upload"); err != nil {
+		return 0, err
+	}
+	// 재시도 시 같은 파일 핸들을 다시 사용하므로 처음 위치로 되돌림
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to rewind source file: %w", err)
+	}
 
-	// 압축할 파일 다운로드
-	s3Client := getS3Client(originRegion)
-	start := time.Now()
-	originalSize, err := downloadFromS3(ctx, s3Client, event.OriginBucket, event.OriginKey, inputPath)
+	// 파일 크기 확인
+	fileInfo, err := f.Stat()
 	if err != nil {
-		log.Printf("[ERROR] Download failed: %v (duration: %s)", err, time.Since(start))
-		return buildErrorResult(event, err), err
+		return 0, fmt.Errorf("failed to get file info: %w", err)
 	}
-	log.Printf("Download success: %d bytes (duration: %s)", originalSize, time.Since(start))
+	fileSize := fileInfo.Size()
+	multipart := fileSize > multipartThreshold
 
-	// 파일 압축 수행
-	start = time.Now()
-	if err := compressFile(inputPath, outputPath); err != nil {
-		log.Printf("[ERROR] Compression failed: %v (duration: %s)", err, time.Since(start))
-		return buildErrorResult(event, err), err
+	// S3에 파일 업로드 (임계값을 넘는 파일은 멀티파트 업로드)
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   &progressReadSeeker{progressReader: newProgressReader(ctx, f, "upload", key, fileSize), seeker: f},
 	}
-	log.Printf("Compression success (duration: %s)", time.Since(start))
+	opts.apply(input)
 
-	// 압축된 파일 지정된 버킷에 업로드
-	s3Client = getS3Client(targetRegion)
-	start = time.Now()
-	compressedSize, err := uploadToS3(ctx, s3Client, targetBucket, targetKey, outputPath)
-	if err != nil {
-		log.Printf("[ERROR] Upload failed: %v (duration: %s)", err, time.Since(start))
-		return buildErrorResult(event, err), err
+	// 체크섬 검증: 단일 PUT은 로컬에서 계산한 SHA256을 전달하여 S3가 수신 데이터를 검증하도록 함
+	// 멀티파트는 파트별 체크섬만 지정 가능하므로 알고리즘만 지정하고, 업로드와 같은 파트 크기로 복합 체크섬을 미리 계산
+	var checksum string
+	partSize := multipartPartSize(fileSize)
+	if opts.VerifyChecksum {
+		input.ChecksumAlgorithm = s3types.ChecksumAlgorithmSha256
+		if multipart {
+			checksum, err = multipartSHA256(f, fileSize, partSize)
+		} else if checksum, err = fileSHA256(f); err == nil {
+			input.ChecksumSHA256 = aws.String(checksum)
+		}
+		if err != nil {
+			return 0, err
+		}
 	}
-	log.Printf("Upload success: %d bytes (duration: %s)", compressedSize, time.Since(start))
 
-	// 원본 삭제(선택 옵션)
-	if event.DeleteOriginal {
-		if err := deleteFromS3(ctx, s3Client, event.OriginBucket, event.OriginKey); err != nil {
-			log.Printf("[WARN] Failed to delete original file: %v", err)
+	if multipart {
+		loggerFrom(ctx).Info("Using multipart upload", "stage", "upload", bytesAttr(fileSize), "threshold", multipartThreshold, "partSize", partSize)
+		if opts.ResumeUpload {
+			err = uploadResumable(ctx, client, input, f, fileSize, partSize, opts.ProcessUuid)
 		} else {
-			log.Printf("Original file deleted: %s/%s", event.OriginBucket, event.OriginKey)
+			_, err = manager.NewUploader(client, func(u *manager.Uploader) {
+				u.PartSize = partSize
+			}).Upload(ctx, input)
 		}
+	} else {
+		_, err = client.PutObject(ctx, input)
 	}
-
-	result := CompressionResultData{
-		Result:      "SUCCEED",
-		Message:     "Compression succeeded",
-		Region:      targetRegion,
-		Bucket:      targetBucket,
-		Key:         targetKey,
-		ProcessUuid: event.ProcessUuid,
+	if err != nil {
+		return 0, fmt.Errorf("failed to put S3 object: %w", err)
 	}
 
-	// SQS로 결과 전송
-	if err := sendResultToQueue(event.QueueRegion, event.QueueUrl, result); err != nil {
-		log.Printf("[ERROR] Failed to send SQS message: %v", err)
-		return buildErrorResult(event, err), err
+	if opts.VerifyChecksum {
+		if err := verifyUploadedChecksum(ctx, client, bucket, key, checksum); err != nil {
+			return 0, err
+		}
 	}
 
-	log.Printf("File processing success (total time: %s)", time.Since(startTime))
-	return result, nil
+	return fileSize, nil
 }
 
-func defaultIfEmpty(value, def string) string {
-	if value == "" {
-		return def
+// 파일의 SHA256을 base64로 계산한 뒤 파일 위치를 처음으로 되돌림
+func fileSHA256(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := copyBuffered(h, f); err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
 	}
-	return value
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind source file: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
 
-func validateRequest(event FileCompressionForm) error {
-	if event.OriginBucket == "" || event.OriginKey == "" {
-		return fmt.Errorf("origin bucket and key required")
+// 업로드된 객체의 저장된 SHA256 체크섬을 조회하여 로컬 값과 비교 (멀티파트는 "-파트 수"가 붙은 복합 체크섬)
+func verifyUploadedChecksum(ctx context.Context, client S3API, bucket, key, expected string) error {
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: s3types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to head uploaded object: %w", err)
+	}
+	stored := aws.ToString(out.ChecksumSHA256)
+	if stored == "" {
+		return fmt.Errorf("checksum verification failed: no SHA256 checksum stored for %s/%s", bucket, key)
 	}
-	// 이미 압축된 파일인지 확인
-	if strings.HasSuffix(event.OriginKey, CompressExtension) {
-		return fmt.Errorf("file is already compressed")
+	if stored != expected {
+		return fmt.Errorf("checksum mismatch for %s/%s: expected %s, got %s", bucket, key, expected, stored)
 	}
+	loggerFrom(ctx).Info("Checksum verified", "stage", "upload", "checksum", stored)
 	return nil
 }
 
-// S3 버킷에서 파일을 다운로드하고 파일 크기 반환
-func downloadFromS3(ctx context.Context, client *s3.Client, bucket, key, destPath string) (int64, error) {
-	f, err := os.Create(destPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer f.Close()
+// 객체 존재 여부 확인 (404면 false)
+func objectExists(ctx context.Context, client S3API, bucket, key, versionId string) (bool, error) {
+	out, err := headObject(ctx, client, bucket, key, versionId)
+	return out != nil, err
+}
 
-	// 파일 다운로드
-	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+// HeadObject 결과 반환 (404면 nil)
+func headObject(ctx context.Context, client S3API, bucket, key, versionId string) (*s3.HeadObjectOutput, error) {
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIdParam(versionId),
 	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get S3 object: %w", err)
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to head S3 object: %w", err)
 	}
-	defer resp.Body.Close()
+	return out, nil
+}
 
-	// 파일에 S3 데이터 복사 (로컬에 임시 저장)
-	bytesWritten, err := io.Copy(f, resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("failed to copy S3 data: %w", err)
+// versionId가 있으면 해당 버전을 영구 삭제
+// 없으면 버전 관리 버킷에서는 삭제 마커만 추가되므로 원본 데이터는 이전 버전으로 남아 복구 가능
+func deleteFromS3(ctx context.Context, client S3API, bucket, key, versionId string) error {
+	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIdParam(versionId),
+	})
+	return err
+}
+
+// 빈 버전 ID는 요청에서 생략 (최신 버전 대상)
+func versionIdParam(versionId string) *string {
+	if versionId == "" {
+		return nil
 	}
+	return aws.String(versionId)
+}
 
-	return bytesWritten, nil
+// 요청의 바이트 범위를 GetObject Range 헤더 값으로 변환 (범위가 없으면 nil, RangeEnd가 없으면 끝까지)
+func byteRangeParam(event FileCompressionForm) *string {
+	if event.RangeStart == nil && event.RangeEnd == nil {
+		return nil
+	}
+	start := aws.ToInt64(event.RangeStart)
+	if event.RangeEnd == nil {
+		return aws.String(fmt.Sprintf("bytes=%d-", start))
+	}
+	return aws.String(fmt.Sprintf("bytes=%d-%d", start, *event.RangeEnd))
 }
 
-// 7za 바이너리 프로그램으로 압축 수행
-func compressFile(inputPath, outputPath string) error {
-	if _, err := os.Stat(SevenZipCmd); os.IsNotExist(err) {
-		return fmt.Errorf("7za binary not found")
+// 바이트 범위 옵션 검사 (일부만 압축하므로 원본 삭제, 원본 그대로 업로드하는 옵션과는 함께 사용할 수 없음)
+func validateByteRange(event FileCompressionForm) error {
+	if event.RangeStart == nil && event.RangeEnd == nil {
+		return nil
 	}
-	// 7z 명령어 실행(미리 정의된 옵션 상수 기반으로) (7z 압축은 라이브러리가 아닌 바이너리로 실행)
-	cmd := exec.Command(SevenZipCmd, "a", SevenZipFormatFlag, SevenZipCopyFlag, outputPath, inputPath)
-	cmd.Env = append(os.Environ(), "LANG=C") // 상세한 출력을 위해 환경변수 설정
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Printf("[ERROR] 7za failed: %v\n%s", err, out)
-		return fmt.Errorf("7za error: %w", err)
+	if event.OriginKey == "" || len(event.OriginKeys) > 0 || event.OriginPrefix != "" {
+		return fmt.Errorf("rangeStart/rangeEnd are only supported with a single originKey")
+	}
+	if event.Operation == OperationDecompress {
+		return fmt.Errorf("rangeStart/rangeEnd are not supported for decompress")
+	}
+	if event.DeleteOriginal || event.SkipIfLarger {
+		return fmt.Errorf("rangeStart/rangeEnd cannot be used with deleteOriginal or skipIfLarger")
+	}
+	start := aws.ToInt64(event.RangeStart)
+	if start < 0 {
+		return fmt.Errorf("rangeStart must not be negative")
+	}
+	if event.RangeEnd != nil && *event.RangeEnd < start {
+		return fmt.Errorf("rangeEnd %d must not be less than rangeStart %d", *event.RangeEnd, start)
 	}
-	log.Printf("7za compression successful")
 	return nil
 }
 
-// 파일을 S3에 업로드하고 업로드된 파일 크기 반환
-func uploadToS3(ctx context.Context, client *s3.Client, bucket, key, sourcePath string) (int64, error) {
-	f, err := os.Open(sourcePath)
+// HeadObject로 확인한 원본 크기 안에 바이트 범위가 있는지 확인하고 실제로 다운로드할 크기 반환
+func byteRangeSize(event FileCompressionForm, objectSize int64) (int64, error) {
+	start := aws.ToInt64(event.RangeStart)
+	end := objectSize - 1
+	if event.RangeEnd != nil {
+		end = *event.RangeEnd
+	}
+	if start >= objectSize || end >= objectSize {
+		return 0, newCompressionError(ErrCodeValidation, fmt.Errorf("byte range %d-%d is outside object size %d", start, end, objectSize))
+	}
+	return end - start + 1, nil
+}
+
+// 같은 큐로의 전송이 연속으로 실패하면 일정 시간 동안 전송을 시도하지 않고 바로 errCircuitOpen 반환
+// (잘못 설정되었거나 스로틀링 중인 큐에서 매 요청이 재시도 시간을 소모하지 않도록)
+// onSent는 전송에 성공한 뒤 호출 (nil 가능), 여러 레코드를 처리 중이면 결과를 모아 두었다가 처리가 끝난 뒤 배치로 전송
+func sendResultToQueue(ctx context.Context, event FileCompressionForm, queueUrl string, result CompressionResultData, onSent func()) error {
+	if err := injectFault("sqs"); err != nil {
+		return err
+	}
+	if batch := resultBatchFrom(ctx); batch != nil {
+		batch.add(event, queueUrl, result, onSent)
+		return nil
+	}
+	if !queueBreaker.allow(queueUrl, time.Now()) {
+		loggerFrom(ctx).Warn("Queue circuit breaker is open, skipping SQS send", "stage", "queue", "queueUrl", queueUrl)
+		return fmt.Errorf("skipped sending to %s: %w", queueUrl, errCircuitOpen)
+	}
+	// 대량 처리 시 SQS 스로틀링을 피하도록 QUEUE_SEND_RATE(초당 메시지 수)에 맞춰 대기
+	if err := queueLimiter.wait(ctx); err != nil {
+		return fmt.Errorf("waiting for queue send rate limit: %w", err)
+	}
+	client, err := sqsClientFor(event.QueueRegion, credentialsFromForm(event))
+	if err == nil {
+		err = sendResultMessage(context.Background(), client, queueUrl, event.MessageGroupId, event.MessageFormat, result)
+	}
+	if queueBreaker.record(queueUrl, err, time.Now()) {
+		loggerFrom(ctx).Warn("Queue circuit breaker opened after consecutive failures", "stage", "queue", "queueUrl", queueUrl, "cooldownMs", queueBreaker.cooldown.Milliseconds())
+	}
+	if err == nil && onSent != nil {
+		onSent()
+	}
+	return err
+}
+
+// 결과를 messageFormat 형식으로 직렬화하여 SQS 메시지로 전송
+func sendResultMessage(ctx context.Context, client SQSAPI, queueUrl, messageGroupId, messageFormat string, result CompressionResultData) error {
+	input, err := resultMessageInput(queueUrl, messageGroupId, messageFormat, result)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open source file: %w", err)
+		return err
 	}
-	defer f.Close()
+	_, err = client.SendMessage(ctx, input)
+	return err
+}
 
-	// 파일 크기 확인
-	fileInfo, err := f.Stat()
+// 결과 메시지 전송 요청 생성 (단건/배치 전송 공용)
+func resultMessageInput(queueUrl, messageGroupId, messageFormat string, result CompressionResultData) (*sqs.SendMessageInput, error) {
+	body, err := serializeResult(result, messageFormat, time.Now())
 	if err != nil {
-		return 0, fmt.Errorf("failed to get file info: %w", err)
+		return nil, err
 	}
-	fileSize := fileInfo.Size()
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueUrl),
+		MessageBody: aws.String(body),
+	}
+	// FIFO 큐는 MessageGroupId가 필수 (기본값: ProcessUuid)
+	// 중복 제거 ID는 ProcessUuid에 결과를 붙여 같은 요청의 SUCCEED/FAILED 메시지가 서로 중복 처리되지 않도록 함
+	if isFifoQueue(queueUrl) {
+		input.MessageGroupId = aws.String(defaultIfEmpty(messageGroupId, defaultIfEmpty(result.ProcessUuid, DefaultMessageGroupId)))
+		if result.ProcessUuid != "" {
+			input.MessageDeduplicationId = aws.String(result.ProcessUuid + "-" + result.Result)
+		}
+	}
+	return input, nil
+}
 
-	// S3에 파일 업로드
-	_, err = client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   f,
+// SNS 구독(SNS → SQS)으로 전달되는 메시지와 같은 형태의 알림 봉투
+// SNS를 통해 결과를 받는 소비자가 같은 파싱 코드로 처리할 수 있도록 사용
+type snsNotification struct {
+	Type      string `json:"Type"`
+	MessageId string `json:"MessageId"`
+	Message   string `json:"Message"` // 결과 JSON 문자열
+	Timestamp string `json:"Timestamp"`
+}
+
+// 결과 메시지 본문 생성 (raw: 결과 JSON 그대로, sns: 결과 JSON을 SNS 알림 봉투의 Message로 감쌈)
+func serializeResult(result CompressionResultData, messageFormat string, now time.Time) (string, error) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	if defaultIfEmpty(messageFormat, DefaultMessageFormat) != MessageFormatSNS {
+		return string(body), nil
+	}
+	envelope, err := json.Marshal(snsNotification{
+		Type:      "Notification",
+		MessageId: result.ProcessUuid + "-" + result.Result,
+		Message:   string(body),
+		Timestamp: now.UTC().Format("2006-01-02T15:04:05.000Z"),
 	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to put S3 object: %w", err)
+		return "", err
 	}
+	return string(envelope), nil
+}
 
-	return fileSize, nil
+func isFifoQueue(queueUrl string) bool {
+	return strings.HasSuffix(queueUrl, ".fifo")
 }
 
-func deleteFromS3(ctx context.Context, client *s3.Client, bucket, key string) error {
-	_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
-	return err
+// 7za 분할 압축 볼륨 번호 형식 (.001부터 시작)
+const firstVolumeSuffix = ".001"
+
+// 업로드할 로컬 파일과 대상 키
+type volumeFile struct {
+	Path string
+	Key  string
 }
 
-func sendResultToQueue(region, queueUrl string, result CompressionResultData) error {
-	client := getSQSClient(region)
-	body, err := json.Marshal(result)
+// 분할 압축으로 생성된 볼륨 파일(outputPath.001, .002, ...)을 번호 순으로 찾아 대상 키에 같은 번호를 붙임
+func listVolumeFiles(outputPath, targetKey string) ([]volumeFile, error) {
+	paths, err := filepath.Glob(outputPath + ".[0-9][0-9][0-9]*")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to find volume files: %w", err)
 	}
-	_, err = client.SendMessage(context.Background(), &sqs.SendMessageInput{
-		QueueUrl:    aws.String(queueUrl),
-		MessageBody: aws.String(string(body)),
-	})
-	return err
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no volume files produced for %s", filepath.Base(outputPath))
+	}
+	sort.Strings(paths)
+	volumes := make([]volumeFile, 0, len(paths))
+	for _, p := range paths {
+		volumes = append(volumes, volumeFile{Path: p, Key: targetKey + strings.TrimPrefix(p, outputPath)})
+	}
+	return volumes, nil
+}
+
+// 요청별 고유 작업 디렉토리 생성 (/tmp/job-<ProcessUuid>-<랜덤>, 오래 남은 디렉토리는 sweepStaleWorkDirs가 정리)
+// 웜 컨테이너에서 같은 파일명을 동시에 처리해도 임시 파일이 겹치지 않고, 정리는 디렉토리 하나만 삭제하면 됨
+// 같은 ProcessUuid로 재시도하거나 동시에 실행되어도 겹치지 않도록 랜덤 접미사는 유지
+func createWorkDir(processUuid string) (string, error) {
+	pattern := workDirPrefix + "*"
+	if name := workDirName(processUuid); name != "" {
+		pattern = workDirPrefix + name + "-*"
+	}
+	dir, err := os.MkdirTemp(tempDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create work directory: %w", err)
+	}
+	return dir, nil
 }
 
-// 입력 키로부터 /tmp 경로를 생성
-func buildTempPaths(originKey string) (string, string) {
-	fileName := filepath.Base(originKey)
+// 입력 키 목록으로부터 작업 디렉토리 아래의 경로를 생성 (출력 파일명은 첫 번째 키 기준)
+// 아카이브 내부에 파일명이 그대로 저장되므로 파일명 자체는 바꾸지 않음
+func buildTempPaths(workDir string, originKeys []string, extension string) ([]string, string) {
+	inputPaths := make([]string, 0, len(originKeys))
+	for _, key := range originKeys {
+		inputPaths = append(inputPaths, filepath.Join(workDir, filepath.Base(key)))
+	}
+	fileName := filepath.Base(originKeys[0])
 	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
-	inputPath := filepath.Join(TempDir, fileName)
-	outputPath := filepath.Join(TempDir, base+CompressExtension)
+	outputPath := filepath.Join(workDir, base+extension)
+
+	return inputPaths, outputPath
+}
 
-	return inputPath, outputPath
+// 접두사 기준 상대 경로를 유지한 다운로드 경로 생성 (상위 디렉토리도 함께 생성)
+// 키에 ".."가 있어도 root 밖으로 벗어나지 않도록 정리
+func buildPrefixPaths(root, prefix string, keys []string) ([]string, error) {
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		rel := filepath.Clean("/" + strings.TrimPrefix(key, prefix))
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", key, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
 }
 
 // 파일 확장자 변경 메서드
@@ -283,64 +2939,248 @@ func replaceExtension(key, newExtension string) string {
 	return key[:len(key)-len(ext)] + newExtension
 }
 
-// 임시 파일 삭제
-func cleanupTemp(paths ...string) {
+// 임시 파일/디렉토리 삭제 (디렉토리는 하위 파일까지 삭제)
+func cleanupTemp(ctx context.Context, paths ...string) {
 	for _, p := range paths {
-		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
-			log.Printf("[WARN] Failed to delete temp file %s: %v", p, err)
+		if err := os.RemoveAll(p); err != nil {
+			loggerFrom(ctx).Warn("Failed to delete temp file", "stage", "cleanup", "path", p, errorAttr(err))
+		}
+	}
+}
+
+// 의도적으로 처리하지 않은 요청의 결과(SKIPPED)를 성공 큐(SuccessQueueUrl, 없으면 QueueUrl)로 전송
+// 건너뛴 것은 실패가 아니므로 SQS 전송 실패는 로그만 남김 (드라이런은 전송하지 않음)
+func handleSkip(ctx context.Context, event FileCompressionForm, reason string, result CompressionResultData) (CompressionResultData, error) {
+	result.Result = ResultSkipped
+	result.Reason = reason
+	result.Passthrough = event.Passthrough
+	if queueUrl := defaultIfEmpty(event.SuccessQueueUrl, event.QueueUrl); queueUrl != "" && !event.DryRun {
+		if err := sendResultToQueue(ctx, event, queueUrl, result, nil); err != nil && !errors.Is(err, errCircuitOpen) {
+			loggerFrom(ctx).Error("Failed to send skip SQS message", "stage", "queue", errorAttr(err))
 		}
 	}
+	return result, nil
+}
+
+// 실패 결과를 생성하고 실패 큐(FailureQueueUrl, 없으면 QueueUrl)로 전송
+// SQS 전송 실패는 로그만 남기고 원래 에러를 그대로 반환
+func handleFailure(ctx context.Context, event FileCompressionForm, err error) (CompressionResultData, error) {
+	result := buildErrorResult(event, err)
+	queueUrl := defaultIfEmpty(event.FailureQueueUrl, event.QueueUrl)
+	if queueUrl == "" || event.DryRun {
+		return result, err
+	}
+	if sendErr := sendResultToQueue(ctx, event, queueUrl, result, nil); sendErr != nil && !errors.Is(sendErr, errCircuitOpen) {
+		loggerFrom(ctx).Error("Failed to send failure SQS message", "stage", "queue", errorAttr(sendErr))
+	}
+	return result, err
 }
 
 func buildErrorResult(event FileCompressionForm, err error) CompressionResultData {
 	return CompressionResultData{
-		Result:      "FAILED",
+		Result:      ResultFailed,
 		Message:     err.Error(),
 		Region:      event.OriginRegion,
 		Bucket:      event.OriginBucket,
 		Key:         event.OriginKey,
 		ProcessUuid: event.ProcessUuid,
+		ErrorCode:   errorCode(err),
+		Passthrough: event.Passthrough,
+	}
+}
+
+// 압축 결과를 CloudWatch 커스텀 메트릭으로 발행 (실패해도 처리 결과에는 영향 없음)
+func publishMetrics(ctx context.Context, job compressionJob, stats compressionStats) {
+	dimensions := []cwtypes.Dimension{
+		{Name: aws.String("Bucket"), Value: aws.String(job.TargetBucket)},
+		{Name: aws.String("Region"), Value: aws.String(job.TargetRegion)},
+	}
+	metric := func(name string, value float64, unit cwtypes.StandardUnit) cwtypes.MetricDatum {
+		return cwtypes.MetricDatum{
+			MetricName: aws.String(name),
+			Value:      aws.Float64(value),
+			Unit:       unit,
+			Dimensions: dimensions,
+		}
+	}
+
+	data := []cwtypes.MetricDatum{
+		metric("OriginalSize", float64(stats.OriginalSize), cwtypes.StandardUnitBytes),
+		metric("CompressedSize", float64(stats.CompressedSize), cwtypes.StandardUnitBytes),
+		metric("DownloadDuration", float64(stats.DownloadDuration.Milliseconds()), cwtypes.StandardUnitMilliseconds),
+		metric("CompressDuration", float64(stats.CompressDuration.Milliseconds()), cwtypes.StandardUnitMilliseconds),
+		metric("UploadDuration", float64(stats.UploadDuration.Milliseconds()), cwtypes.StandardUnitMilliseconds),
+	}
+	if stats.TempFreeBytes > 0 {
+		data = append(data, metric("TempFreeBytes", float64(stats.TempFreeBytes), cwtypes.StandardUnitBytes))
+	}
+	if stats.OriginalSize > 0 {
+		data = append(data, metric("CompressionRatio", compressionRatio(stats.CompressedSize, stats.OriginalSize), cwtypes.StandardUnitNone))
+	}
+
+	_, err := cloudWatchClient.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(metricsNamespace),
+		MetricData: data,
+	})
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to publish CloudWatch metrics", "stage", "metrics", errorAttr(err))
+	}
+}
+
+// 일시적인 AWS 오류(스로틀링, 5xx 등)에 대해 지수 백오프 + 지터로 재시도
+// Lambda 제한 시간을 넘겨서 재시도하지 않도록 context 데드라인을 확인
+func withRetry(ctx context.Context, operation string, maxRetries int, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= maxRetries || !isRetryableError(err) {
+			return err
+		}
+
+		delay := backoffDelay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			loggerFrom(ctx).Warn("Not retrying: not enough time before deadline", "stage", operation, errorAttr(err))
+			return err
+		}
+		loggerFrom(ctx).Warn("Operation failed, retrying", "stage", operation, "attempt", attempt+1, "maxAttempts", maxRetries+1, "delayMs", delay.Milliseconds(), errorAttr(err))
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// AWS 오류가 아니지만 재시도할 수 있는 오류 (7za 일시적 실패 등)
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var retryable *retryableError
+	if errors.As(err, &retryable) {
+		return true
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// 시도 횟수에 따른 지수 백오프 지연 시간 (full jitter)
+func backoffDelay(attempt int) time.Duration {
+	maxDelay := RetryBaseDelay << attempt
+	if maxDelay <= 0 || maxDelay > RetryMaxDelay {
+		maxDelay = RetryMaxDelay
+	}
+	return time.Duration(rand.Int64N(int64(maxDelay)))
+}
+
+// 정수형 환경 변수 조회 (없거나 잘못된 값이면 기본값 사용)
+func getEnvInt64(name string, def int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		slog.Warn("Invalid environment value, fallback to default", "stage", "init", "name", name, "value", value, "default", def)
+		return def
 	}
+	return n
 }
 
 func getLambdaRegion() string {
 	return os.Getenv("AWS_REGION")
 }
 
-func createS3Client(region string) *s3.Client {
+func createS3Client(region string) (*s3.Client, error) {
+	return createS3ClientWithRole(region, "", staticCredentials{})
+}
+
+// roleArn이 있으면 STS AssumeRole 자격 증명으로 S3 클라이언트 생성 (교차 계정 접근)
+// 정적 자격 증명이 있으면 기본 자격 증명 대신 사용 (AssumeRole 호출에도 사용)
+// 설정 오류는 프로세스를 종료하지 않고 에러로 반환하여 해당 요청만 실패하도록 함
+func createS3ClientWithRole(region, roleArn string, creds staticCredentials) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 config for region %s: %w", region, err)
+	}
+	creds.apply(&cfg)
+	if roleArn != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleArn)
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return s3.NewFromConfig(cfg, applyS3EndpointOptions), nil
+}
+
+// S3_ENDPOINT_URL, S3_FORCE_PATH_STYLE, S3_USE_ACCELERATE 설정 적용 (설정이 없으면 기본 동작)
+func applyS3EndpointOptions(o *s3.Options) {
+	if s3EndpointURL != "" {
+		o.BaseEndpoint = aws.String(s3EndpointURL)
+		o.UsePathStyle = s3UsePathStyle
+	}
+	o.UseAccelerate = s3UseAccelerate
+}
+
+func createSQSClient(region string, creds staticCredentials) (*sqs.Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
 	if err != nil {
-		log.Fatalf("[ERROR] Failed to load S3 config for region %s: %v", region, err)
+		return nil, fmt.Errorf("failed to load SQS config for region %s: %w", region, err)
 	}
-	return s3.NewFromConfig(cfg)
+	creds.apply(&cfg)
+	return sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		if sqsEndpointURL != "" {
+			o.BaseEndpoint = aws.String(sqsEndpointURL)
+		}
+	}), nil
 }
 
-func createSQSClient(region string) *sqs.Client {
+func createCloudWatchClient(region string) *cloudwatch.Client {
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
 	if err != nil {
-		log.Fatalf("[ERROR] Failed to load SQS config for region %s: %v", region, err)
+		fatal("Failed to load CloudWatch config", "stage", "init", "region", region, errorAttr(err))
 	}
-	return sqs.NewFromConfig(cfg)
+	return cloudwatch.NewFromConfig(cfg)
+}
+
+func getS3Client(region string) (*s3.Client, error) {
+	return getS3ClientWithRole(region, "", staticCredentials{})
 }
 
-func getS3Client(region string) *s3.Client {
-	if client, ok := s3Clients[region]; ok {
-		return client
+// 리전 + AssumeRole ARN (+ 정적 자격 증명) 조합별로 S3 클라이언트 캐시 (ARN이 없으면 리전만 키로 사용)
+func getS3ClientWithRole(region, roleArn string, creds staticCredentials) (*s3.Client, error) {
+	cacheKey := region
+	if roleArn != "" {
+		cacheKey = region + "|" + roleArn
+	}
+	if creds.isSet() {
+		cacheKey += "|" + creds.cacheKey()
 	}
-	client := createS3Client(region)
-	s3Clients[region] = client
-	return client
+
+	return cachedClient(&clientsMu, s3Clients, cacheKey, func() (*s3.Client, error) {
+		return createS3ClientWithRole(region, roleArn, creds)
+	})
 }
 
-func getSQSClient(region string) *sqs.Client {
-	if client, ok := sqsClients[region]; ok {
-		return client
+// 리전 (+ 정적 자격 증명) 조합별로 SQS 클라이언트 캐시
+func getSQSClient(region string, creds staticCredentials) (*sqs.Client, error) {
+	cacheKey := region
+	if creds.isSet() {
+		cacheKey = region + "|" + creds.cacheKey()
 	}
-	client := createSQSClient(region)
-	sqsClients[region] = client
-	return client
+
+	return cachedClient(&clientsMu, sqsClients, cacheKey, func() (*sqs.Client, error) {
+		return createSQSClient(region, creds)
+	})
 }
 
 func main() {
-	lambda.Start(Handler)
+	lambda.Start(EventHandler)
 }
@@ -0,0 +1,851 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const testQueueUrl = "https://sqs.us-east-1.amazonaws.com/123456789012/results"
+
+// 가짜 클라이언트로 처리할 기본 요청 (gzip은 Go 구현으로 압축하므로 7za가 필요 없음)
+func testEvent() FileCompressionForm {
+	return FileCompressionForm{
+		ProcessUuid:  "test-uuid",
+		OriginRegion: "us-east-1",
+		OriginBucket: "origin-bucket",
+		OriginKey:    "logs/app.log",
+		TargetBucket: "target-bucket",
+		Format:       "gzip",
+		QueueUrl:     testQueueUrl,
+	}
+}
+
+var testContent = bytes.Repeat([]byte("hello compression\n"), 1000)
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	return out
+}
+
+func TestProcessRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    func(*FileCompressionForm)
+		setup    func(*fakeS3, *fakeSQS)
+		wantCode string // 비어있으면 성공
+		check    func(t *testing.T, s3c *fakeS3, sqsc *fakeSQS, result CompressionResultData)
+	}{
+		{
+			name: "compresses and uploads",
+			check: func(t *testing.T, s3c *fakeS3, sqsc *fakeSQS, result CompressionResultData) {
+				if result.Key != "logs/app.gz" || result.Bucket != "target-bucket" {
+					t.Errorf("result target = %s/%s, want target-bucket/logs/app.gz", result.Bucket, result.Key)
+				}
+				obj := s3c.object("target-bucket", "logs/app.gz")
+				if obj == nil {
+					t.Fatal("compressed object was not uploaded")
+				}
+				if got := gunzip(t, obj.data); !bytes.Equal(got, testContent) {
+					t.Error("uploaded archive does not decompress to the original content")
+				}
+				if obj.contentType != "application/gzip" {
+					t.Errorf("content type = %q, want application/gzip", obj.contentType)
+				}
+				if result.OriginalSize != int64(len(testContent)) || result.CompressedSize != int64(len(obj.data)) {
+					t.Errorf("sizes = %d/%d, want %d/%d", result.OriginalSize, result.CompressedSize, len(testContent), len(obj.data))
+				}
+				if s3c.object("origin-bucket", "logs/app.log") == nil {
+					t.Error("original was deleted without deleteOriginal")
+				}
+				messages := sqsc.results(t)
+				if len(messages) != 1 || messages[0].Result != ResultSucceed || messages[0].Key != "logs/app.gz" {
+					t.Errorf("SQS messages = %+v, want one SUCCEED message for logs/app.gz", messages)
+				}
+			},
+		},
+		{
+			name:  "deletes original after sending result",
+			event: func(e *FileCompressionForm) { e.DeleteOriginal = true },
+			check: func(t *testing.T, s3c *fakeS3, sqsc *fakeSQS, result CompressionResultData) {
+				if s3c.object("origin-bucket", "logs/app.log") != nil {
+					t.Error("original was not deleted")
+				}
+				if len(s3c.deletes) != 1 || *s3c.deletes[0].Key != "logs/app.log" {
+					t.Errorf("deletes = %d, want one delete of logs/app.log", len(s3c.deletes))
+				}
+				if len(sqsc.sent) != 1 {
+					t.Errorf("SQS messages = %d, want 1", len(sqsc.sent))
+				}
+			},
+		},
+		{
+			name: "deleteOriginal rejects same origin and target",
+			event: func(e *FileCompressionForm) {
+				e.DeleteOriginal = true
+				e.TargetBucket = e.OriginBucket
+				e.TargetKey = e.OriginKey
+				e.ForceRecompress = true
+			},
+			wantCode: ErrCodeValidation,
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, _ CompressionResultData) {
+				if len(s3c.deletes) != 0 || len(s3c.puts) != 0 {
+					t.Errorf("puts/deletes = %d/%d, want none", len(s3c.puts), len(s3c.deletes))
+				}
+			},
+		},
+		{
+			// 버킷 이름은 전역 고유하므로 대상 리전이 달라도 같은 객체
+			name: "deleteOriginal rejects same origin and target in a different region",
+			event: func(e *FileCompressionForm) {
+				e.DeleteOriginal = true
+				e.TargetRegion = "ap-northeast-2"
+				e.TargetBucket = e.OriginBucket
+				e.TargetKey = e.OriginKey
+				e.ForceRecompress = true
+			},
+			wantCode: ErrCodeValidation,
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, _ CompressionResultData) {
+				if len(s3c.deletes) != 0 || len(s3c.puts) != 0 {
+					t.Errorf("puts/deletes = %d/%d, want none", len(s3c.puts), len(s3c.deletes))
+				}
+			},
+		},
+		{
+			name:     "invalid request",
+			event:    func(e *FileCompressionForm) { e.OriginKey = "" },
+			wantCode: ErrCodeValidation,
+		},
+		{
+			name:     "origin not found",
+			event:    func(e *FileCompressionForm) { e.OriginKey = "logs/missing.log" },
+			wantCode: ErrCodeOriginNotFound,
+		},
+		{
+			name: "origin access denied",
+			setup: func(s3c *fakeS3, _ *fakeSQS) {
+				s3c.errs["HeadObject"] = fakeResponseError(http.StatusForbidden, "AccessDenied")
+			},
+			wantCode: ErrCodeAccessDenied,
+		},
+		{
+			name: "target exists",
+			setup: func(s3c *fakeS3, _ *fakeSQS) {
+				s3c.putObject("target-bucket", "logs/app.gz", []byte("old"), "application/gzip")
+			},
+			wantCode: ErrCodeTargetExists,
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, _ CompressionResultData) {
+				if got := s3c.object("target-bucket", "logs/app.gz").data; string(got) != "old" {
+					t.Error("existing target was overwritten")
+				}
+			},
+		},
+		{
+			name:  "overwrite replaces target",
+			event: func(e *FileCompressionForm) { e.Overwrite = true },
+			setup: func(s3c *fakeS3, _ *fakeSQS) {
+				s3c.putObject("target-bucket", "logs/app.gz", []byte("old"), "application/gzip")
+			},
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, _ CompressionResultData) {
+				if got := gunzip(t, s3c.object("target-bucket", "logs/app.gz").data); !bytes.Equal(got, testContent) {
+					t.Error("target was not replaced")
+				}
+			},
+		},
+		{
+			name:  "download fails",
+			event: func(e *FileCompressionForm) { e.DeleteOriginal = true },
+			setup: func(s3c *fakeS3, _ *fakeSQS) {
+				s3c.errs["GetObject"] = errors.New("connection reset")
+			},
+			wantCode: ErrCodeDownload,
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, _ CompressionResultData) {
+				if len(s3c.deletes) != 0 {
+					t.Error("original deleted after failed download")
+				}
+			},
+		},
+		{
+			name:  "upload fails",
+			event: func(e *FileCompressionForm) { e.DeleteOriginal = true },
+			setup: func(s3c *fakeS3, _ *fakeSQS) {
+				s3c.errs["PutObject"] = errors.New("bucket is full")
+			},
+			wantCode: ErrCodeUpload,
+			check: func(t *testing.T, s3c *fakeS3, sqsc *fakeSQS, _ CompressionResultData) {
+				if s3c.object("origin-bucket", "logs/app.log") == nil {
+					t.Error("original deleted after failed upload")
+				}
+				messages := sqsc.results(t)
+				if len(messages) != 1 || messages[0].Result != ResultFailed || messages[0].ErrorCode != ErrCodeUpload {
+					t.Errorf("SQS messages = %+v, want one FAILED message with %s", messages, ErrCodeUpload)
+				}
+			},
+		},
+		{
+			name:  "already compressed is skipped",
+			event: func(e *FileCompressionForm) { e.OriginKey = "logs/app.log.gz" },
+			check: func(t *testing.T, s3c *fakeS3, _ *fakeSQS, result CompressionResultData) {
+				if result.Result != ResultSkipped || result.Reason != SkipReasonAlreadyCompressed {
+					t.Errorf("result = %s/%s, want SKIPPED/%s", result.Result, result.Reason, SkipReasonAlreadyCompressed)
+				}
+				if len(s3c.puts) != 0 {
+					t.Error("skipped request uploaded an object")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+			if tt.setup != nil {
+				tt.setup(s3c, sqsc)
+			}
+			useFakeClients(t, s3c, sqsc)
+			event := testEvent()
+			if tt.event != nil {
+				tt.event(&event)
+			}
+
+			result, err := processRequest(context.Background(), event)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("processRequest: %v", err)
+				}
+				if result.Result != ResultSucceed && result.Result != ResultSkipped {
+					t.Fatalf("result = %s, want success", result.Result)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("processRequest succeeded, want %s", tt.wantCode)
+				}
+				if got := errorCode(err); got != tt.wantCode || result.ErrorCode != tt.wantCode {
+					t.Fatalf("error code = %s (result %s), want %s: %v", got, result.ErrorCode, tt.wantCode, err)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, s3c, sqsc, result)
+			}
+		})
+	}
+}
+
+// data를 임시 파일로 쓰고 처음 위치로 연 파일 반환
+func writeTempFile(t *testing.T, data []byte) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func setMultipartThreshold(t *testing.T, threshold int64) {
+	t.Helper()
+	saved := multipartThreshold
+	multipartThreshold = threshold
+	t.Cleanup(func() { multipartThreshold = saved })
+}
+
+// 임계값을 넘는 파일만 멀티파트 업로드, 임계값 이하(같은 크기 포함)는 단일 PutObject
+func TestUploadToS3MultipartThreshold(t *testing.T) {
+	const threshold = 1024 * 1024
+	setMultipartThreshold(t, threshold)
+	tests := []struct {
+		name          string
+		size          int
+		wantMultipart bool
+		wantParts     int
+	}{
+		{"small file", 1024, false, 0},
+		{"at threshold", threshold, false, 0},
+		{"above threshold", 6 * 1024 * 1024, true, 2}, // 기본 파트 크기 5MiB
+		{"several parts", 11 * 1024 * 1024, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c := newFakeS3()
+			data := bytes.Repeat([]byte{'x'}, tt.size)
+			size, err := uploadToS3(context.Background(), s3c, "target-bucket", "out.gz", writeTempFile(t, data), uploadOptions{ContentType: "application/gzip"})
+			if err != nil {
+				t.Fatalf("uploadToS3: %v", err)
+			}
+			if size != int64(tt.size) {
+				t.Errorf("size = %d, want %d", size, tt.size)
+			}
+			if multipart := len(s3c.creates) > 0; multipart != tt.wantMultipart {
+				t.Errorf("multipart = %v, want %v", multipart, tt.wantMultipart)
+			}
+			if tt.wantMultipart && (len(s3c.puts) != 0 || len(s3c.parts) != tt.wantParts) {
+				t.Errorf("puts/parts = %d/%d, want 0/%d", len(s3c.puts), len(s3c.parts), tt.wantParts)
+			}
+			if !tt.wantMultipart && len(s3c.puts) != 1 {
+				t.Errorf("puts = %d, want 1", len(s3c.puts))
+			}
+			obj := s3c.object("target-bucket", "out.gz")
+			if obj == nil || !bytes.Equal(obj.data, data) || obj.contentType != "application/gzip" {
+				t.Error("uploaded object does not match the source file")
+			}
+		})
+	}
+}
+
+// 포맷별 기본 ContentType과 요청의 contentType 재정의가 업로드 요청에 반영되는지 확인
+func TestUploadContentType(t *testing.T) {
+	tests := []struct {
+		format   string
+		override string
+		want     string
+	}{
+		{"7z", "", "application/x-7z-compressed"},
+		{"zip", "", "application/zip"},
+		{"gzip", "", "application/gzip"},
+		{"zstd", "", "application/zstd"},
+		{"7z", "application/octet-stream", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.override, func(t *testing.T) {
+			event := testEvent()
+			event.Format = tt.format
+			event.ContentType = tt.override
+			opts := buildUploadOptions(event, compressionFormats[tt.format], "out")
+			input := &s3.PutObjectInput{}
+			opts.apply(input)
+			if got := aws.ToString(input.ContentType); got != tt.want {
+				t.Errorf("ContentType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// originVersionId가 원본 다운로드(GetObject)와 원본 삭제(DeleteObject)에 모두 전달되는지 확인
+func TestOriginVersionIdThreaded(t *testing.T) {
+	for _, versionId := range []string{"", "3HL4kqtJlcpXroDTDmJ"} {
+		t.Run("version="+versionId, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+			useFakeClients(t, s3c, sqsc)
+			event := testEvent()
+			event.OriginVersionId = versionId
+			event.DeleteOriginal = true
+
+			if _, err := processRequest(context.Background(), event); err != nil {
+				t.Fatalf("processRequest: %v", err)
+			}
+			if len(s3c.gets) != 1 || len(s3c.deletes) != 1 {
+				t.Fatalf("gets/deletes = %d/%d, want 1/1", len(s3c.gets), len(s3c.deletes))
+			}
+			if got := aws.ToString(s3c.gets[0].VersionId); got != versionId {
+				t.Errorf("GetObject VersionId = %q, want %q", got, versionId)
+			}
+			if got := aws.ToString(s3c.deletes[0].VersionId); got != versionId {
+				t.Errorf("DeleteObject VersionId = %q, want %q", got, versionId)
+			}
+			if versionId == "" && s3c.deletes[0].VersionId != nil {
+				t.Error("empty version id should be omitted from DeleteObject")
+			}
+		})
+	}
+}
+
+// 대소문자와 관계없이 .7z 또는 요청 포맷의 확장자로 끝나면 이미 압축된 것으로 판단
+func TestIsAlreadyCompressed(t *testing.T) {
+	tests := []struct {
+		key    string
+		format string
+		want   bool
+	}{
+		{"data/file.7z", "", true},
+		{"data/file.7Z", "", true},
+		{"data/file.7z", "gzip", true},
+		{"data/file.ZIP", "zip", true},
+		{"data/file.Zip", "zip", true},
+		{"data/file.GZ", "gzip", true},
+		{"data/file.zst", "zstd", true},
+		{"data/file.zip", "", false}, // 7z 출력에는 zip도 다시 압축
+		{"data/file.gz", "7z", false},
+		{"data/file.txt", "gzip", false},
+		{"data/7z", "", false},
+		{"data.7z/file", "", false},
+	}
+	for _, tt := range tests {
+		if got := isAlreadyCompressed(tt.key, tt.format); got != tt.want {
+			t.Errorf("isAlreadyCompressed(%q, %q) = %v, want %v", tt.key, tt.format, got, tt.want)
+		}
+	}
+}
+
+// 이미 압축된 확장자라도 forceRecompress면 건너뛰지 않고 압축
+func TestForceRecompress(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.LOG.GZ", testContent, "application/gzip")
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.OriginKey = "logs/app.LOG.GZ"
+	event.TargetKey = "logs/again.gz"
+	event.ForceRecompress = true
+
+	result, err := processRequest(context.Background(), event)
+	if err != nil || result.Result != ResultSucceed {
+		t.Fatalf("processRequest = %s, %v, want SUCCEED", result.Result, err)
+	}
+	if s3c.object("target-bucket", "logs/again.gz") == nil {
+		t.Error("forced recompression did not upload")
+	}
+}
+
+// 같은 ProcessUuid와 같은 파일명으로 동시에 처리해도 요청마다 다른 작업 디렉토리를 사용하고, 정리 후에는 남지 않음
+func TestTempPathsUniqueAcrossConcurrentRequests(t *testing.T) {
+	savedTemp := tempDir
+	tempDir = t.TempDir()
+	t.Cleanup(func() { tempDir = savedTemp })
+
+	const workers = 32
+	paths := make([]string, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			workDir, err := createWorkDir("same-uuid")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			inputs, output := buildTempPaths(workDir, []string{"a/data.csv"}, ".7z")
+			if filepath.Base(inputs[0]) != "data.csv" || filepath.Base(output) != "data.7z" {
+				errs[i] = fmt.Errorf("unexpected temp paths %v, %s", inputs, output)
+				return
+			}
+			// 각자 다른 내용을 쓰고 다시 읽어 다른 요청이 덮어쓰지 않았는지 확인
+			content := []byte(strconv.Itoa(i))
+			if err := os.WriteFile(inputs[0], content, 0o600); err != nil {
+				errs[i] = err
+				return
+			}
+			paths[i] = inputs[0]
+			got, err := os.ReadFile(inputs[0])
+			if err == nil && !bytes.Equal(got, content) {
+				err = fmt.Errorf("temp file %s was overwritten by another request", inputs[0])
+			}
+			errs[i] = err
+			cleanupTemp(context.Background(), workDir)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("worker %d: %v", i, err)
+		}
+		if seen[paths[i]] {
+			t.Errorf("temp path %s used by more than one request", paths[i])
+		}
+		seen[paths[i]] = true
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("%d work directories left after cleanup", len(entries))
+	}
+}
+
+// 동시 실행 수와 최대값을 기록하는 압축 구현 (release가 닫힐 때까지 끝나지 않음)
+type blockingCompressor struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingCompressor) Compress(ctx context.Context, inputPaths []string, outputPath string, opts sevenZipOptions) error {
+	c.mu.Lock()
+	c.running++
+	c.peak = max(c.peak, c.running)
+	c.mu.Unlock()
+	c.started <- struct{}{}
+	<-c.release
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	return nil
+}
+
+// MAX_COMPRESS_PROCESSES 슬롯 수보다 많은 압축을 동시에 요청해도 슬롯 수만큼만 실행되고, 대기 중 취소되면 에러 반환
+func TestCompressSlotsLimitConcurrency(t *testing.T) {
+	const slots, workers = 2, 6
+	savedSlots := compressSlots
+	compressSlots = make(chan struct{}, slots)
+	t.Cleanup(func() { compressSlots = savedSlots })
+
+	c := &blockingCompressor{started: make(chan struct{}, workers), release: make(chan struct{})}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := compressFile(context.Background(), c, nil, "", sevenZipOptions{}); err != nil {
+				t.Errorf("compressFile: %v", err)
+			}
+		}()
+	}
+	for i := 0; i < slots; i++ {
+		<-c.started
+	}
+	// 슬롯이 모두 사용 중이면 나머지는 시작하지 못함
+	select {
+	case <-c.started:
+		t.Fatal("compression started beyond the slot limit")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// 대기 중인 요청은 context 취소로 빠져나올 수 있음
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := compressFile(ctx, c, nil, "", sevenZipOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("compressFile while slots are full = %v, want deadline exceeded", err)
+	}
+
+	close(c.release)
+	wg.Wait()
+	if c.peak != slots {
+		t.Errorf("peak concurrent compressions = %d, want %d", c.peak, slots)
+	}
+}
+
+// 크기가 0인 원본: 기본값이면 경고 후 빈 아카이브를 만들고, rejectEmpty면 EMPTY_ORIGIN으로 실패
+func TestEmptyOrigin(t *testing.T) {
+	for _, rejectEmpty := range []bool{false, true} {
+		t.Run("rejectEmpty="+strconv.FormatBool(rejectEmpty), func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			s3c.putObject("origin-bucket", "logs/app.log", nil, "text/plain")
+			useFakeClients(t, s3c, sqsc)
+			event := testEvent()
+			event.RejectEmpty = rejectEmpty
+			event.DeleteOriginal = true
+
+			result, err := processRequest(context.Background(), event)
+			if rejectEmpty {
+				if errorCode(err) != ErrCodeEmptyOrigin || result.ErrorCode != ErrCodeEmptyOrigin {
+					t.Fatalf("error = %v (result code %s), want %s", err, result.ErrorCode, ErrCodeEmptyOrigin)
+				}
+				if len(s3c.puts) != 0 || len(s3c.deletes) != 0 {
+					t.Errorf("puts/deletes = %d/%d, want none for rejected empty origin", len(s3c.puts), len(s3c.deletes))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("processRequest: %v", err)
+			}
+			if result.OriginalSize != 0 {
+				t.Errorf("OriginalSize = %d, want 0", result.OriginalSize)
+			}
+			obj := s3c.object("target-bucket", "logs/app.gz")
+			if obj == nil || len(gunzip(t, obj.data)) != 0 {
+				t.Error("empty origin should produce an archive of empty content")
+			}
+		})
+	}
+}
+
+// raw는 결과 JSON 그대로, sns는 결과 JSON 문자열을 Message로 담은 SNS 알림 봉투로 직렬화
+func TestSerializeResult(t *testing.T) {
+	result := CompressionResultData{Result: ResultSucceed, Bucket: "b", Key: "k.7z", ProcessUuid: "uuid-1"}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.FixedZone("KST", 9*60*60))
+
+	for _, format := range []string{"", MessageFormatRaw} {
+		body, err := serializeResult(result, format, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded CompressionResultData
+		if err := json.Unmarshal([]byte(body), &decoded); err != nil || decoded.Key != "k.7z" || decoded.ProcessUuid != "uuid-1" {
+			t.Errorf("format %q: body %s does not decode to the result (%v)", format, body, err)
+		}
+	}
+
+	body, err := serializeResult(result, MessageFormatSNS, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope snsNotification
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		t.Fatalf("sns body is not an SNS envelope: %v", err)
+	}
+	if envelope.Type != "Notification" || envelope.MessageId != "uuid-1-SUCCEED" || envelope.Timestamp != "2024-05-01T03:00:00.123Z" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+	var inner CompressionResultData
+	if err := json.Unmarshal([]byte(envelope.Message), &inner); err != nil || inner.Key != "k.7z" || inner.Result != ResultSucceed {
+		t.Errorf("envelope Message %q does not decode to the result (%v)", envelope.Message, err)
+	}
+}
+
+// 업로드 후 결과 전송(SQS)이 실패하면 QUEUE_FAILED로 실패하고 원본은 삭제하지 않음
+func TestQueueFailureKeepsOriginal(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{err: errors.New("queue does not exist")}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.DeleteOriginal = true
+
+	result, err := processRequest(context.Background(), event)
+	if errorCode(err) != ErrCodeQueue || result.Result != ResultFailed {
+		t.Fatalf("processRequest = %s, %v, want %s", result.Result, err, ErrCodeQueue)
+	}
+	if s3c.object("target-bucket", "logs/app.gz") == nil {
+		t.Error("archive was not uploaded before the queue send")
+	}
+	if len(s3c.deletes) != 0 || s3c.object("origin-bucket", "logs/app.log") == nil {
+		t.Error("original was deleted although the result message was not sent")
+	}
+}
+
+// 로컬 파일 간 복사 처리량을 io.Copy 기본 버퍼(32KB)와 BufferSize(4MB)로 비교 (go test -run x -bench CopyBuffer)
+func BenchmarkCopyBuffer(b *testing.B) {
+	const size = 256 * 1024 * 1024
+	dir := b.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, bytes.Repeat([]byte{0x5a}, size), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	for _, bufSize := range []int{32 * 1024, BufferSize} {
+		b.Run(strconv.Itoa(bufSize/1024)+"KB", func(b *testing.B) {
+			buf := make([]byte, bufSize)
+			b.SetBytes(size)
+			for b.Loop() {
+				in, err := os.Open(src)
+				if err != nil {
+					b.Fatal(err)
+				}
+				out, err := os.Create(filepath.Join(dir, "dst"))
+				if err != nil {
+					b.Fatal(err)
+				}
+				// copyBuffered와 같이 ReaderFrom/WriterTo를 숨겨 버퍼를 실제로 사용하게 함
+				if _, err := io.CopyBuffer(struct{ io.Writer }{out}, struct{ io.Reader }{in}, buf); err != nil {
+					b.Fatal(err)
+				}
+				in.Close()
+				out.Close()
+			}
+		})
+	}
+}
+
+// minAgeHours 경계 주변의 LastModified: 기준보다 최근이면 SKIPPED(TOO_YOUNG), 지났으면 압축
+// 여러 원본이면 가장 최근에 수정된 객체 기준
+func TestMinAgeHours(t *testing.T) {
+	const minAge = 2 * time.Hour
+	tests := []struct {
+		name     string
+		ages     []time.Duration
+		wantSkip bool
+	}{
+		{"just younger", []time.Duration{minAge - time.Minute}, true},
+		{"just older", []time.Duration{minAge + time.Minute}, false},
+		{"much older", []time.Duration{30 * 24 * time.Hour}, false},
+		{"modified in the future", []time.Duration{-time.Hour}, true},
+		{"one of many is young", []time.Duration{minAge + time.Hour, minAge - time.Minute}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c, sqsc := newFakeS3(), &fakeSQS{}
+			event := testEvent()
+			event.MinAgeHours = int(minAge / time.Hour)
+			event.OriginKey = ""
+			for i, age := range tt.ages {
+				key := "logs/part" + strconv.Itoa(i) + ".log"
+				s3c.putObject("origin-bucket", key, testContent, "text/plain").lastModified = time.Now().Add(-age)
+				event.OriginKeys = append(event.OriginKeys, key)
+			}
+			if len(event.OriginKeys) == 1 {
+				event.OriginKey, event.OriginKeys = event.OriginKeys[0], nil
+			} else {
+				// 건너뛰는 경우만 있으므로 7za 없이도 압축 전에 끝남
+				event.Format = "7z"
+				event.TargetKey = "logs/parts.7z"
+			}
+			useFakeClients(t, s3c, sqsc)
+
+			result, err := processRequest(context.Background(), event)
+			if tt.wantSkip {
+				if err != nil || result.Result != ResultSkipped || result.Reason != SkipReasonTooYoung {
+					t.Fatalf("processRequest = %s/%s, %v, want SKIPPED/%s", result.Result, result.Reason, err, SkipReasonTooYoung)
+				}
+				if len(s3c.gets) != 0 || len(s3c.puts) != 0 {
+					t.Error("young origin was downloaded or uploaded")
+				}
+				return
+			}
+			if err != nil || result.Result != ResultSucceed {
+				t.Fatalf("processRequest = %s, %v, want SUCCEED", result.Result, err)
+			}
+		})
+	}
+}
+
+// 다른 리전의 버킷에 요청했을 때의 S3 리다이렉트 에러 (X-Amz-Bucket-Region 헤더 포함)
+func bucketRedirectError(region string) error {
+	err := fakeResponseError(http.StatusMovedPermanently, "PermanentRedirect")
+	if region != "" {
+		err.(*awshttp.ResponseError).Response.Header.Set("X-Amz-Bucket-Region", region)
+	}
+	return err
+}
+
+func TestBucketRegionFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantRegion string
+		wantOK     bool
+	}{
+		{"region header", bucketRedirectError("eu-west-1"), "eu-west-1", true},
+		{"expecting message", fakeResponseError(http.StatusBadRequest, "AuthorizationHeaderMalformed: the region 'us-east-1' is wrong; expecting 'ap-northeast-2'"), "ap-northeast-2", true},
+		{"redirect without region", bucketRedirectError(""), "", false},
+		{"other response error", fakeResponseError(http.StatusForbidden, "AccessDenied"), "", false},
+		{"not a response error", errors.New("expecting 'us-west-2'"), "", false},
+		{"nil", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			region, ok := bucketRegionFromError(tt.err)
+			if region != tt.wantRegion || ok != tt.wantOK {
+				t.Errorf("bucketRegionFromError = %q, %v, want %q, %v", region, ok, tt.wantRegion, tt.wantOK)
+			}
+		})
+	}
+	// 이미 같은 리전이면 리전 불일치가 아님
+	if err := regionMismatchError(bucketRedirectError("us-east-1"), "origin-bucket", "us-east-1"); err != nil {
+		t.Errorf("regionMismatchError for same region = %v, want nil", err)
+	}
+}
+
+// 원본 버킷이 다른 리전이면 HeadObject 단계에서 WRONG_REGION으로 실패하고 실제 리전을 안내
+func TestOriginWrongRegion(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	s3c.errs["HeadObject"] = bucketRedirectError("eu-west-1")
+	useFakeClients(t, s3c, sqsc)
+
+	result, err := processRequest(context.Background(), testEvent())
+	if got := errorCode(err); got != ErrCodeWrongRegion || result.ErrorCode != ErrCodeWrongRegion {
+		t.Fatalf("error code = %q (result %q), want %s: %v", got, result.ErrorCode, ErrCodeWrongRegion, err)
+	}
+	if !strings.Contains(err.Error(), "set originRegion to eu-west-1") {
+		t.Errorf("error %q does not name the actual region", err)
+	}
+	if len(s3c.gets) != 0 {
+		t.Error("origin was downloaded despite the region mismatch")
+	}
+}
+
+func TestWithOriginTimestamp(t *testing.T) {
+	kst := time.FixedZone("KST", 9*60*60)
+	base := uploadOptions{Metadata: map[string]string{"owner": "team-a"}}
+
+	opts := base.withOriginTimestamp(time.Date(2024, 3, 1, 9, 30, 15, 500, kst))
+	if got, want := opts.Metadata["original-last-modified"], "2024-03-01T00:30:15Z"; got != want {
+		t.Errorf("original-last-modified = %q, want %q", got, want)
+	}
+	if opts.Metadata["owner"] != "team-a" {
+		t.Error("existing metadata was dropped")
+	}
+	if _, ok := base.Metadata["original-last-modified"]; ok {
+		t.Error("withOriginTimestamp modified the receiver's metadata")
+	}
+	if opts := base.withOriginTimestamp(time.Time{}); len(opts.Metadata) != 1 {
+		t.Errorf("zero time added metadata: %v", opts.Metadata)
+	}
+}
+
+// preserveTimestamp면 원본 LastModified가 RFC3339로 대상 메타데이터에 저장됨
+func TestPreserveTimestamp(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	lastModified := time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain").lastModified = lastModified
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.PreserveTimestamp = true
+
+	if _, err := processRequest(context.Background(), event); err != nil {
+		t.Fatalf("processRequest: %v", err)
+	}
+	target := s3c.object("target-bucket", "logs/app.gz")
+	if target == nil {
+		t.Fatal("target was not uploaded")
+	}
+	got := target.metadata["original-last-modified"]
+	if parsed, err := time.Parse(time.RFC3339, got); err != nil || !parsed.Equal(lastModified) {
+		t.Errorf("original-last-modified = %q, want %s", got, lastModified.Format(time.RFC3339))
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		value, targetKey, want string
+	}{
+		{"", "logs/app.gz", ""},
+		{"inline", "logs/app.gz", "inline"},
+		{`attachment; filename="custom.gz"`, "logs/app.gz", `attachment; filename="custom.gz"`},
+		{DispositionAttachment, "logs/2024/app.gz", "attachment; filename=app.gz"},
+		{DispositionAttachment, "logs/my app.gz", `attachment; filename="my app.gz"`},
+		{DispositionAttachment, "logs/로그.gz", "attachment; filename*=utf-8''%EB%A1%9C%EA%B7%B8.gz"},
+	}
+	for _, tt := range tests {
+		if got := contentDisposition(tt.value, tt.targetKey); got != tt.want {
+			t.Errorf("contentDisposition(%q, %q) = %q, want %q", tt.value, tt.targetKey, got, tt.want)
+		}
+	}
+}
+
+// contentDisposition이 attachment면 업로드 요청에 대상 파일명이 들어간 Content-Disposition이 설정됨
+func TestUploadContentDisposition(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+	event := testEvent()
+	event.Disposition = DispositionAttachment
+
+	if _, err := processRequest(context.Background(), event); err != nil {
+		t.Fatalf("processRequest: %v", err)
+	}
+	if len(s3c.puts) != 1 {
+		t.Fatalf("PutObject called %d times, want 1", len(s3c.puts))
+	}
+	if got, want := aws.ToString(s3c.puts[0].ContentDisposition), "attachment; filename=app.gz"; got != want {
+		t.Errorf("ContentDisposition = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// 객체 잠금 보존을 요청했지만 대상 버킷에 객체 잠금이 활성화되어 있지 않은 경우
+const ErrCodeObjectLock = "OBJECT_LOCK_NOT_ENABLED"
+
+// 객체 잠금 옵션 검사: 모드와 보존 만료 시각(RFC3339, 미래 시각)을 함께 지정해야 함
+func validateObjectLock(event FileCompressionForm) error {
+	if event.ObjectLockMode == "" && event.ObjectLockRetainUntil == "" {
+		return nil
+	}
+	if event.ObjectLockMode == "" || event.ObjectLockRetainUntil == "" {
+		return fmt.Errorf("objectLockMode and objectLockRetainUntil must be set together")
+	}
+	switch s3types.ObjectLockMode(event.ObjectLockMode) {
+	case s3types.ObjectLockModeGovernance, s3types.ObjectLockModeCompliance:
+	default:
+		return fmt.Errorf("unsupported objectLockMode: %s (expected GOVERNANCE or COMPLIANCE)", event.ObjectLockMode)
+	}
+	retainUntil, err := time.Parse(time.RFC3339, event.ObjectLockRetainUntil)
+	if err != nil {
+		return fmt.Errorf("invalid objectLockRetainUntil: %s (expected RFC3339, e.g. 2030-01-01T00:00:00Z)", event.ObjectLockRetainUntil)
+	}
+	if !retainUntil.After(time.Now()) {
+		return fmt.Errorf("objectLockRetainUntil must be in the future: %s", event.ObjectLockRetainUntil)
+	}
+	return nil
+}
+
+// 대상 버킷에 객체 잠금이 활성화되어 있는지 확인 (업로드 전에 확인하여 압축 후 PutObject에서 실패하지 않도록 함)
+func checkTargetObjectLock(ctx context.Context, client S3API, bucket string) error {
+	out, err := client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+	if isObjectLockNotConfigured(err) || err == nil && (out.ObjectLockConfiguration == nil || out.ObjectLockConfiguration.ObjectLockEnabled != s3types.ObjectLockEnabledEnabled) {
+		return newCompressionError(ErrCodeObjectLock, fmt.Errorf("target bucket %s does not have object lock enabled", bucket))
+	}
+	if isAccessDenied(err) {
+		return newCompressionError(ErrCodeAccessDenied, fmt.Errorf("access denied to object lock configuration of target bucket %s (s3:GetBucketObjectLockConfiguration required)", bucket))
+	}
+	if err != nil {
+		return newCompressionError(ErrCodeUpload, fmt.Errorf("failed to get object lock configuration: %w", err))
+	}
+	return nil
+}
+
+// 객체 잠금 설정이 없는 버킷에 대한 404 ObjectLockConfigurationNotFoundError인지 확인
+func isObjectLockNotConfigured(err error) bool {
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound && strings.Contains(err.Error(), "ObjectLockConfigurationNotFound")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestValidateObjectLock(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name        string
+		mode, until string
+		wantErr     bool
+	}{
+		{"not set", "", "", false},
+		{"governance", "GOVERNANCE", future, false},
+		{"compliance", "COMPLIANCE", future, false},
+		{"mode only", "GOVERNANCE", "", true},
+		{"retainUntil only", "", future, true},
+		{"lowercase mode", "governance", future, true},
+		{"unknown mode", "LEGAL_HOLD", future, true},
+		{"not RFC3339", "GOVERNANCE", "2030-01-01", true},
+		{"in the past", "COMPLIANCE", past, true},
+	}
+	for _, tt := range tests {
+		err := validateObjectLock(FileCompressionForm{ObjectLockMode: tt.mode, ObjectLockRetainUntil: tt.until})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateObjectLock = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckTargetObjectLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		locked   bool
+		err      error
+		wantCode string // 비어있으면 성공
+	}{
+		{"enabled", true, nil, ""},
+		{"not configured", false, nil, ErrCodeObjectLock},
+		{"access denied", true, fakeResponseError(http.StatusForbidden, "AccessDenied"), ErrCodeAccessDenied},
+		{"other error", true, errors.New("connection reset"), ErrCodeUpload},
+	}
+	for _, tt := range tests {
+		s3c := newFakeS3()
+		s3c.objectLock["target-bucket"] = tt.locked
+		if tt.err != nil {
+			s3c.errs["GetObjectLockConfiguration"] = tt.err
+		}
+		err := checkTargetObjectLock(context.Background(), s3c, "target-bucket")
+		if got := errorCode(err); err != nil && got != tt.wantCode || err == nil && tt.wantCode != "" {
+			t.Errorf("%s: checkTargetObjectLock = %v, want code %q", tt.name, err, tt.wantCode)
+		}
+	}
+}
+
+// 객체 잠금이 없는 대상 버킷이면 압축/업로드 전에 실패하고, 활성화된 버킷이면 보존 설정과 함께 업로드
+func TestHandlerObjectLock(t *testing.T) {
+	retainUntil := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	for _, locked := range []bool{false, true} {
+		s3c, sqsc := newFakeS3(), &fakeSQS{}
+		s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+		s3c.objectLock["target-bucket"] = locked
+		useFakeClients(t, s3c, sqsc)
+		event := testEvent()
+		event.ObjectLockMode = "COMPLIANCE"
+		event.ObjectLockRetainUntil = retainUntil.Format(time.RFC3339)
+
+		_, err := Handler(context.Background(), event)
+		if !locked {
+			if errorCode(err) != ErrCodeObjectLock {
+				t.Errorf("unlocked bucket: Handler = %v, want %s", err, ErrCodeObjectLock)
+			}
+			if len(s3c.puts) != 0 || len(s3c.creates) != 0 {
+				t.Error("unlocked bucket: object uploaded")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("locked bucket: Handler: %v", err)
+		}
+		if len(s3c.puts) != 1 {
+			t.Fatalf("locked bucket: %d puts, want 1", len(s3c.puts))
+		}
+		put := s3c.puts[0]
+		if put.ObjectLockMode != s3types.ObjectLockModeCompliance || put.ObjectLockRetainUntilDate == nil || !put.ObjectLockRetainUntilDate.Equal(retainUntil) {
+			t.Errorf("locked bucket: put lock mode %s until %v", put.ObjectLockMode, put.ObjectLockRetainUntilDate)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// 원본 해시 알고리즘 (originHash)
+const (
+	OriginHashSHA256 = "sha256"
+	OriginHashMD5    = "md5"
+)
+
+// 원본 해시 옵션 검사 (다운로드한 단일 원본 전체를 해시하므로 임시 파일 모드의 단일 원본 압축과 개별 압축 모드만 지원)
+func validateOriginHash(event FileCompressionForm) error {
+	if event.OriginHash == "" {
+		return nil
+	}
+	if event.OriginHash != OriginHashSHA256 && event.OriginHash != OriginHashMD5 {
+		return fmt.Errorf("unsupported originHash: %s (expected sha256 or md5)", event.OriginHash)
+	}
+	switch {
+	case len(event.OriginKeys) > 0 && event.Mode != ModeIndividual, event.OriginPrefix != "":
+		return fmt.Errorf("originHash is not supported with originKeys (except individual mode) or originPrefix")
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("originHash is not supported for %s", event.Operation)
+	case event.StreamMode:
+		return fmt.Errorf("originHash is not supported in stream mode")
+	case event.CopyOnly:
+		return fmt.Errorf("originHash cannot be used with copyOnly")
+	case event.RangeStart != nil || event.RangeEnd != nil:
+		return fmt.Errorf("originHash cannot be used with rangeStart/rangeEnd")
+	}
+	return nil
+}
+
+// 알고리즘 이름으로 해시 생성 (비어있으면 nil)
+func newOriginHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case OriginHashSHA256:
+		return sha256.New()
+	case OriginHashMD5:
+		return md5.New()
+	}
+	return nil
+}
+
+// 해시 결과를 hex 문자열로 반환 (h가 nil이면 "")
+func hashDigest(h hash.Hash) string {
+	if h == nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 원본 해시를 x-amz-meta-original-<algorithm> (hex)로 추가한 업로드 옵션 반환
+func (o uploadOptions) withOriginHash(algorithm, digest string) uploadOptions {
+	if digest == "" {
+		return o
+	}
+	metadata := make(map[string]string, len(o.Metadata)+1)
+	for k, v := range o.Metadata {
+		metadata[k] = v
+	}
+	metadata["original-"+algorithm] = digest
+	o.Metadata = metadata
+	return o
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestValidateOriginHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"not set", FileCompressionForm{StreamMode: true}, false},
+		{"sha256", FileCompressionForm{OriginHash: OriginHashSHA256}, false},
+		{"md5", FileCompressionForm{OriginHash: OriginHashMD5}, false},
+		{"individual", FileCompressionForm{OriginHash: OriginHashSHA256, OriginKeys: []string{"a", "b"}, Mode: ModeIndividual}, false},
+		{"unsupported algorithm", FileCompressionForm{OriginHash: "sha1"}, true},
+		{"multiple keys", FileCompressionForm{OriginHash: OriginHashSHA256, OriginKeys: []string{"a", "b"}}, true},
+		{"prefix", FileCompressionForm{OriginHash: OriginHashSHA256, OriginPrefix: "logs/"}, true},
+		{"decompress", FileCompressionForm{OriginHash: OriginHashSHA256, Operation: OperationDecompress}, true},
+		{"stream", FileCompressionForm{OriginHash: OriginHashSHA256, StreamMode: true}, true},
+		{"copy", FileCompressionForm{OriginHash: OriginHashMD5, CopyOnly: true}, true},
+		{"range", FileCompressionForm{OriginHash: OriginHashSHA256, RangeStart: aws.Int64(0), RangeEnd: aws.Int64(10)}, true},
+		{"range end only", FileCompressionForm{OriginHash: OriginHashSHA256, RangeEnd: aws.Int64(10)}, true},
+	}
+	for _, tt := range tests {
+		if err := validateOriginHash(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateOriginHash = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWithOriginHash(t *testing.T) {
+	opts := uploadOptions{Metadata: map[string]string{"owner": "team"}}
+	got := opts.withOriginHash(OriginHashSHA256, "abc")
+	if got.Metadata["original-sha256"] != "abc" || got.Metadata["owner"] != "team" {
+		t.Errorf("metadata = %v", got.Metadata)
+	}
+	// 원래 옵션의 메타데이터는 바뀌지 않아야 함
+	if _, ok := opts.Metadata["original-sha256"]; ok {
+		t.Error("withOriginHash modified the original metadata")
+	}
+	if got := opts.withOriginHash(OriginHashSHA256, ""); len(got.Metadata) != 1 {
+		t.Errorf("empty digest metadata = %v", got.Metadata)
+	}
+}
+
+// 업로드된 객체의 original-<algorithm> 메타데이터가 다운로드한 원본의 해시와 같은지 확인
+func TestHandlerOriginHash(t *testing.T) {
+	sha := sha256.Sum256(testContent)
+	md := md5.Sum(testContent)
+	tests := map[string]string{
+		OriginHashSHA256: hex.EncodeToString(sha[:]),
+		OriginHashMD5:    hex.EncodeToString(md[:]),
+	}
+	for algorithm, want := range tests {
+		s3c, sqsc := newFakeS3(), &fakeSQS{}
+		s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+		useFakeClients(t, s3c, sqsc)
+		event := testEvent()
+		event.OriginHash = algorithm
+
+		if _, err := Handler(context.Background(), event); err != nil {
+			t.Fatalf("%s: Handler: %v", algorithm, err)
+		}
+		obj := s3c.object("target-bucket", "logs/app.gz")
+		if obj == nil {
+			t.Fatalf("%s: target object not uploaded", algorithm)
+		}
+		if got := obj.metadata["original-"+algorithm]; got != want {
+			t.Errorf("%s: original-%s = %q, want %q", algorithm, algorithm, got, want)
+		}
+	}
+}
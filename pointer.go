@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 포인터가 가리키는 요청 JSON의 최대 크기
+const MaxPointerFormBytes = 16 * 1024 * 1024
+
+func (f FileCompressionForm) hasS3Pointer() bool {
+	return f.S3PointerBucket != "" || f.S3PointerKey != ""
+}
+
+// S3 포인터 요청 검사: 버킷과 키를 함께 지정하고 다른 필드는 비워야 함 (요청 내용은 모두 포인터가 가리키는 객체에 있음)
+func validateS3Pointer(event FileCompressionForm) error {
+	if event.S3PointerBucket == "" || event.S3PointerKey == "" {
+		return fmt.Errorf("s3PointerBucket and s3PointerKey must be set together")
+	}
+	rest := event
+	rest.S3PointerBucket, rest.S3PointerKey = "", ""
+	if !reflect.ValueOf(rest).IsZero() {
+		return fmt.Errorf("s3PointerBucket/s3PointerKey cannot be combined with other request fields")
+	}
+	return nil
+}
+
+// 포인터가 가리키는 S3 객체(Lambda 리전, 실행 역할 자격 증명)에서 요청 JSON을 읽어 FileCompressionForm으로 디코딩
+// 포인터 안의 요청이 다시 포인터인 경우는 허용하지 않음
+func loadFormFromS3(ctx context.Context, event FileCompressionForm) (FileCompressionForm, error) {
+	if err := validateS3Pointer(event); err != nil {
+		return event, newCompressionError(ErrCodeValidation, err)
+	}
+	region := getLambdaRegion()
+	client, err := s3ClientFor(region, "", staticCredentials{})
+	if err != nil {
+		return event, err
+	}
+	resp, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(event.S3PointerBucket),
+		Key:    aws.String(event.S3PointerKey),
+	})
+	if err != nil {
+		if regionErr := regionMismatchError(err, event.S3PointerBucket, region); regionErr != nil {
+			return event, regionErr
+		}
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return event, newCompressionError(ErrCodeOriginNotFound, fmt.Errorf("request object not found: %s/%s", event.S3PointerBucket, event.S3PointerKey))
+		}
+		if isAccessDenied(err) {
+			return event, newCompressionError(ErrCodeAccessDenied, fmt.Errorf("access denied to request object: %s/%s", event.S3PointerBucket, event.S3PointerKey))
+		}
+		return event, newCompressionError(ErrCodeDownload, fmt.Errorf("failed to get request object: %w", err))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxPointerFormBytes+1))
+	if err != nil {
+		return event, newCompressionError(ErrCodeDownload, fmt.Errorf("failed to read request object: %w", err))
+	}
+	if len(data) > MaxPointerFormBytes {
+		return event, newCompressionError(ErrCodeValidation, fmt.Errorf("request object is larger than %d bytes: %s/%s", MaxPointerFormBytes, event.S3PointerBucket, event.S3PointerKey))
+	}
+	form, err := decodeForm(data)
+	if err != nil {
+		return event, newCompressionError(ErrCodeValidation, fmt.Errorf("request object %s/%s: %w", event.S3PointerBucket, event.S3PointerKey, err))
+	}
+	if form.hasS3Pointer() {
+		return event, newCompressionError(ErrCodeValidation, fmt.Errorf("request object must not contain another s3 pointer"))
+	}
+	return form, nil
+}
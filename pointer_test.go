@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func pointerEvent() FileCompressionForm {
+	return FileCompressionForm{S3PointerBucket: "request-bucket", S3PointerKey: "requests/test-uuid.json"}
+}
+
+func TestValidateS3Pointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"bucket and key", pointerEvent(), false},
+		{"bucket only", FileCompressionForm{S3PointerBucket: "request-bucket"}, true},
+		{"key only", FileCompressionForm{S3PointerKey: "requests/test-uuid.json"}, true},
+		{"combined with other fields", FileCompressionForm{S3PointerBucket: "request-bucket", S3PointerKey: "requests/test-uuid.json", ProcessUuid: "test-uuid"}, true},
+	}
+	for _, tt := range tests {
+		if err := validateS3Pointer(tt.event); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateS3Pointer = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadFormFromS3(t *testing.T) {
+	form, err := json.Marshal(testEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, _ := json.Marshal(pointerEvent())
+
+	tests := []struct {
+		name     string
+		event    FileCompressionForm
+		body     []byte // nil이면 객체 없음
+		err      error  // GetObject 에러
+		wantCode string // 비어있으면 성공
+	}{
+		{"valid", pointerEvent(), form, nil, ""},
+		{"combined fields", FileCompressionForm{S3PointerBucket: "request-bucket", S3PointerKey: "requests/test-uuid.json", Format: "gzip"}, form, nil, ErrCodeValidation},
+		{"not found", pointerEvent(), nil, nil, ErrCodeOriginNotFound},
+		{"access denied", pointerEvent(), form, fakeResponseError(http.StatusForbidden, "AccessDenied"), ErrCodeAccessDenied},
+		{"other error", pointerEvent(), form, errors.New("connection reset"), ErrCodeDownload},
+		{"too large", pointerEvent(), bytes.Repeat([]byte(" "), MaxPointerFormBytes+1), nil, ErrCodeValidation},
+		{"invalid json", pointerEvent(), []byte("{"), nil, ErrCodeValidation},
+		{"nested pointer", pointerEvent(), nested, nil, ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c := newFakeS3()
+			if tt.body != nil {
+				s3c.putObject("request-bucket", "requests/test-uuid.json", tt.body, "application/json")
+			}
+			if tt.err != nil {
+				s3c.errs["GetObject"] = tt.err
+			}
+			useFakeClients(t, s3c, &fakeSQS{})
+
+			got, err := loadFormFromS3(context.Background(), tt.event)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("loadFormFromS3 = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadFormFromS3: %v", err)
+			}
+			if got.ProcessUuid != "test-uuid" || got.OriginKey != "logs/app.log" || got.hasS3Pointer() {
+				t.Errorf("loaded form = %+v", got)
+			}
+		})
+	}
+}
+
+// 포인터 요청은 가리키는 요청으로 처리하고, 읽기에 실패하면 결과 큐로 보내지 않음
+func TestHandlerS3Pointer(t *testing.T) {
+	form, _ := json.Marshal(testEvent())
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	s3c.putObject("request-bucket", "requests/test-uuid.json", form, "application/json")
+	useFakeClients(t, s3c, sqsc)
+
+	result, err := Handler(context.Background(), pointerEvent())
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	if result.ProcessUuid != "test-uuid" || s3c.object("target-bucket", "logs/app.gz") == nil {
+		t.Errorf("result = %+v", result)
+	}
+
+	missing := pointerEvent()
+	missing.S3PointerKey = "requests/missing.json"
+	sent := len(sqsc.sent)
+	if _, err := Handler(context.Background(), missing); errorCode(err) != ErrCodeOriginNotFound {
+		t.Errorf("missing pointer: Handler = %v, want %s", err, ErrCodeOriginNotFound)
+	}
+	if len(sqsc.sent) != sent {
+		t.Error("failure result sent for unreadable pointer")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// SigV4 presigned URL 최대 유효 기간 (7일)
+const MaxPresignExpirySeconds = 7 * 24 * 60 * 60
+
+// presignExpirySeconds 검사 (0이면 URL 생성 안 함)
+func validatePresignExpiry(event FileCompressionForm) error {
+	if event.PresignExpiry == 0 {
+		return nil
+	}
+	if event.PresignExpiry < 0 || event.PresignExpiry > MaxPresignExpirySeconds {
+		return fmt.Errorf("presignExpirySeconds must be between 1 and %d", MaxPresignExpirySeconds)
+	}
+	if event.VolumeSize != "" {
+		return fmt.Errorf("presignExpirySeconds is not supported with volumeSize")
+	}
+	return nil
+}
+
+// 업로드한 대상 객체의 GET presigned URL 생성 (대상 리전 클라이언트로 서명)
+// 역할 위임(TargetRoleArn)이나 임시 자격 증명으로 서명하면 세션이 만료될 때 URL도 함께 만료됨
+func presignTargetURL(ctx context.Context, job compressionJob) (string, error) {
+	client, ok := job.TargetClient.(*s3.Client)
+	if !ok {
+		return "", fmt.Errorf("target client does not support presigning")
+	}
+	req, err := s3.NewPresignClient(client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(job.TargetBucket),
+		Key:    aws.String(job.TargetKey),
+	}, s3.WithPresignExpires(time.Duration(job.Event.PresignExpiry)*time.Second))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign target object: %w", err)
+	}
+	return req.URL, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// 네트워크 없이 서명만 하는 실제 S3 클라이언트 (정적 자격 증명)
+func newPresignTestClient() *s3.Client {
+	return s3.New(s3.Options{
+		Region:      "ap-northeast-2",
+		Credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+	})
+}
+
+func TestPresignTargetURL(t *testing.T) {
+	job := compressionJob{
+		Event:        FileCompressionForm{PresignExpiry: 900},
+		TargetClient: newPresignTestClient(),
+		TargetBucket: "target-bucket",
+		TargetKey:    "logs/2024/app.gz",
+	}
+	raw, err := presignTargetURL(context.Background(), job)
+	if err != nil {
+		t.Fatalf("presignTargetURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("invalid presigned URL %q: %v", raw, err)
+	}
+	if u.Scheme != "https" || u.Host != "target-bucket.s3.ap-northeast-2.amazonaws.com" || u.Path != "/logs/2024/app.gz" {
+		t.Errorf("presigned URL = %s, want the target object in ap-northeast-2", raw)
+	}
+	query := u.Query()
+	if got := query.Get("X-Amz-Expires"); got != "900" {
+		t.Errorf("X-Amz-Expires = %q, want 900", got)
+	}
+	if got := query.Get("X-Amz-Credential"); !strings.HasPrefix(got, "AKIDEXAMPLE/") || !strings.Contains(got, "/ap-northeast-2/s3/") {
+		t.Errorf("X-Amz-Credential = %q", got)
+	}
+	if query.Get("X-Amz-Signature") == "" {
+		t.Error("presigned URL is not signed")
+	}
+}
+
+// 서명할 수 없는 클라이언트(테스트용 가짜 등)면 에러
+func TestPresignTargetURLUnsupportedClient(t *testing.T) {
+	job := compressionJob{Event: FileCompressionForm{PresignExpiry: 900}, TargetClient: newFakeS3(), TargetBucket: "target-bucket", TargetKey: "logs/app.gz"}
+	if _, err := presignTargetURL(context.Background(), job); err == nil {
+		t.Error("presignTargetURL succeeded with a client that cannot presign")
+	}
+}
+
+func TestValidatePresignExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"not set", FileCompressionForm{}, false},
+		{"minimum", FileCompressionForm{PresignExpiry: 1}, false},
+		{"maximum", FileCompressionForm{PresignExpiry: MaxPresignExpirySeconds}, false},
+		{"negative", FileCompressionForm{PresignExpiry: -1}, true},
+		{"over seven days", FileCompressionForm{PresignExpiry: MaxPresignExpirySeconds + 1}, true},
+		{"with volumes", FileCompressionForm{PresignExpiry: 60, VolumeSize: "100m"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePresignExpiry(tt.event); (err != nil) != tt.wantErr {
+				t.Errorf("validatePresignExpiry = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
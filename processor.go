@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// 요청 처리기 설정 (동시 처리 수 제한만 담당하고, 클라이언트/임시 디렉토리 등은 init에서 설정한 패키지 전역 값을 그대로 사용)
+type ProcessorOptions struct {
+	MaxConcurrent int // 동시에 처리할 수 있는 최대 요청 수 (0이면 제한 없음)
+}
+
+// Handler의 동시 처리 수 제한: Process로 요청 하나를 처리하며 동시에 실행되는 요청이 MaxConcurrent를 넘지 않음
+// 같은 프로세스에서 Handler를 여러 고루틴으로 호출하는 경우(로컬 서버, 배치 루프 등)를 위한 것이며,
+// package main이므로 다른 패키지에서 import하는 라이브러리로는 사용할 수 없음
+// (Lambda는 컨테이너당 한 번에 하나의 호출만 처리하므로 Handler는 기본적으로 제한 없이 사용)
+type Processor struct {
+	opts  ProcessorOptions
+	slots chan struct{} // nil이면 제한 없음
+}
+
+func NewProcessor(opts ProcessorOptions) *Processor {
+	p := &Processor{opts: opts}
+	if opts.MaxConcurrent > 0 {
+		p.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return p
+}
+
+// Handler가 사용하는 기본 처리기 (init에서 MAX_CONCURRENT_REQUESTS 환경 변수로 생성)
+var defaultProcessor *Processor
+
+// 요청 하나를 처리 (처리 슬롯이 없으면 빌 때까지 대기, 대기 중 context가 끝나면 실패 처리)
+func (p *Processor) Process(ctx context.Context, event FileCompressionForm) (CompressionResultData, error) {
+	// 요청이 S3 포인터면 가리키는 객체에서 실제 요청을 읽음 (결과 큐 정보도 그 안에 있으므로 실패해도 큐로는 보내지 않음)
+	if event.hasS3Pointer() {
+		form, err := loadFormFromS3(ctx, event)
+		if err != nil {
+			loggerFrom(ctx).Error("Failed to load request from S3 pointer", "stage", "validate", "bucket", event.S3PointerBucket, "key", event.S3PointerKey, errorAttr(err))
+			return buildErrorResult(event, err), err
+		}
+		event = form
+	}
+	// 이후 모든 로그에 processUuid 포함
+	ctx = withLogger(ctx, slog.Default().With("processUuid", event.ProcessUuid))
+	if event.Warmup {
+		return handleWarmup(ctx), nil
+	}
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+			defer func() { <-p.slots }()
+		case <-ctx.Done():
+			err := newCompressionError(ErrCodeTimeBudget, fmt.Errorf("waiting for processing slot: %w", ctx.Err()))
+			loggerFrom(ctx).Error("No processing slot available", "stage", "init", "maxConcurrent", p.opts.MaxConcurrent, errorAttr(err))
+			return handleFailure(ctx, event, err)
+		}
+	}
+	return withIdempotency(ctx, event, func() (CompressionResultData, error) {
+		return processRequest(ctx, event)
+	})
+}
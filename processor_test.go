@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// 처리 슬롯이 모두 사용 중이면 대기하다가 context가 끝나면 INSUFFICIENT_TIME으로 실패
+func TestProcessorConcurrencyCap(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	useFakeClients(t, s3c, sqsc)
+
+	p := NewProcessor(ProcessorOptions{MaxConcurrent: 1})
+	p.slots <- struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Process(ctx, testEvent()); errorCode(err) != ErrCodeTimeBudget {
+		t.Fatalf("Process with busy slot = %v, want %s", err, ErrCodeTimeBudget)
+	}
+	if len(s3c.puts) != 0 {
+		t.Error("object uploaded while waiting for a slot")
+	}
+
+	// 슬롯이 비면 처리
+	<-p.slots
+	if _, err := p.Process(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(p.slots) != 0 {
+		t.Error("processing slot not released")
+	}
+}
+
+func TestProcessorUnlimited(t *testing.T) {
+	if p := NewProcessor(ProcessorOptions{}); p.slots != nil {
+		t.Error("MaxConcurrent 0 created a limiter")
+	}
+}
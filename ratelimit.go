@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 결과 큐 전송 속도 제한 기본값 (초당 메시지 수, 0이면 제한 없음)
+const DefaultQueueSendRate = 0
+
+// 토큰 버킷 방식의 속도 제한기: 초당 rate개의 토큰이 채워지고 최대 burst개까지 쌓임
+// 토큰이 없으면 실패하지 않고 다음 토큰이 생길 때까지 대기 (context 취소 시 중단)
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// rate가 0 이하이면 제한하지 않는 limiter 반환
+func newRateLimiter(rate float64) *rateLimiter {
+	burst := max(rate, 1)
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	for {
+		delay := l.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// 토큰을 채운 뒤 하나를 사용할 수 있으면 사용하고 0, 없으면 다음 토큰까지 남은 시간 반환
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
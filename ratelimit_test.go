@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// 처음에는 burst개까지 바로 사용할 수 있고, 그 뒤에는 다음 토큰까지 기다려야 함
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(5)
+	now := time.Now()
+	for i := range 5 {
+		if delay := l.reserve(now); delay != 0 {
+			t.Fatalf("reserve %d = %v, want 0 within burst", i, delay)
+		}
+	}
+	if delay := l.reserve(now); delay != 200*time.Millisecond {
+		t.Errorf("reserve after burst = %v, want 200ms", delay)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := newRateLimiter(5)
+	now := time.Now()
+	for range 5 {
+		l.reserve(now)
+	}
+
+	// 100ms 뒤에는 토큰 반 개: 남은 100ms 대기
+	now = now.Add(100 * time.Millisecond)
+	if delay := l.reserve(now); delay != 100*time.Millisecond {
+		t.Errorf("reserve after 100ms = %v, want 100ms", delay)
+	}
+	now = now.Add(100 * time.Millisecond)
+	if delay := l.reserve(now); delay != 0 {
+		t.Errorf("reserve after 200ms = %v, want 0", delay)
+	}
+
+	// 오래 쉬어도 burst개까지만 쌓임
+	now = now.Add(time.Minute)
+	for i := range 5 {
+		if delay := l.reserve(now); delay != 0 {
+			t.Fatalf("reserve %d after idle = %v, want 0", i, delay)
+		}
+	}
+	if delay := l.reserve(now); delay == 0 {
+		t.Error("tokens accumulated beyond burst")
+	}
+}
+
+// 초당 1개 미만이면 burst는 1이고 토큰 간격은 1/rate초
+func TestRateLimiterFractionalRate(t *testing.T) {
+	l := newRateLimiter(0.5)
+	now := time.Now()
+	if delay := l.reserve(now); delay != 0 {
+		t.Fatalf("first reserve = %v, want 0", delay)
+	}
+	if delay := l.reserve(now); delay != 2*time.Second {
+		t.Errorf("second reserve = %v, want 2s", delay)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := newRateLimiter(0)
+	for range 1000 {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+}
+
+// 토큰을 기다리는 중 context가 취소되면 바로 취소 에러 반환
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l := newRateLimiter(0.01) // 다음 토큰까지 100초
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	err := l.wait(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("wait returned after %v, want prompt return on cancel", elapsed)
+	}
+}
+
+// burst를 다 쓴 뒤에는 rate에 맞춰 간격을 두고 통과
+func TestRateLimiterPacing(t *testing.T) {
+	const rate = 50
+	l := newRateLimiter(rate)
+	ctx := context.Background()
+	for range rate {
+		if err := l.wait(ctx); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	start := time.Now()
+	for range 5 {
+		if err := l.wait(ctx); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	// 5개 토큰은 100ms에 걸쳐 채워짐 (타이머 오차를 감안해 하한만 느슨하게 확인)
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("5 paced waits took %v, want about 100ms", elapsed)
+	}
+}
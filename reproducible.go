@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 재현 가능한 출력용 7z 스위치: 생성/접근 시간 저장 안 함 (7-Zip 버전별 기본값 차이 제거)
+var reproducibleSevenZipFlags = []string{"-mtc=off", "-mta=off"}
+
+// 같은 입력에서 같은 바이트의 아카이브를 만들기 위한 옵션 검사
+// 제한 사항:
+//   - 7za 버전, 압축 옵션(레벨, 사전 크기, 솔리드)과 스레드 수가 같아야 함 (threads를 지정하지 않으면 CPU 수에 따라 LZMA2 블록 분할이 달라질 수 있음)
+//   - 암호화는 실행마다 임의의 salt/IV를 사용하므로 함께 사용할 수 없음
+//   - 스트리밍 모드는 표준 입력 항목에 현재 시간이 기록되므로 지원하지 않음
+//   - zip은 디렉토리 순회 순서대로 항목을 저장하므로 접두사(originPrefix) 모드에서는 순서를 보장할 수 없음
+func validateReproducible(event FileCompressionForm) error {
+	if !event.Reproducible {
+		return nil
+	}
+	format := defaultIfEmpty(event.Format, DefaultFormat)
+	switch {
+	case event.Password != "":
+		return fmt.Errorf("reproducible cannot be used with password")
+	case event.StreamMode:
+		return fmt.Errorf("reproducible is not supported in stream mode")
+	case event.Operation == OperationDecompress:
+		return fmt.Errorf("reproducible is not supported for decompress")
+	case format == "zip" && event.OriginPrefix != "":
+		return fmt.Errorf("reproducible is not supported for zip with originPrefix")
+	}
+	return nil
+}
+
+// 접두사 압축용 디렉토리(root 포함)의 수정 시간을 t로 맞춤 (아카이브에 저장되는 디렉토리 항목 시간 고정)
+func setDirModTimes(root string, t time.Time) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if err := os.Chtimes(path, t, t); err != nil {
+			return fmt.Errorf("failed to set modification time of %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// 원본 중 가장 최근 LastModified
+func latestModTime(infos []objectInfo) time.Time {
+	var latest time.Time
+	for _, info := range infos {
+		if info.LastModified.After(latest) {
+			latest = info.LastModified
+		}
+	}
+	return latest
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// reproducible이면 같은 입력을 다른 시각에 두 번 압축해도 같은 바이트의 결과가 나옴
+// 7z/zip은 실제 7za가 필요하므로 없으면 건너뜀 (gzip은 Go 구현으로 항상 확인)
+func TestReproducibleOutput(t *testing.T) {
+	tests := []struct {
+		format, targetKey string
+		needsSevenZip     bool
+	}{
+		{"gzip", "logs/app.gz", false},
+		{"7z", "logs/app.7z", true},
+		{"zip", "logs/app.zip", true},
+	}
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if _, err := os.Stat(sevenZipCmd); tt.needsSevenZip && err != nil {
+				t.Skipf("7za not available: %v", err)
+			}
+			var outputs [][]byte
+			for run := range 2 {
+				if run > 0 && tt.needsSevenZip {
+					// 아카이브에 현재 시각이 들어가면 결과가 달라지도록 초 단위 시간을 바꿈
+					time.Sleep(1100 * time.Millisecond)
+				}
+				s3c, sqsc := newFakeS3(), &fakeSQS{}
+				s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain").lastModified = lastModified
+				useFakeClients(t, s3c, sqsc)
+				event := testEvent()
+				event.Format = tt.format
+				event.TargetKey = tt.targetKey
+				event.Reproducible = true
+				event.Threads = 1
+
+				if _, err := processRequest(context.Background(), event); err != nil {
+					t.Fatalf("run %d: processRequest: %v", run+1, err)
+				}
+				target := s3c.object("target-bucket", tt.targetKey)
+				if target == nil {
+					t.Fatalf("run %d: target was not uploaded", run+1)
+				}
+				outputs = append(outputs, target.data)
+			}
+			if !bytes.Equal(outputs[0], outputs[1]) {
+				t.Errorf("outputs differ: %s vs %s", etag(outputs[0]), etag(outputs[1]))
+			}
+		})
+	}
+}
+
+func TestValidateReproducible(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   FileCompressionForm
+		wantErr bool
+	}{
+		{"off", FileCompressionForm{Password: "secret"}, false},
+		{"7z", FileCompressionForm{Reproducible: true, Format: "7z"}, false},
+		{"password", FileCompressionForm{Reproducible: true, Password: "secret"}, true},
+		{"stream", FileCompressionForm{Reproducible: true, StreamMode: true}, true},
+		{"decompress", FileCompressionForm{Reproducible: true, Operation: OperationDecompress}, true},
+		{"zip with prefix", FileCompressionForm{Reproducible: true, Format: "zip", OriginPrefix: "logs/"}, true},
+		{"7z with prefix", FileCompressionForm{Reproducible: true, Format: "7z", OriginPrefix: "logs/"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReproducible(tt.event); (err != nil) != tt.wantErr {
+				t.Errorf("validateReproducible = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
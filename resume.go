@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// 이어서 업로드 옵션 검사 (임시 파일 모드의 멀티파트 업로드에만 적용)
+// 이어 붙일 파트가 같은 내용인지 ETag(MD5)로만 확인하므로 파트별 SHA256 체크섬을 쓰는 verifyChecksum과는 함께 사용할 수 없음
+func validateResumeUpload(event FileCompressionForm) error {
+	if !event.ResumeUpload {
+		return nil
+	}
+	switch {
+	case event.StreamMode:
+		return fmt.Errorf("resumeUpload is not supported in stream mode")
+	case event.VerifyChecksum:
+		return fmt.Errorf("resumeUpload cannot be used with verifyChecksum")
+	case event.CopyOnly:
+		return fmt.Errorf("resumeUpload cannot be used with copyOnly")
+	}
+	return nil
+}
+
+// 다운로드한 파일의 수정 시간을 원본 LastModified로 맞춤
+// 아카이브/gzip 헤더에 저장되는 시간이 실행마다 달라지지 않아야 다시 압축한 결과가 이전 실행의 파트와 같아짐
+func setOriginModTimes(paths []string, infos []objectInfo) error {
+	for i, path := range paths {
+		if infos[i].LastModified.IsZero() {
+			continue
+		}
+		if err := os.Chtimes(path, infos[i].LastModified, infos[i].LastModified); err != nil {
+			return fmt.Errorf("failed to set modification time of %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// 이어서 업로드할 업로드를 찾기 위한 표식 객체 접미사 (대상 키 + 접미사)
+// 진행 중인 멀티파트 업로드의 메타데이터/태그는 ListMultipartUploads로 조회할 수 없으므로,
+// 업로드를 시작할 때 업로드 ID, ProcessUuid, 업로드 설정 지문을 표식 객체의 메타데이터로 남기고 완료되면 삭제
+const ResumeMarkerSuffix = ".resume-marker"
+
+// 표식 객체 메타데이터 (x-amz-meta-*)
+const (
+	resumeMetaUploadId    = "upload-id"
+	resumeMetaProcessUuid = "process-uuid"
+	resumeMetaSettings    = "upload-settings"
+)
+
+func resumeMarkerKey(key string) string {
+	return key + ResumeMarkerSuffix
+}
+
+// 완료된 객체에 적용되는 업로드 설정(SSE/KMS, 메타데이터, 태그, 스토리지 클래스, 객체 잠금 등)의 지문
+// 멀티파트 업로드는 CreateMultipartUpload 때의 설정으로 완료되므로, 설정이 다른 업로드를 이어서 완료하면 요청과 다른 객체가 됨
+func uploadSettingsFingerprint(input *s3.PutObjectInput) string {
+	h := sha256.New()
+	fields := []string{
+		string(input.ServerSideEncryption),
+		aws.ToString(input.SSEKMSKeyId),
+		aws.ToString(input.ContentType),
+		aws.ToString(input.ContentDisposition),
+		string(input.StorageClass),
+		aws.ToString(input.Tagging),
+		string(input.ObjectLockMode),
+		aws.ToTime(input.ObjectLockRetainUntilDate).UTC().Format(time.RFC3339),
+		string(input.ChecksumAlgorithm),
+	}
+	for _, k := range slices.Sorted(maps.Keys(input.Metadata)) {
+		fields = append(fields, k+"="+input.Metadata[k])
+	}
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 이어서 업로드할 수 있는 멀티파트 업로드로 업로드 (없으면 새로 업로드)
+// 새 업로드는 시작 직후 표식 객체를 남기고 실패해도 파트를 남겨 다음 실행에서 이어서 사용할 수 있도록 함
+func uploadResumable(ctx context.Context, client S3API, input *s3.PutObjectInput, f *os.File, size, partSize int64, processUuid string) error {
+	settings := uploadSettingsFingerprint(input)
+	resumed, err := resumeMultipartUpload(ctx, client, input, f, size, partSize, processUuid, settings)
+	if err != nil || resumed {
+		return err
+	}
+	marked := resumeMarkerClient{S3API: client, processUuid: processUuid, settings: settings}
+	_, err = manager.NewUploader(marked, func(u *manager.Uploader) {
+		u.PartSize = partSize
+		u.LeavePartsOnError = true
+	}).Upload(ctx, input)
+	if err != nil {
+		return err
+	}
+	deleteResumeMarker(ctx, client, aws.ToString(input.Bucket), aws.ToString(input.Key))
+	return nil
+}
+
+// 멀티파트 업로드를 시작하면 표식 객체를 남기는 S3 클라이언트 (업로드 자체는 요청 태그에 ProcessUuid 포함)
+type resumeMarkerClient struct {
+	S3API
+	processUuid string
+	settings    string
+}
+
+func (c resumeMarkerClient) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	out, err := c.S3API.CreateMultipartUpload(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.S3API.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: params.Bucket,
+		Key:    aws.String(resumeMarkerKey(aws.ToString(params.Key))),
+		Body:   strings.NewReader(""),
+		Metadata: map[string]string{
+			resumeMetaUploadId:    aws.ToString(out.UploadId),
+			resumeMetaProcessUuid: c.processUuid,
+			resumeMetaSettings:    c.settings,
+		},
+	})
+	if err != nil {
+		// 표식이 없으면 이어서 업로드할 수 없을 뿐이므로 업로드는 계속 진행
+		loggerFrom(ctx).Warn("Failed to write resume marker", "stage", "upload", "uploadId", aws.ToString(out.UploadId), errorAttr(err))
+	}
+	return out, nil
+}
+
+// 업로드가 끝났거나 버린 뒤 표식 객체 삭제 (실패해도 다음 실행에서 업로드가 없으면 무시되므로 경고만 남김)
+func deleteResumeMarker(ctx context.Context, client S3API, bucket, key string) {
+	if err := deleteFromS3(ctx, client, bucket, resumeMarkerKey(key), ""); err != nil {
+		loggerFrom(ctx).Warn("Failed to delete resume marker", "stage", "upload", errorAttr(err))
+	}
+}
+
+// 이전 실행의 업로드를 이어서 사용할 수 없으면 중단하여 남은 파트를 정리하고 표식 삭제
+func abortStaleUpload(ctx context.Context, client S3API, bucket, key, uploadId string) {
+	_, err := client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadId),
+	})
+	if err != nil {
+		var noSuchUpload *s3types.NoSuchUpload
+		if !errors.As(err, &noSuchUpload) {
+			loggerFrom(ctx).Warn("Failed to abort stale multipart upload", "stage", "upload", "uploadId", uploadId, errorAttr(err))
+		}
+	}
+	deleteResumeMarker(ctx, client, bucket, key)
+}
+
+// 표식 객체가 가리키는 중단된 멀티파트 업로드를 이어서 업로드하고 true 반환 (없으면 false, 새로 업로드)
+// 같은 ProcessUuid로 같은 업로드 설정을 사용한 업로드만 이어서 사용하고, 다르면 중단한 뒤 새로 업로드
+// 파트는 크기와 ETag(MD5)가 로컬 파일의 같은 구간과 일치하는 것만 재사용하고 나머지는 다시 업로드
+// (SSE-KMS로 암호화된 파트는 ETag가 MD5가 아니므로 모두 다시 업로드됨)
+func resumeMultipartUpload(ctx context.Context, client S3API, input *s3.PutObjectInput, f *os.File, size, partSize int64, processUuid, settings string) (bool, error) {
+	bucket, key := aws.ToString(input.Bucket), aws.ToString(input.Key)
+	marker, err := headObject(ctx, client, bucket, resumeMarkerKey(key), "")
+	if err != nil {
+		return false, fmt.Errorf("failed to read resume marker: %w", err)
+	}
+	if marker == nil {
+		return false, nil
+	}
+	uploadId := marker.Metadata[resumeMetaUploadId]
+	if uploadId == "" {
+		deleteResumeMarker(ctx, client, bucket, key)
+		return false, nil
+	}
+	if marker.Metadata[resumeMetaProcessUuid] != processUuid || marker.Metadata[resumeMetaSettings] != settings {
+		loggerFrom(ctx).Info("Discarding multipart upload from a different request or with different settings", "stage", "upload", "uploadId", uploadId, "previousProcessUuid", marker.Metadata[resumeMetaProcessUuid])
+		abortStaleUpload(ctx, client, bucket, key, uploadId)
+		return false, nil
+	}
+	existing, err := listUploadedParts(ctx, client, bucket, key, uploadId)
+	if err != nil {
+		var noSuchUpload *s3types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			// 이미 완료되었거나 수명 주기 규칙으로 정리된 업로드
+			deleteResumeMarker(ctx, client, bucket, key)
+			return false, nil
+		}
+		return false, err
+	}
+
+	parts := int((size + partSize - 1) / partSize)
+	completed := make([]s3types.CompletedPart, parts)
+	sem := make(chan struct{}, manager.DefaultUploadConcurrency)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		reused   int
+		mu       sync.Mutex
+	)
+	for i := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			number := int32(i + 1)
+			offset := int64(i) * partSize
+			section := io.NewSectionReader(f, offset, min(partSize, size-offset))
+			etag, err := uploadMissingPart(ctx, client, bucket, key, uploadId, number, section, existing[number])
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				return
+			}
+			if etag == "" {
+				mu.Lock()
+				reused++
+				mu.Unlock()
+				etag = aws.ToString(existing[number].ETag)
+			}
+			completed[i] = s3types.CompletedPart{PartNumber: aws.Int32(number), ETag: aws.String(etag)}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return true, firstErr
+	}
+	loggerFrom(ctx).Info("Resuming multipart upload", "stage", "upload", "uploadId", uploadId, "parts", parts, "reusedParts", reused)
+
+	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        aws.String(uploadId),
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to complete multipart upload %s: %w", uploadId, err)
+	}
+	deleteResumeMarker(ctx, client, bucket, key)
+	return true, nil
+}
+
+// 업로드 ID에 이미 올라간 파트 목록 (파트 번호 → 파트)
+func listUploadedParts(ctx context.Context, client S3API, bucket, key, uploadId string) (map[int32]s3types.Part, error) {
+	parts := map[int32]s3types.Part{}
+	paginator := s3.NewListPartsPaginator(client, &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadId),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list parts of multipart upload %s: %w", uploadId, err)
+		}
+		for _, part := range page.Parts {
+			parts[aws.ToInt32(part.PartNumber)] = part
+		}
+	}
+	return parts, nil
+}
+
+// 이미 올라간 파트가 같은 내용이면 ""를 반환하고, 아니면 파트를 업로드하여 새 ETag 반환
+func uploadMissingPart(ctx context.Context, client S3API, bucket, key, uploadId string, number int32, section *io.SectionReader, existing s3types.Part) (string, error) {
+	if existing.ETag != nil && aws.ToInt64(existing.Size) == section.Size() {
+		h := md5.New()
+		if _, err := io.Copy(h, section); err != nil {
+			return "", fmt.Errorf("failed to hash part %d: %w", number, err)
+		}
+		if hex.EncodeToString(h.Sum(nil)) == strings.Trim(aws.ToString(existing.ETag), `"`) {
+			return "", nil
+		}
+		if _, err := section.Seek(0, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to rewind part %d: %w", number, err)
+		}
+	}
+	out, err := client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadId),
+		PartNumber: aws.Int32(number),
+		Body:       section,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", number, err)
+	}
+	return aws.ToString(out.ETag), nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// 3개 파트(5MiB, 5MiB, 1MiB)로 나뉘는 업로드 내용
+func resumeTestContent() []byte {
+	data := make([]byte, 11*1024*1024)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func resumeTestOptions(processUuid string) uploadOptions {
+	return uploadOptions{ResumeUpload: true, ProcessUuid: processUuid, Tagging: buildTagging(nil, processUuid, true), ContentType: "application/gzip"}
+}
+
+// CompleteMultipartUpload 직전에 중단된 이전 실행을 흉내 냄 (파트와 표식 객체가 남음)
+func interruptedUpload(t *testing.T, s3c *fakeS3, data []byte, opts uploadOptions) {
+	t.Helper()
+	s3c.errs["CompleteMultipartUpload"] = errors.New("function timed out")
+	if _, err := uploadToS3(context.Background(), s3c, "target-bucket", "logs/app.gz", writeTempFile(t, data), opts); err == nil {
+		t.Fatal("interrupted upload succeeded")
+	}
+	delete(s3c.errs, "CompleteMultipartUpload")
+	if len(s3c.uploads) != 1 || len(s3c.parts) != 3 {
+		t.Fatalf("interrupted upload left %d uploads and %d parts, want 1 and 3", len(s3c.uploads), len(s3c.parts))
+	}
+	marker := s3c.object("target-bucket", resumeMarkerKey("logs/app.gz"))
+	if marker == nil || marker.metadata[resumeMetaProcessUuid] != opts.ProcessUuid {
+		t.Fatalf("resume marker = %+v, want one for %s", marker, opts.ProcessUuid)
+	}
+}
+
+func TestResumeMultipartUpload(t *testing.T) {
+	setMultipartThreshold(t, 1024*1024)
+	data := resumeTestContent()
+	changed := bytes.Clone(data)
+	changed[len(changed)-1]++ // 마지막 파트만 다름
+
+	tests := []struct {
+		name        string
+		data        []byte
+		opts        uploadOptions
+		wantResumed bool
+		wantParts   int // 두 번째 실행에서 업로드한 파트 수
+	}{
+		{"same request and settings", data, resumeTestOptions("uuid-1"), true, 0},
+		{"same request, changed last part", changed, resumeTestOptions("uuid-1"), true, 1},
+		{"different processUuid", data, resumeTestOptions("uuid-2"), false, 3},
+		{"different storage class", data, func() uploadOptions {
+			o := resumeTestOptions("uuid-1")
+			o.StorageClass = s3types.StorageClassStandardIa
+			return o
+		}(), false, 3},
+		{"different encryption", data, func() uploadOptions {
+			o := resumeTestOptions("uuid-1")
+			o.SSEAlgorithm, o.SSEKmsKeyId = s3types.ServerSideEncryptionAwsKms, "alias/logs"
+			return o
+		}(), false, 3},
+		{"different metadata", data, func() uploadOptions {
+			o := resumeTestOptions("uuid-1")
+			o.Metadata = map[string]string{"original-key": "logs/other.log"}
+			return o
+		}(), false, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3c := newFakeS3()
+			interruptedUpload(t, s3c, data, resumeTestOptions("uuid-1"))
+			partsBefore := len(s3c.parts)
+
+			if _, err := uploadToS3(context.Background(), s3c, "target-bucket", "logs/app.gz", writeTempFile(t, tt.data), tt.opts); err != nil {
+				t.Fatalf("uploadToS3: %v", err)
+			}
+			if got := len(s3c.parts) - partsBefore; got != tt.wantParts {
+				t.Errorf("uploaded %d parts, want %d", got, tt.wantParts)
+			}
+			wantCreates, wantAborts := 2, 1
+			if tt.wantResumed {
+				wantCreates, wantAborts = 1, 0
+			}
+			if len(s3c.creates) != wantCreates || len(s3c.aborts) != wantAborts {
+				t.Errorf("CreateMultipartUpload %d, AbortMultipartUpload %d, want %d and %d", len(s3c.creates), len(s3c.aborts), wantCreates, wantAborts)
+			}
+			obj := s3c.object("target-bucket", "logs/app.gz")
+			if obj == nil || !bytes.Equal(obj.data, tt.data) {
+				t.Fatal("completed object does not match the local file")
+			}
+			if got, want := obj.tagging, tt.opts.Tagging; got != want {
+				t.Errorf("completed object tagging = %q, want %q", got, want)
+			}
+			if s3c.object("target-bucket", resumeMarkerKey("logs/app.gz")) != nil {
+				t.Error("resume marker was not deleted")
+			}
+			if len(s3c.uploads) != 0 {
+				t.Errorf("%d multipart uploads left", len(s3c.uploads))
+			}
+		})
+	}
+}
+
+// 표식 객체가 가리키는 업로드가 이미 없으면(수명 주기 규칙으로 정리 등) 새로 업로드
+func TestResumeMultipartUploadGone(t *testing.T) {
+	setMultipartThreshold(t, 1024*1024)
+	data := resumeTestContent()
+	s3c := newFakeS3()
+	opts := resumeTestOptions("uuid-1")
+	interruptedUpload(t, s3c, data, opts)
+	for id := range s3c.uploads {
+		delete(s3c.uploads, id)
+	}
+
+	if _, err := uploadToS3(context.Background(), s3c, "target-bucket", "logs/app.gz", writeTempFile(t, data), opts); err != nil {
+		t.Fatalf("uploadToS3: %v", err)
+	}
+	if obj := s3c.object("target-bucket", "logs/app.gz"); obj == nil || !bytes.Equal(obj.data, data) {
+		t.Fatal("object was not uploaded")
+	}
+	if len(s3c.creates) != 2 || s3c.object("target-bucket", resumeMarkerKey("logs/app.gz")) != nil {
+		t.Errorf("CreateMultipartUpload %d times and marker left = %v, want a fresh upload without marker", len(s3c.creates), s3c.object("target-bucket", resumeMarkerKey("logs/app.gz")) != nil)
+	}
+}
+
+// 설정 지문은 메타데이터 순서와 무관하고, 완료된 객체에 적용되는 설정이 다르면 달라짐
+func TestUploadSettingsFingerprint(t *testing.T) {
+	build := func(opts uploadOptions) string {
+		input := &s3.PutObjectInput{}
+		opts.apply(input)
+		return uploadSettingsFingerprint(input)
+	}
+	base := resumeTestOptions("uuid-1")
+	base.Metadata = map[string]string{"a": "1", "b": "2"}
+	same := resumeTestOptions("uuid-1")
+	same.Metadata = map[string]string{"b": "2", "a": "1"}
+	if build(base) != build(same) {
+		t.Error("fingerprint depends on metadata order")
+	}
+
+	locked := base
+	locked.ObjectLockMode = s3types.ObjectLockModeGovernance
+	locked.ObjectLockRetainUntil = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	longer := locked
+	longer.ObjectLockRetainUntil = locked.ObjectLockRetainUntil.AddDate(1, 0, 0)
+	for name, opts := range map[string]uploadOptions{"object lock": locked, "retain until": longer} {
+		if build(opts) == build(base) || (name == "retain until" && build(opts) == build(locked)) {
+			t.Errorf("fingerprint ignores %s", name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// SSE-C 키 없이 SSE-C로 암호화된 원본을 읽으려 한 경우
+const ErrCodeSSECustomerKey = "SSE_C_KEY_REQUIRED"
+
+// SSE-C(고객 제공 키)로 암호화된 원본 객체를 읽기 위한 키 (AES256만 지원)
+type sseCustomerKey struct {
+	Algorithm string
+	Key       string // base64 인코딩된 256비트 키
+	KeyMD5    string // base64 인코딩된 키의 MD5
+}
+
+// 요청의 SSECustomerKey로부터 키 생성 (지정하지 않았으면 ok=false)
+func sseCustomerKeyFromForm(event FileCompressionForm) (sseCustomerKey, bool, error) {
+	if event.SSECustomerKey == "" {
+		if event.SSECustomerAlg != "" {
+			return sseCustomerKey{}, false, fmt.Errorf("sseCustomerAlgorithm requires sseCustomerKey")
+		}
+		return sseCustomerKey{}, false, nil
+	}
+	algorithm := defaultIfEmpty(event.SSECustomerAlg, string(s3types.ServerSideEncryptionAes256))
+	if algorithm != string(s3types.ServerSideEncryptionAes256) {
+		return sseCustomerKey{}, false, fmt.Errorf("unsupported sseCustomerAlgorithm: %s (only AES256)", algorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(event.SSECustomerKey)
+	if err != nil {
+		return sseCustomerKey{}, false, fmt.Errorf("sseCustomerKey must be base64 encoded: %w", err)
+	}
+	if len(raw) != 32 {
+		return sseCustomerKey{}, false, fmt.Errorf("sseCustomerKey must be a 256-bit key, got %d bits", len(raw)*8)
+	}
+	sum := md5.Sum(raw)
+	return sseCustomerKey{
+		Algorithm: algorithm,
+		Key:       event.SSECustomerKey,
+		KeyMD5:    base64.StdEncoding.EncodeToString(sum[:]),
+	}, true, nil
+}
+
+func validateSSECustomerKey(event FileCompressionForm) error {
+	_, _, err := sseCustomerKeyFromForm(event)
+	return err
+}
+
+// 원본 읽기 요청(GetObject, HeadObject, CopyObject 원본)에 SSE-C 키를 추가하는 S3 클라이언트
+// 대상 업로드에는 영향을 주지 않음 (대상 암호화는 SSEAlgorithm/SSEKmsKeyId로 지정)
+type sseCustomerClient struct {
+	S3API
+	key sseCustomerKey
+}
+
+func (c sseCustomerClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	input := *params
+	input.SSECustomerAlgorithm = &c.key.Algorithm
+	input.SSECustomerKey = &c.key.Key
+	input.SSECustomerKeyMD5 = &c.key.KeyMD5
+	return c.S3API.GetObject(ctx, &input, optFns...)
+}
+
+func (c sseCustomerClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	input := *params
+	input.SSECustomerAlgorithm = &c.key.Algorithm
+	input.SSECustomerKey = &c.key.Key
+	input.SSECustomerKeyMD5 = &c.key.KeyMD5
+	return c.S3API.HeadObject(ctx, &input, optFns...)
+}
+
+func (c sseCustomerClient) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	input := *params
+	input.CopySourceSSECustomerAlgorithm = &c.key.Algorithm
+	input.CopySourceSSECustomerKey = &c.key.Key
+	input.CopySourceSSECustomerKeyMD5 = &c.key.KeyMD5
+	return c.S3API.CopyObject(ctx, &input, optFns...)
+}
+
+// SSE-C 객체를 키 없이 읽을 때 S3가 반환하는 에러인지 확인
+// GetObject는 400 InvalidRequest("...stored using a form of Server Side Encryption..."), HeadObject는 본문 없는 400을 반환
+func isSSECustomerKeyRequired(err error) bool {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.HTTPStatusCode() != http.StatusBadRequest {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Server Side Encryption") || strings.Contains(msg, "HeadObject")
+}
+
+// 키를 지정하지 않은 요청에서 SSE-C 키가 필요한 에러면 원인을 알려주는 에러로 감쌈 (아니면 그대로 반환)
+// 키를 지정했는데 400이면 키가 필요 없는 객체이므로 S3 에러를 그대로 전달
+func describeSSECustomerError(err error, event FileCompressionForm, key string) error {
+	if err == nil || event.SSECustomerKey != "" || !isSSECustomerKeyRequired(err) {
+		return err
+	}
+	return newCompressionError(ErrCodeSSECustomerKey, fmt.Errorf("object is SSE-C encrypted, customer key required: %s/%s (set sseCustomerKey): %w", event.OriginBucket, key, err))
+}
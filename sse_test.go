@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+// SDK가 반환하는 형태의 S3 에러 ("operation error S3: <op>, https response error StatusCode: ...")
+func fakeOperationError(operation string, status int, message string) error {
+	return &smithy.OperationError{ServiceID: "S3", OperationName: operation, Err: fakeResponseError(status, message)}
+}
+
+func TestDescribeSSECustomerError(t *testing.T) {
+	withKey := FileCompressionForm{OriginBucket: "origin-bucket", SSECustomerKey: base64.StdEncoding.EncodeToString(make([]byte, 32))}
+	withoutKey := FileCompressionForm{OriginBucket: "origin-bucket"}
+	tests := []struct {
+		name     string
+		err      error
+		event    FileCompressionForm
+		wantCode string // 비어있으면 원래 에러를 그대로 반환
+	}{
+		{"get without key", fakeOperationError("GetObject", http.StatusBadRequest, "InvalidRequest: The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object."), withoutKey, ErrCodeSSECustomerKey},
+		{"head without key", fakeOperationError("HeadObject", http.StatusBadRequest, "Bad Request"), withoutKey, ErrCodeSSECustomerKey},
+		{"key given but not needed", fakeOperationError("GetObject", http.StatusBadRequest, "InvalidRequest: The encryption parameters are not applicable to this object."), withKey, ""},
+		{"other bad request", fakeOperationError("GetObject", http.StatusBadRequest, "InvalidArgument: Invalid version id specified"), withoutKey, ""},
+		{"access denied", fakeOperationError("HeadObject", http.StatusForbidden, "Forbidden"), withoutKey, ""},
+		{"network error", errors.New("connection reset"), withoutKey, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeSSECustomerError(tt.err, tt.event, "logs/app.log")
+			if tt.wantCode == "" {
+				if got != tt.err {
+					t.Errorf("describeSSECustomerError = %v, want the original error", got)
+				}
+				return
+			}
+			if errorCode(got) != tt.wantCode || !errors.Is(got, tt.err) {
+				t.Errorf("describeSSECustomerError = %v, want %s wrapping the S3 error", got, tt.wantCode)
+			}
+			if !strings.Contains(got.Error(), "origin-bucket/logs/app.log") {
+				t.Errorf("error %q does not name the object", got)
+			}
+		})
+	}
+	if err := describeSSECustomerError(nil, withoutKey, "logs/app.log"); err != nil {
+		t.Errorf("describeSSECustomerError(nil) = %v", err)
+	}
+}
+
+// SSE-C 원본을 키 없이 요청하면 HeadObject 단계에서 SSE_C_KEY_REQUIRED로 실패하고 다운로드하지 않음
+func TestOriginSSECustomerKeyRequired(t *testing.T) {
+	s3c, sqsc := newFakeS3(), &fakeSQS{}
+	s3c.putObject("origin-bucket", "logs/app.log", testContent, "text/plain")
+	s3c.errs["HeadObject"] = fakeOperationError("HeadObject", http.StatusBadRequest, "Bad Request")
+	useFakeClients(t, s3c, sqsc)
+
+	result, err := processRequest(context.Background(), testEvent())
+	if errorCode(err) != ErrCodeSSECustomerKey || result.ErrorCode != ErrCodeSSECustomerKey {
+		t.Fatalf("processRequest error = %v (result %q), want %s", err, result.ErrorCode, ErrCodeSSECustomerKey)
+	}
+	if len(s3c.gets) != 0 {
+		t.Error("origin was downloaded without the customer key")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// S3 객체 태그 제한 (https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-tagging.html)
+const (
+	MaxObjectTags     = 10
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+	TagKeyCompressed  = "compressed"  // 자동으로 추가되는 태그 (값: 압축 결과면 true, copyOnly 복사면 false)
+	TagKeyProcessUuid = "processUuid" // 자동으로 추가되는 태그 (값: 요청의 ProcessUuid)
+	reservedTagPrefix = "aws:"
+	autoTagCount      = 2
+)
+
+// 태그 키 → 값
+type TagSet map[string]string
+
+// 태그 키/값에 허용되는 문자: 문자, 숫자, 공백, + - = . _ : / @
+var tagCharsPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// 요청의 Tags가 S3 태그 제한과 자동 태그 규칙에 맞는지 검사
+func validateTags(tags TagSet) error {
+	if len(tags)+autoTagCount > MaxObjectTags {
+		return fmt.Errorf("too many tags: %d (at most %d besides %s and %s)", len(tags), MaxObjectTags-autoTagCount, TagKeyCompressed, TagKeyProcessUuid)
+	}
+	for key, value := range tags {
+		if key == "" || utf8.RuneCountInString(key) > MaxTagKeyLength {
+			return fmt.Errorf("tag key must be 1 to %d characters: %q", MaxTagKeyLength, key)
+		}
+		if utf8.RuneCountInString(value) > MaxTagValueLength {
+			return fmt.Errorf("tag value for %s must be at most %d characters", key, MaxTagValueLength)
+		}
+		if strings.HasPrefix(strings.ToLower(key), reservedTagPrefix) {
+			return fmt.Errorf("tag key must not start with %s: %s", reservedTagPrefix, key)
+		}
+		if key == TagKeyCompressed || key == TagKeyProcessUuid {
+			return fmt.Errorf("tag key %s is set automatically", key)
+		}
+		if !tagCharsPattern.MatchString(key) || !tagCharsPattern.MatchString(value) {
+			return fmt.Errorf("tag contains unsupported characters: %s=%s", key, value)
+		}
+	}
+	return nil
+}
+
+// 요청 태그에 자동 태그를 더해 PutObjectInput.Tagging 형식(URL 인코딩된 key=value&...)으로 변환
+// compressed는 압축하지 않고 그대로 복사한 객체(copyOnly)면 false
+func buildTagging(tags TagSet, processUuid string, compressed bool) string {
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	values.Set(TagKeyCompressed, strconv.FormatBool(compressed))
+	if processUuid != "" {
+		values.Set(TagKeyProcessUuid, processUuid)
+	}
+	return values.Encode()
+}
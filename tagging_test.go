@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// 태그는 URL 인코딩된 key=value&... 형식(키 순서 정렬)이고 자동 태그가 포함됨
+func TestBuildTagging(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        TagSet
+		processUuid string
+		compressed  bool
+		want        string
+	}{
+		{"auto tags only", nil, "uuid-1", true, "compressed=true&processUuid=uuid-1"},
+		{"copied", nil, "uuid-1", false, "compressed=false&processUuid=uuid-1"},
+		{"no uuid", nil, "", true, "compressed=true"},
+		{"user tags sorted", TagSet{"team": "data", "cost-center": "42"}, "u", true, "compressed=true&cost-center=42&processUuid=u&team=data"},
+		{"escaped", TagSet{"path": "a/b c", "expr": "x=y+z&w"}, "u", true, "compressed=true&expr=x%3Dy%2Bz%26w&path=a%2Fb+c&processUuid=u"},
+		{"unicode", TagSet{"팀": "데이터"}, "u", true, "compressed=true&processUuid=u&%ED%8C%80=%EB%8D%B0%EC%9D%B4%ED%84%B0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildTagging(tt.tags, tt.processUuid, tt.compressed)
+			if got != tt.want {
+				t.Errorf("buildTagging = %q, want %q", got, tt.want)
+			}
+			// S3가 디코딩하면 원래 값이 나와야 함
+			values, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.tags {
+				if values.Get(key) != value {
+					t.Errorf("decoded %s = %q, want %q", key, values.Get(key), value)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tooMany := TagSet{}
+	for _, key := range strings.Split("a b c d e f g h i", " ") {
+		tooMany[key] = "v"
+	}
+	tests := []struct {
+		name    string
+		tags    TagSet
+		wantErr bool
+	}{
+		{"valid", TagSet{"team": "data-eng", "path": "a/b:c@d"}, false},
+		{"max user tags", TagSet{"1": "", "2": "", "3": "", "4": "", "5": "", "6": "", "7": "", "8": ""}, false},
+		{"too many", tooMany, true},
+		{"empty key", TagSet{"": "v"}, true},
+		{"long key", TagSet{strings.Repeat("k", MaxTagKeyLength+1): "v"}, true},
+		{"long value", TagSet{"k": strings.Repeat("v", MaxTagValueLength+1)}, true},
+		{"reserved prefix", TagSet{"AWS:cost": "v"}, true},
+		{"auto key", TagSet{TagKeyCompressed: "false"}, true},
+		{"bad chars", TagSet{"k": "a&b"}, true},
+	}
+	for _, tt := range tests {
+		if err := validateTags(tt.tags); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateTags error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TargetKeyTemplate에서 사용할 수 있는 토큰
+// {yyyy}, {mm}, {dd}: 처리 시각(UTC) 날짜
+// {basename}: 원본 파일명(확장자 제외), {dir}: 원본 키의 디렉토리
+// {uuid}: ProcessUuid, {ext}: 출력 확장자 (예: .7z, 압축 해제 시 빈 값)
+var templateTokenPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+var templateTokens = map[string]bool{
+	"{yyyy}": true, "{mm}": true, "{dd}": true,
+	"{basename}": true, "{dir}": true, "{uuid}": true, "{ext}": true,
+}
+
+// 지원하지 않는 토큰이 있는지 확인
+func validateTargetKeyTemplate(template string) error {
+	for _, token := range templateTokenPattern.FindAllString(template, -1) {
+		if !templateTokens[token] {
+			return fmt.Errorf("unsupported token in targetKeyTemplate: %s", token)
+		}
+	}
+	return nil
+}
+
+// 템플릿의 토큰을 원본 키 기준 값으로 치환하여 대상 키 생성
+// 빈 {dir} 등으로 생기는 중복/선행 "/"는 정리
+func expandTargetKeyTemplate(template, originKey, processUuid, extension string, now time.Time) string {
+	now = now.UTC()
+	dir := path.Dir(originKey)
+	if dir == "." {
+		dir = ""
+	}
+	fileName := path.Base(originKey)
+	basename := strings.TrimSuffix(fileName, path.Ext(fileName))
+
+	key := strings.NewReplacer(
+		"{yyyy}", now.Format("2006"),
+		"{mm}", now.Format("01"),
+		"{dd}", now.Format("02"),
+		"{basename}", basename,
+		"{dir}", dir,
+		"{uuid}", processUuid,
+		"{ext}", extension,
+	).Replace(template)
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// 요청의 대상 키 결정
+// TargetKey가 있으면 그대로, 없으면 TargetKeyTemplate, 둘 다 없으면 원본 키의 확장자만 변경 (압축 해제 시 .7z 제거, 복사 시 원본 키 그대로)
+func resolveTargetKey(event FileCompressionForm, operation string, format compressionFormat, now time.Time) string {
+	if event.TargetKey != "" {
+		return event.TargetKey
+	}
+	extension := format.Extension
+	switch {
+	case operation == OperationDecompress:
+		extension = ""
+	case event.CopyOnly:
+		extension = path.Ext(event.OriginKey)
+	}
+	if event.TargetKeyTemplate != "" {
+		return expandTargetKeyTemplate(event.TargetKeyTemplate, templateOriginKey(event), event.ProcessUuid, extension, now)
+	}
+	if operation == OperationDecompress {
+		return strings.TrimSuffix(event.OriginKey, CompressExtension)
+	}
+	if event.CopyOnly {
+		return event.OriginKey
+	}
+	return replaceExtension(event.OriginKey, format.Extension)
+}
+
+// 템플릿의 {basename}, {dir} 기준 키 (여러 원본이면 첫 번째 키, 접두사 요청이면 접두사, URL 원본이면 URL의 파일명)
+func templateOriginKey(event FileCompressionForm) string {
+	switch {
+	case event.OriginPrefix != "":
+		return strings.TrimSuffix(event.OriginPrefix, "/")
+	case len(event.OriginKeys) > 0:
+		return event.OriginKeys[0]
+	case event.OriginUrl != "":
+		return originURLName(event.OriginUrl)
+	default:
+		return event.OriginKey
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandTargetKeyTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 7, 23, 30, 0, 0, time.FixedZone("KST", 9*60*60)) // UTC 기준 2024-03-07 14:30
+	tests := []struct {
+		name      string
+		template  string
+		originKey string
+		extension string
+		want      string
+	}{
+		{"date prefix", "compressed/{yyyy}/{mm}/{dd}/{basename}{ext}", "logs/app.log", ".7z", "compressed/2024/03/07/app.7z"},
+		{"original dir", "archive/{dir}/{basename}{ext}", "logs/2024/app.log", ".gz", "archive/logs/2024/app.gz"},
+		{"empty dir", "archive/{dir}/{basename}{ext}", "app.log", ".7z", "archive/app.7z"},
+		{"uuid", "{uuid}/{basename}{ext}", "a/b.txt", ".zip", "uuid-1/b.zip"},
+		{"leading dir token", "{dir}/{basename}{ext}", "app.log", ".7z", "app.7z"},
+		{"no extension", "out/{basename}{ext}", "data/README", ".7z", "out/README.7z"},
+		{"decompress without ext", "restored/{basename}{ext}", "data/file.7z", "", "restored/file"},
+		{"fixed ext", "{dir}/{yyyy}{mm}{dd}-{basename}.7z", "x/y/z.csv", ".7z", "x/y/20240307-z.7z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTargetKeyTemplate(tt.template, tt.originKey, "uuid-1", tt.extension, now); got != tt.want {
+				t.Errorf("expandTargetKeyTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTargetKeyTemplate(t *testing.T) {
+	if err := validateTargetKeyTemplate("{yyyy}/{mm}/{dd}/{dir}/{basename}-{uuid}{ext}"); err != nil {
+		t.Errorf("valid template rejected: %v", err)
+	}
+	for _, template := range []string{"{year}/{basename}", "{BASENAME}", "{}"} {
+		if err := validateTargetKeyTemplate(template); err == nil {
+			t.Errorf("validateTargetKeyTemplate(%q) accepted an unsupported token", template)
+		}
+	}
+}
+
+// targetKey가 있으면 템플릿보다 우선
+func TestResolveTargetKeyPrefersTargetKey(t *testing.T) {
+	event := FileCompressionForm{OriginKey: "logs/app.log", TargetKey: "explicit.7z", TargetKeyTemplate: "t/{basename}{ext}"}
+	if got := resolveTargetKey(event, OperationCompress, compressionFormats["7z"], time.Now()); got != "explicit.7z" {
+		t.Errorf("resolveTargetKey = %q, want explicit.7z", got)
+	}
+	event.TargetKey = ""
+	if got := resolveTargetKey(event, OperationCompress, compressionFormats["7z"], time.Now()); got != "t/app.7z" {
+		t.Errorf("resolveTargetKey = %q, want t/app.7z", got)
+	}
+}
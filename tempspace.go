@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// 요청별 작업 디렉토리 이름 접두사 (tempDir/job-<ProcessUuid>-<랜덤>)
+const workDirPrefix = "job-"
+
+// 작업 디렉토리 이름에 넣을 ProcessUuid 최대 길이
+const maxWorkDirNameLength = 64
+
+// 작업 디렉토리 이름에 사용할 수 있도록 ProcessUuid 정리 (영문, 숫자, -, _ 외의 문자는 _로 바꾸고 길이 제한)
+func workDirName(processUuid string) string {
+	name := []rune(processUuid)
+	if len(name) > maxWorkDirNameLength {
+		name = name[:maxWorkDirNameLength]
+	}
+	for i, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// 임시 파일 관리 방식 (TEMP_FILE_STRATEGY)
+const (
+	TempFileStrategyPath     = "path"     // 업로드가 끝날 때까지 작업 디렉토리에 파일을 남겨둠 (기본값)
+	TempFileStrategyUnlinked = "unlinked" // 업로드할 파일을 연 직후 작업 디렉토리를 삭제하여, 업로드 중 강제 종료되어도 커널이 공간을 회수
+)
+
+// 임시 디렉토리(tempDir) 파일 시스템의 사용 가능한 바이트 수 (Lambda /tmp는 기본 512MB, 최대 10GB)
+func availableTempBytes() (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(tempDir, &st); err != nil {
+		return 0, fmt.Errorf("failed to stat temp directory %s: %w", tempDir, err)
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// 앞으로 required 바이트를 쓸 수 있는지 확인하여, 쓰기 도중 공간 부족으로 실패하기 전에 미리 실패 처리
+// 여유 공간을 조회하지 못하면 경고만 남기고 진행 (확인 자체가 처리를 막지 않도록)
+func checkTempSpace(ctx context.Context, stage string, required int64) error {
+	free, err := availableTempBytes()
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to check temp space", "stage", stage, errorAttr(err))
+		return nil
+	}
+	loggerFrom(ctx).Info("Temp space", "stage", stage, "freeBytes", free, "requiredBytes", required)
+	if required > free {
+		return newCompressionError(ErrCodeTempSpace, fmt.Errorf("insufficient temp space: %d bytes required, %d bytes free in %s", required, free, tempDir))
+	}
+	return nil
+}
+
+// 현재 여유 공간을 로그로 남기고 메트릭용으로 반환 (조회 실패 시 0)
+func recordTempFreeBytes(ctx context.Context, stage string) int64 {
+	free, err := availableTempBytes()
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to check temp space", "stage", stage, errorAttr(err))
+		return 0
+	}
+	loggerFrom(ctx).Info("Temp space after processing", "stage", stage, "freeBytes", free)
+	return free
+}
+
+// 강제 종료된 이전 실행(타임아웃, OOM 등)이 남긴 작업 디렉토리 삭제 (컨테이너 초기화 시 실행)
+// 우리가 만든 job-* 디렉토리 중 maxAge보다 오래 수정되지 않은 것만 지우므로, 실행 중인 요청의 디렉토리는 건드리지 않음
+func sweepStaleWorkDirs(maxAge time.Duration, now time.Time) {
+	if maxAge <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(tempDir, workDirPrefix+"*"))
+	if err != nil {
+		slog.Warn("Failed to list stale work directories", "stage", "init", errorAttr(err))
+		return
+	}
+	removed := 0
+	for _, dir := range matches {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() || now.Sub(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("Failed to delete stale work directory", "stage", "init", "path", dir, errorAttr(err))
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		slog.Info("Deleted stale work directories", "stage", "init", "count", removed, "maxAge", maxAge.String())
+	}
+}
+
+// 업로드할 파일을 모두 열어 두고, 전부 닫는 함수를 함께 반환
+// unlinked 전략이면 연 직후 작업 디렉토리(입력 파일 포함)를 삭제함 (열린 파일은 닫을 때까지 계속 읽을 수 있고 그 뒤 공간이 회수됨)
+func openUploadSources(ctx context.Context, workDir string, paths []string) ([]*os.File, func(), error) {
+	files := make([]*os.File, 0, len(paths))
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("failed to open source file: %w", err)
+		}
+		files = append(files, f)
+	}
+	if tempFileStrategy == TempFileStrategyUnlinked {
+		cleanupTemp(ctx, workDir)
+		loggerFrom(ctx).Debug("Unlinked temp files before upload", "stage", "upload", "files", len(files))
+	}
+	return files, closeAll, nil
+}
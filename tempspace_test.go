@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 임시 디렉토리를 t.TempDir()로 바꾸고 테스트가 끝나면 되돌림
+func useTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	saved := tempDir
+	tempDir = dir
+	t.Cleanup(func() { tempDir = saved })
+	return dir
+}
+
+func TestAvailableTempBytes(t *testing.T) {
+	dir := useTempDir(t)
+
+	free, err := availableTempBytes()
+	if err != nil {
+		t.Fatalf("availableTempBytes: %v", err)
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		t.Fatalf("Statfs: %v", err)
+	}
+	// 조회 사이에 다른 프로세스가 공간을 쓸 수 있으므로 파일 시스템 전체 크기 이내인지만 확인
+	if total := int64(st.Blocks) * int64(st.Bsize); free <= 0 || free > total {
+		t.Errorf("availableTempBytes = %d, want between 1 and %d", free, total)
+	}
+
+	tempDir = filepath.Join(dir, "missing")
+	if _, err := availableTempBytes(); err == nil {
+		t.Error("availableTempBytes succeeded for a missing directory")
+	}
+}
+
+func TestCheckTempSpace(t *testing.T) {
+	dir := useTempDir(t)
+	ctx := context.Background()
+
+	if err := checkTempSpace(ctx, "download", 1); err != nil {
+		t.Errorf("checkTempSpace(1) = %v", err)
+	}
+	if err := checkTempSpace(ctx, "download", math.MaxInt64); errorCode(err) != ErrCodeTempSpace {
+		t.Errorf("checkTempSpace(MaxInt64) = %v, want %s", err, ErrCodeTempSpace)
+	}
+	// 여유 공간을 조회하지 못하면 막지 않음
+	tempDir = filepath.Join(dir, "missing")
+	if err := checkTempSpace(ctx, "download", math.MaxInt64); err != nil {
+		t.Errorf("checkTempSpace without statfs = %v, want nil", err)
+	}
+	if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+		t.Errorf("checkTempSpace created the temp directory: %v", err)
+	}
+}
+
+// 오래된 job-* 디렉토리만 삭제하고, 최근 디렉토리와 다른 이름의 디렉토리/파일은 남김
+func TestSweepStaleWorkDirs(t *testing.T) {
+	dir := useTempDir(t)
+	now := time.Now()
+	const maxAge = time.Hour
+
+	mkdir := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Join(path, "nested"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "nested", "input.log"), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	stale := mkdir(workDirPrefix+"old-uuid-123", 2*maxAge)
+	justStale := mkdir(workDirPrefix+"edge-uuid-456", maxAge)
+	recent := mkdir(workDirPrefix+"running-uuid-789", maxAge-time.Minute)
+	other := mkdir("other-old-dir", 2*maxAge)
+	file := filepath.Join(dir, workDirPrefix+"not-a-dir")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, now.Add(-2*maxAge), now.Add(-2*maxAge)); err != nil {
+		t.Fatal(err)
+	}
+
+	sweepStaleWorkDirs(maxAge, now)
+
+	for path, wantExists := range map[string]bool{stale: false, justStale: false, recent: true, other: true, file: true} {
+		if _, err := os.Stat(path); (err == nil) != wantExists {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(path), err == nil, wantExists)
+		}
+	}
+
+	// maxAge가 0이면 정리하지 않음
+	stale = mkdir(workDirPrefix+"old-uuid-000", 2*maxAge)
+	sweepStaleWorkDirs(0, now)
+	if _, err := os.Stat(stale); err != nil {
+		t.Errorf("sweep with maxAge 0 removed %s", filepath.Base(stale))
+	}
+}
+
+func TestWorkDirName(t *testing.T) {
+	tests := []struct {
+		processUuid, want string
+	}{
+		{"", ""},
+		{"3f2b9c1e-7d4a-4e8b-9f00-123456789abc", "3f2b9c1e-7d4a-4e8b-9f00-123456789abc"},
+		{"batch_2024/01/01", "batch_2024_01_01"},
+		{"../../etc", "______etc"},
+		{"작업 1", "___1"},
+		{strings.Repeat("a", maxWorkDirNameLength+10), strings.Repeat("a", maxWorkDirNameLength)},
+	}
+	for _, tt := range tests {
+		if got := workDirName(tt.processUuid); got != tt.want {
+			t.Errorf("workDirName(%q) = %q, want %q", tt.processUuid, got, tt.want)
+		}
+	}
+}
+
+// 작업 디렉토리는 tempDir 바로 아래에 job-<ProcessUuid>-<랜덤> 이름으로 요청마다 따로 생성
+func TestCreateWorkDir(t *testing.T) {
+	root := useTempDir(t)
+
+	first, err := createWorkDir("uuid/1")
+	if err != nil {
+		t.Fatalf("createWorkDir: %v", err)
+	}
+	second, err := createWorkDir("uuid/1")
+	if err != nil {
+		t.Fatalf("createWorkDir: %v", err)
+	}
+	if first == second {
+		t.Errorf("same ProcessUuid got the same work directory %s", first)
+	}
+	for _, dir := range []string{first, second} {
+		if filepath.Dir(dir) != root || !strings.HasPrefix(filepath.Base(dir), workDirPrefix+"uuid_1-") {
+			t.Errorf("work directory %s, want %s/%suuid_1-*", dir, root, workDirPrefix)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("work directory %s was not created: %v", dir, err)
+		}
+	}
+
+	anonymous, err := createWorkDir("")
+	if err != nil {
+		t.Fatalf("createWorkDir without ProcessUuid: %v", err)
+	}
+	if filepath.Dir(anonymous) != root || !strings.HasPrefix(filepath.Base(anonymous), workDirPrefix) {
+		t.Errorf("work directory without ProcessUuid = %s", anonymous)
+	}
+
+	tempDir = filepath.Join(root, "missing")
+	if _, err := createWorkDir("uuid"); err == nil {
+		t.Error("createWorkDir succeeded in a missing temp directory")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultOriginURLTimeout = 300        // URL 원본 다운로드 제한 시간 (초)
+	defaultOriginURLName    = "download" // URL 경로에서 파일명을 얻을 수 없을 때 사용할 이름
+)
+
+// URL 원본 다운로드용 HTTP 클라이언트 (ORIGIN_URL_TIMEOUT으로 제한 시간 변경)
+// 요청자가 지정한 URL로 내부 주소(인스턴스 메타데이터, VPC 내부 서비스 등)에 접근하지 못하도록
+// DNS 조회 후 실제로 연결하는 IP와 리다이렉트 대상을 검사 (프록시를 거치면 대상 IP를 검사할 수 없으므로 프록시 미사용)
+var originHTTPClient = &http.Client{
+	Timeout: DefaultOriginURLTimeout * time.Second,
+	Transport: &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkOriginDial}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	},
+	CheckRedirect: checkOriginRedirect,
+}
+
+// 최대 리다이렉트 횟수 (net/http 기본값과 같음)
+const maxOriginRedirects = 10
+
+var errBlockedOriginAddress = errors.New("origin url address is not allowed")
+
+// URL 원본으로 연결할 수 없는 주소: 루프백, 링크 로컬(169.254.169.254 메타데이터 포함), 사설, 지정되지 않은 주소
+func blockedOriginIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// 테스트에서 httptest 서버(127.0.0.1)에 연결할 수 있도록 교체
+var isBlockedOriginIP = blockedOriginIP
+
+// 연결 직전(DNS 조회 후) 대상 IP 검사 (조회 결과가 바뀌는 DNS rebinding도 실제 연결 주소로 확인)
+func checkOriginDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isBlockedOriginIP(ip) {
+		return fmt.Errorf("%w: %s", errBlockedOriginAddress, host)
+	}
+	return nil
+}
+
+// 리다이렉트 대상도 원본 URL과 같은 규칙으로 검사 (http/https만, IP 주소면 차단 대상 확인, 호스트 이름은 연결 시 checkOriginDial에서 확인)
+func checkOriginRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxOriginRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxOriginRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("%w: redirect to %s scheme", errBlockedOriginAddress, req.URL.Scheme)
+	}
+	if ip := net.ParseIP(req.URL.Hostname()); ip != nil && isBlockedOriginIP(ip) {
+		return fmt.Errorf("%w: redirect to %s", errBlockedOriginAddress, ip)
+	}
+	return nil
+}
+
+// URL 원본 요청 검사: http/https URL만 허용하고, S3 원본 필드와 원본 S3 객체를 전제로 하는 옵션은 함께 사용할 수 없음
+// 대상은 S3이므로 targetBucket과 targetKey(또는 targetKeyTemplate)를 지정해야 함
+func validateOriginUrl(event FileCompressionForm) error {
+	u, err := url.Parse(event.OriginUrl)
+	if err != nil {
+		return fmt.Errorf("invalid originUrl: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported originUrl scheme: %q (only http and https)", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("originUrl must include a host")
+	}
+	switch {
+	case event.OriginBucket != "" || event.OriginKey != "" || len(event.OriginKeys) > 0 || event.OriginPrefix != "":
+		return fmt.Errorf("originUrl cannot be used with originBucket, originKey, originKeys or originPrefix")
+	case event.TargetBucket == "":
+		return fmt.Errorf("targetBucket is required when originUrl is set")
+	case event.TargetKey == "" && event.TargetKeyTemplate == "":
+		return fmt.Errorf("targetKey or targetKeyTemplate is required when originUrl is set")
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("originUrl is not supported for %s", event.Operation)
+	case event.StreamMode:
+		return fmt.Errorf("originUrl is not supported in stream mode")
+	case event.CopyOnly, event.AutoFormat, event.DeleteOriginal:
+		return fmt.Errorf("originUrl cannot be used with copyOnly, autoFormat or deleteOriginal")
+	case event.OriginVersionId != "", event.RangeStart != nil, event.RangeEnd != nil:
+		return fmt.Errorf("originUrl cannot be used with originVersionId or rangeStart/rangeEnd")
+	case event.SSECustomerKey != "", event.MinAgeHours > 0:
+		return fmt.Errorf("originUrl cannot be used with sseCustomerKey or minAgeHours")
+	}
+	return nil
+}
+
+// URL 경로의 마지막 요소 (임시 파일명과 아카이브 항목 이름으로 사용)
+func originURLName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return defaultOriginURLName
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return defaultOriginURLName
+	}
+	return name
+}
+
+// 로그용 URL (쿼리 문자열의 서명/토큰과 사용자 정보를 제외)
+func originURLForLog(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// URL의 파일을 destPath로 다운로드하고 크기와 ContentType/Last-Modified 반환
+// Content-Length가 MaxInputBytes를 넘으면 받지 않고, Content-Length가 없거나 틀려도 제한을 넘게 읽으면 중단
+func downloadFromURL(ctx context.Context, job compressionJob, destPath string) (objectInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.Event.OriginUrl, nil)
+	if err != nil {
+		return objectInfo{}, fmt.Errorf("failed to create origin request: %w", err)
+	}
+	resp, err := originHTTPClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedOriginAddress) {
+			// 연결 에러(net.OpError)를 체인에 남기면 SDK 기준으로 재시도 대상이 되므로 메시지로만 포함
+			return objectInfo{}, newCompressionError(ErrCodeValidation, fmt.Errorf("failed to get origin url: %w (%v)", errBlockedOriginAddress, err))
+		}
+		if ctx.Err() != nil {
+			return objectInfo{}, fmt.Errorf("failed to get origin url: %w", err)
+		}
+		return objectInfo{}, &retryableError{err: fmt.Errorf("failed to get origin url: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return objectInfo{}, newCompressionError(ErrCodeOriginNotFound, fmt.Errorf("origin url not found: %s (%s)", originURLForLog(job.Event.OriginUrl), resp.Status))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return objectInfo{}, newCompressionError(ErrCodeAccessDenied, fmt.Errorf("access denied to origin url: %s (%s)", originURLForLog(job.Event.OriginUrl), resp.Status))
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return objectInfo{}, &retryableError{err: fmt.Errorf("origin url returned %s", resp.Status)}
+	case resp.StatusCode != http.StatusOK:
+		return objectInfo{}, fmt.Errorf("origin url returned %s", resp.Status)
+	}
+	limit := maxInputBytes(job)
+	if resp.ContentLength > limit {
+		return objectInfo{}, checkInputSize(job, resp.ContentLength)
+	}
+
+	f, err := os.Create(destPath)
+	if err != nil {
+		return objectInfo{}, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+	body := newProgressReader(ctx, resp.Body, "download", originURLName(job.Event.OriginUrl), resp.ContentLength)
+	var dst io.Writer = f
+	h := newOriginHash(job.Event.OriginHash)
+	if h != nil {
+		dst = io.MultiWriter(f, h)
+	}
+	bytesWritten, err := copyBuffered(dst, io.LimitReader(body, limit+1))
+	if err != nil {
+		return objectInfo{}, &retryableError{err: fmt.Errorf("failed to copy origin url data: %w", err)}
+	}
+	if bytesWritten > limit {
+		return objectInfo{}, checkInputSize(job, bytesWritten)
+	}
+
+	info := objectInfo{Size: bytesWritten, ContentType: resp.Header.Get("Content-Type"), Hash: hashDigest(h)}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = lastModified
+	}
+	loggerFrom(ctx).Info("Downloaded origin url", "stage", "download", "url", originURLForLog(job.Event.OriginUrl), bytesAttr(bytesWritten))
+	return info, nil
+}
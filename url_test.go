@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 테스트 동안 httptest 서버(127.0.0.1)에는 연결할 수 있도록 루프백만 허용 (나머지 차단 규칙은 유지)
+func allowLoopbackOrigin(t *testing.T) {
+	t.Helper()
+	saved := isBlockedOriginIP
+	isBlockedOriginIP = func(ip net.IP) bool { return !ip.IsLoopback() && blockedOriginIP(ip) }
+	t.Cleanup(func() { isBlockedOriginIP = saved })
+}
+
+func downloadTestURL(t *testing.T, rawURL string) (objectInfo, []byte, error) {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "download")
+	info, err := downloadFromURL(context.Background(), compressionJob{Event: FileCompressionForm{OriginUrl: rawURL}}, dest)
+	data, _ := os.ReadFile(dest)
+	return info, data, err
+}
+
+func TestBlockedOriginIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"192.168.1.1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"2606:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := blockedOriginIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("blockedOriginIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
+
+// 기본 규칙에서는 루프백 서버에 연결하지 않고 재시도 없이 VALIDATION_FAILED로 실패
+func TestDownloadFromURLBlocksLoopback(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	_, _, err := downloadTestURL(t, srv.URL+"/app.log")
+	if !errors.Is(err, errBlockedOriginAddress) || errorCode(err) != ErrCodeValidation {
+		t.Fatalf("downloadFromURL = %v, want %s blocked address", err, ErrCodeValidation)
+	}
+	if isRetryableError(err) {
+		t.Error("blocked address error is retryable")
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests", requests)
+	}
+}
+
+func TestDownloadFromURL(t *testing.T) {
+	allowLoopbackOrigin(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app.log", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 12:00:00 GMT")
+		w.Write(testContent)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/app.log", http.StatusFound)
+	})
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	})
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("allowed", func(t *testing.T) {
+		info, data, err := downloadTestURL(t, srv.URL+"/app.log")
+		if err != nil {
+			t.Fatalf("downloadFromURL: %v", err)
+		}
+		if string(data) != string(testContent) || info.Size != int64(len(testContent)) || info.ContentType != "text/plain" || info.LastModified.IsZero() {
+			t.Errorf("downloaded %d bytes, info %+v", len(data), info)
+		}
+	})
+	t.Run("redirect to allowed host", func(t *testing.T) {
+		if _, data, err := downloadTestURL(t, srv.URL+"/moved"); err != nil || string(data) != string(testContent) {
+			t.Errorf("downloadFromURL = %v", err)
+		}
+	})
+	for _, path := range []string{"/metadata", "/file"} {
+		t.Run("redirect "+path, func(t *testing.T) {
+			_, _, err := downloadTestURL(t, srv.URL+path)
+			if !errors.Is(err, errBlockedOriginAddress) || errorCode(err) != ErrCodeValidation {
+				t.Errorf("downloadFromURL = %v, want blocked redirect", err)
+			}
+		})
+	}
+	t.Run("redirect loop", func(t *testing.T) {
+		if _, _, err := downloadTestURL(t, srv.URL+"/loop"); err == nil {
+			t.Error("downloadFromURL followed a redirect loop")
+		}
+	})
+}
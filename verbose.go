@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	verboseTailLines        = 20              // 실패 시 에러 로그에 포함할 마지막 출력 줄 수
+	verboseProgressInterval = 5 * time.Second // 진행률 줄(예: " 42% 3 + file") 로그 최소 간격
+)
+
+var progressLinePattern = regexp.MustCompile(`^\d+%`)
+
+// 7za를 실행하면서 표준 출력/에러를 한 줄씩 바로 로그로 남기고, 마지막 verboseTailLines줄을 반환
+// 진행률은 같은 줄을 \r 또는 \b로 덮어쓰므로 이 문자들도 줄 구분으로 처리하고, 너무 자주 남기지 않도록 간격 제한
+func runWithLiveOutput(ctx context.Context, cmd *exec.Cmd, stage string, opts sevenZipOptions) ([]byte, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return nil, err
+	}
+
+	done := make(chan []string)
+	go func() {
+		var tail []string
+		var lastProgress time.Time
+		scanner := bufio.NewScanner(pr)
+		scanner.Split(scanOutputLines)
+		for scanner.Scan() {
+			line := strings.TrimSpace(opts.redactOutput(scanner.Text()))
+			if line == "" {
+				continue
+			}
+			if progressLinePattern.MatchString(line) {
+				if time.Since(lastProgress) < verboseProgressInterval {
+					continue
+				}
+				lastProgress = time.Now()
+			}
+			loggerFrom(ctx).Info("7za output", "stage", stage, "line", line)
+			tail = append(tail, line)
+			if len(tail) > verboseTailLines {
+				tail = tail[1:]
+			}
+		}
+		// 스캐너가 중간에 멈춰도(너무 긴 줄 등) 7za가 쓰기에서 막히지 않도록 나머지 출력은 버림
+		io.Copy(io.Discard, pr)
+		done <- tail
+	}()
+
+	err := cmd.Wait()
+	pw.Close()
+	tail := <-done
+	return []byte(strings.Join(tail, "\n")), err
+}
+
+// \n, \r, \b를 줄 구분으로 사용하는 bufio.SplitFunc
+func scanOutputLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\n\r\b"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
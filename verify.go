@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// 압축 결과 아카이브의 항목이 입력 파일과 일치하지 않는 경우
+const ErrCodeVerify = "VERIFY_FAILED"
+
+// 아카이브 내용 확인 옵션 검사 (7za로 목록을 볼 수 있는 다중 파일 포맷의 임시 파일 압축만 지원)
+func validateVerifyContents(event FileCompressionForm) error {
+	if !event.VerifyContents {
+		return nil
+	}
+	format := defaultIfEmpty(event.Format, DefaultFormat)
+	switch {
+	case !compressionFormats[format].MultiFile:
+		return fmt.Errorf("verifyContents is not supported for format: %s", format)
+	case defaultIfEmpty(event.Operation, OperationCompress) != OperationCompress:
+		return fmt.Errorf("verifyContents is not supported for %s", event.Operation)
+	case event.StreamMode:
+		return fmt.Errorf("verifyContents cannot be used with streamMode")
+	case event.VolumeSize != "":
+		return fmt.Errorf("verifyContents cannot be used with volumeSize")
+	case event.CopyOnly:
+		return fmt.Errorf("verifyContents cannot be used with copyOnly")
+	}
+	return nil
+}
+
+// 아카이브에 저장될 항목 경로 (root가 있으면 root 기준 상대 경로, 없으면 파일명)
+func expectedArchiveEntries(inputPaths []string, root string) []string {
+	entries := make([]string, 0, len(inputPaths))
+	for _, path := range inputPaths {
+		entry := filepath.Base(path)
+		if root != "" {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				entry = filepath.ToSlash(rel)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// 7za l로 아카이브 항목 목록을 읽어 expected와 개수/이름이 모두 같은지 확인
+func verifyArchiveContents(ctx context.Context, archivePath string, expected []string, opts sevenZipOptions) error {
+	release, err := acquireCompressSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	entries, err := listArchiveFiles(ctx, archivePath, SevenZipPassFlag+opts.Password, opts)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		entries[i] = filepath.ToSlash(entry)
+	}
+	if len(entries) != len(expected) {
+		return fmt.Errorf("archive contains %d files, expected %d", len(entries), len(expected))
+	}
+	want := slices.Sorted(slices.Values(expected))
+	got := slices.Sorted(slices.Values(entries))
+	for i := range want {
+		if got[i] != want[i] {
+			return fmt.Errorf("archive contents do not match inputs: found %q, expected %q", got[i], want[i])
+		}
+	}
+	loggerFrom(ctx).Info("Archive contents verified", "stage", "verify", "files", len(entries))
+	return nil
+}